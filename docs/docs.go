@@ -24,44 +24,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/courses": {
-            "get": {
-                "description": "Retrieve a list of all available yoga courses.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Courses"
-                ],
-                "summary": "Get all courses",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/yoga-guru_internal_models.Course"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "error: Internal server error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    }
-                }
-            },
+        "/checkin": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new yoga course with details like title, type, schedule, level, price, and capacity.",
+                "description": "Check the current student in to a booked session using the token scanned from the front desk QR code.",
                 "consumes": [
                     "application/json"
                 ],
@@ -69,25 +39,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Courses"
+                    "Sessions"
                 ],
-                "summary": "Create a new course (Instructor/Admin only)",
+                "summary": "Check in to a session (Student only)",
                 "parameters": [
                     {
-                        "description": "Course details",
-                        "name": "course",
+                        "description": "Scanned check-in token",
+                        "name": "checkin",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_controllers.CreateCourseRequest"
+                            "$ref": "#/definitions/internal_controllers.CheckInRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/yoga-guru_internal_models.Course"
+                            "$ref": "#/definitions/yoga-guru_internal_models.Attendance"
                         }
                     },
                     "400": {
@@ -100,7 +70,7 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "error: Unauthorized",
+                        "description": "error: Unauthorized or invalid token",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -108,8 +78,17 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "403": {
-                        "description": "error: Forbidden",
+                    "404": {
+                        "description": "error: Booking not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Check-in closed or already checked in",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -129,34 +108,42 @@ const docTemplate = `{
                 }
             }
         },
-        "/courses/{id}": {
+        "/coupons": {
             "get": {
-                "description": "Retrieve details of a specific yoga course by its ID.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve all coupons with their redemption counts.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Courses"
-                ],
-                "summary": "Get a course by ID",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Course ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Coupons"
                 ],
+                "summary": "Get all coupons (Admin only)",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/yoga-guru_internal_models.Course"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/yoga-guru_internal_models.Coupon"
+                            }
                         }
                     },
-                    "404": {
-                        "description": "error: Course not found",
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -175,13 +162,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update the details of an existing yoga course. Only the course instructor or an admin can update a course.",
+                "description": "Create a promotional code taking a percentage or a fixed amount off the enrollment price, optionally limited in time, in number of redemptions, per student, and to a course or price plan.",
                 "consumes": [
                     "application/json"
                 ],
@@ -189,32 +176,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Courses"
+                    "Coupons"
                 ],
-                "summary": "Update an existing course (Instructor/Admin only)",
+                "summary": "Create a coupon (Admin only)",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Course ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Updated course details",
-                        "name": "course",
+                        "description": "Coupon details",
+                        "name": "coupon",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_controllers.UpdateCourseRequest"
+                            "$ref": "#/definitions/internal_controllers.CouponRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/yoga-guru_internal_models.Course"
+                            "$ref": "#/definitions/yoga-guru_internal_models.Coupon"
                         }
                     },
                     "400": {
@@ -244,8 +224,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "error: Course not found",
+                    "409": {
+                        "description": "error: Code already in use",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -263,33 +243,59 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/coupons/{id}": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete an existing yoga course. Only the course instructor or an admin can delete a course.",
+                "description": "Update a coupon. Its redemptions so far are kept.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Courses"
+                    "Coupons"
                 ],
-                "summary": "Delete a course (Instructor/Admin only)",
+                "summary": "Update a coupon (Admin only)",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Course ID",
+                        "description": "Coupon ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Coupon details",
+                        "name": "coupon",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.CouponRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.Coupon"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     },
                     "401": {
                         "description": "error: Unauthorized",
@@ -310,7 +316,16 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "error: Course not found",
+                        "description": "error: Coupon not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Code already in use",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -328,43 +343,33 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/enrollments": {
-            "post": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Allows a student to enroll in a yoga course with various enrollment packages.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Delete a coupon so it can no longer be redeemed. Its redemptions are kept.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Enrollments"
+                    "Coupons"
                 ],
-                "summary": "Enroll a student in a course (Student only)",
+                "summary": "Delete a coupon (Admin only)",
                 "parameters": [
                     {
-                        "description": "Enrollment details",
-                        "name": "enrollment",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/internal_controllers.EnrollRequest"
-                        }
+                        "type": "integer",
+                        "description": "Coupon ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/yoga-guru_internal_models.Enrollment"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
                         "description": "error: Bad request",
@@ -394,16 +399,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "error: Course not found",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "409": {
-                        "description": "error: Already enrolled or course full",
+                        "description": "error: Coupon not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -423,82 +419,48 @@ const docTemplate = `{
                 }
             }
         },
-        "/enrollments/me": {
+        "/coupons/{id}/redemptions": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieve a list of all courses a student is enrolled in.",
+                "description": "Retrieve who redeemed a coupon, for which enrollment and how much it took off.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Enrollments"
+                    "Coupons"
+                ],
+                "summary": "Get the redemptions of a coupon (Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Coupon ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get student's enrollments",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/yoga-guru_internal_models.Enrollment"
-                            }
-                        }
-                    },
-                    "401": {
-                        "description": "error: Unauthorized",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                                "$ref": "#/definitions/yoga-guru_internal_models.CouponRedemption"
                             }
                         }
                     },
-                    "500": {
-                        "description": "error: Internal server error",
+                    "400": {
+                        "description": "error: Bad request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            }
-        },
-        "/enrollments/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Retrieve details of a specific enrollment by its ID. (Admin/Enrolled Student only)",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Enrollments"
-                ],
-                "summary": "Get enrollment by ID",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Enrollment ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/yoga-guru_internal_models.Enrollment"
-                        }
                     },
                     "401": {
                         "description": "error: Unauthorized",
@@ -519,7 +481,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "error: Enrollment not found",
+                        "description": "error: Coupon not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -537,36 +499,76 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
+            }
+        },
+        "/courses": {
+            "get": {
+                "description": "Retrieve a list of all available yoga courses.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Courses"
+                ],
+                "summary": "Get all courses",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/yoga-guru_internal_models.Course"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
                     }
                 ],
-                "description": "Allows a student to cancel their enrollment, or an admin to cancel any enrollment.",
+                "description": "Create a new yoga course with details like title, type, schedule, level, price, and capacity.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Enrollments"
+                    "Courses"
                 ],
-                "summary": "Cancel an enrollment (Student/Admin only)",
+                "summary": "Create a new course (Instructor/Admin only)",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Enrollment ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Course details",
+                        "name": "course",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.CreateCourseRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.Course"
+                        }
                     },
-                    "401": {
-                        "description": "error: Unauthorized",
+                    "400": {
+                        "description": "error: Bad request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -574,8 +576,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "403": {
-                        "description": "error: Forbidden",
+                    "401": {
+                        "description": "error: Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -583,8 +585,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "error: Enrollment not found",
+                    "403": {
+                        "description": "error: Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -604,51 +606,34 @@ const docTemplate = `{
                 }
             }
         },
-        "/login": {
-            "post": {
-                "description": "Authenticate user with email and password, returning a JWT token.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/courses/{id}": {
+            "get": {
+                "description": "Retrieve details of a specific yoga course by its ID.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Courses"
                 ],
-                "summary": "Log in a user",
+                "summary": "Get a course by ID",
                 "parameters": [
                     {
-                        "description": "User login credentials",
-                        "name": "credentials",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/internal_controllers.LoginRequest"
-                        }
+                        "type": "integer",
+                        "description": "Course ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "token: JWT_TOKEN",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "400": {
-                        "description": "error: Bad request",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/yoga-guru_internal_models.Course"
                         }
                     },
-                    "401": {
-                        "description": "error: Invalid credentials",
+                    "404": {
+                        "description": "error: Course not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -666,11 +651,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/refresh": {
-            "post": {
-                "description": "Refresh a JWT access token using a valid refresh token.",
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update the details of an existing yoga course. Only the course instructor or an admin can update a course.",
                 "consumes": [
                     "application/json"
                 ],
@@ -678,23 +666,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Courses"
                 ],
-                "summary": "Refresh an access token",
+                "summary": "Update an existing course (Instructor/Admin only)",
                 "parameters": [
                     {
-                        "description": "Refresh token",
-                        "name": "refresh",
+                        "type": "integer",
+                        "description": "Course ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated course details",
+                        "name": "course",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_controllers.RefreshTokenRequest"
+                            "$ref": "#/definitions/internal_controllers.UpdateCourseRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "token: JWT_TOKEN, refresh: REFRESH_TOKEN",
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.Course"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -702,8 +703,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "400": {
-                        "description": "error: Bad request",
+                    "401": {
+                        "description": "error: Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -711,8 +712,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "error: Invalid refresh token",
+                    "403": {
+                        "description": "error: Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -721,7 +722,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "error: User not found",
+                        "description": "error: Course not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -739,35 +740,36 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/register": {
-            "post": {
-                "description": "Register a new user with username, email, and password. Role defaults to 'student'.",
-                "consumes": [
-                    "application/json"
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Delete an existing yoga course. Only the course instructor or an admin can delete a course. Bookings and waitlist places on its upcoming sessions are cancelled and their students notified.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Courses"
                 ],
-                "summary": "Register a new user",
+                "summary": "Delete a course (Instructor/Admin only)",
                 "parameters": [
                     {
-                        "description": "User registration details",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/internal_controllers.RegisterRequest"
-                        }
+                        "type": "integer",
+                        "description": "Course ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "message: User registered successfully",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -775,8 +777,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "400": {
-                        "description": "error: Bad request",
+                    "403": {
+                        "description": "error: Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -784,8 +786,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "409": {
-                        "description": "error: User with this email or username already exists",
+                    "404": {
+                        "description": "error: Course not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -805,30 +807,37 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/me": {
+        "/courses/{id}/plans": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Retrieve the profile details of the authenticated user.",
+                "description": "Retrieve the active price plans that can be bought for a course, with a quote for each. The quoted price includes tax, which is added on top of or included in the course price as the studio is configured.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Price Plans"
+                ],
+                "summary": "Get the price plans of a course",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Course ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get current user's profile",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_controllers.UserProfileResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_controllers.PlanQuote"
+                            }
                         }
                     },
-                    "401": {
-                        "description": "error: Unauthorized",
+                    "400": {
+                        "description": "error: Bad request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -837,7 +846,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "error: User not found",
+                        "description": "error: Course not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -857,47 +866,90 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}/role": {
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Allows an admin to update the role of any user.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/courses/{id}/sessions": {
+            "get": {
+                "description": "Retrieve the upcoming class instances of a course, including how many places are booked.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Sessions"
                 ],
-                "summary": "Update a user's role (Admin only)",
+                "summary": "Get upcoming sessions of a course",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Course ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/yoga-guru_internal_models.CourseSession"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/enrollments": {
+            "post": {
+                "security": [
                     {
-                        "description": "New role for the user",
-                        "name": "role",
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Allows a student to enroll in a yoga course with one of its price plans, optionally redeeming a coupon code. A pack bought for a course can be booked in any course matching the pack's filter. Enrollments paid online stay pending until the payment gateway confirms the payment, unless a coupon leaves nothing to pay.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enrollments"
+                ],
+                "summary": "Enroll a student in a course (Student only)",
+                "parameters": [
+                    {
+                        "description": "Enrollment details",
+                        "name": "enrollment",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_controllers.UpdateUserRoleRequest"
+                            "$ref": "#/definitions/internal_controllers.EnrollRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/yoga-guru_internal_models.User"
+                            "$ref": "#/definitions/internal_controllers.EnrollResponse"
                         }
                     },
                     "400": {
@@ -928,7 +980,16 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "error: User not found",
+                        "description": "error: Course not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Already enrolled or coupon used up",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -944,207 +1005,7295 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "502": {
+                        "description": "error: Payment gateway error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "gorm.DeletedAt": {
-            "type": "object",
-            "properties": {
-                "time": {
-                    "type": "string"
-                },
-                "valid": {
-                    "description": "Valid is true if Time is not NULL",
-                    "type": "boolean"
+        },
+        "/enrollments/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a list of all courses a student is enrolled in.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enrollments"
+                ],
+                "summary": "Get student's enrollments",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/yoga-guru_internal_models.Enrollment"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
                 }
             }
         },
-        "internal_controllers.CourseSchedule": {
-            "type": "object",
-            "properties": {
-                "dayOfWeekMask": {
-                    "$ref": "#/definitions/yoga-guru_internal_models.DayOfWeekMask"
-                },
-                "endTime": {
-                    "type": "string"
-                },
-                "recurrence": {
-                    "$ref": "#/definitions/yoga-guru_internal_models.ScheduleRecurrence"
+        "/enrollments/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve details of a specific enrollment by its ID. (Admin/Enrolled Student/Household member only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enrollments"
+                ],
+                "summary": "Get enrollment by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Enrollment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.Enrollment"
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Enrollment not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Allows a student to cancel their enrollment, or an admin to cancel any enrollment. Upcoming bookings made with it are cancelled and their places offered to the waitlist, as are its pending freezes and those yet to start. What was paid is refunded according to the cancellation policy: in full shortly after the start date, pro-rated by unused sessions or remaining time afterwards, and not at all after the cutoff. The enrollment is kept as cancelled. Refunds the payment gateway turns down are listed as failed and can be refunded again by staff.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enrollments"
+                ],
+                "summary": "Cancel an enrollment (Student/Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Enrollment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Cancellation reason and refund options",
+                        "name": "cancellation",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.CancelEnrollmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.CancellationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Enrollment not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Already cancelled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/enrollments/{id}/auto-renew": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An enrollment set to auto-renew is renewed with the same price plan when it expires. The renewal is pending until it is paid online.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enrollments"
+                ],
+                "summary": "Turn auto-renewal of an enrollment on or off (Student/Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Enrollment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Auto-renewal setting",
+                        "name": "autoRenew",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.AutoRenewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.Enrollment"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Enrollment not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Enrollment cannot be renewed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/enrollments/{id}/freezes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the freezes requested for an enrollment. (Admin/Enrolled Student only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Freezes"
+                ],
+                "summary": "Get the freezes of an enrollment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Enrollment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/yoga-guru_internal_models.EnrollmentFreeze"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Enrollment not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Pause a six month or yearly enrollment, for travel or an injury. The freeze must be requested in advance and is limited in days per calendar year. Once approved, which may be immediate depending on the studio, the enrollment cannot be booked during the freeze, bookings made for it are cancelled and the expiration date moves out by the frozen days.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Freezes"
+                ],
+                "summary": "Freeze an enrollment (Student/Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Enrollment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Freeze dates",
+                        "name": "freeze",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.FreezeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.EnrollmentFreeze"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Enrollment not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Overlapping freeze or yearly limit reached",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/enrollments/{id}/freezes/{freezeID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Withdraw a freeze that has not started yet. An approved freeze gives back the days it added to the enrollment.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Freezes"
+                ],
+                "summary": "Cancel a freeze (Student/Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Enrollment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Freeze ID",
+                        "name": "freezeID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.EnrollmentFreeze"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Freeze not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Freeze already started or decided",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/enrollments/{id}/invoice.pdf": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Render the invoice of an enrollment as PDF, with the studio's details, the enrollment as line item and its discount and tax. The invoice is numbered the first time it is downloaded, sequentially and without gaps, and keeps its number and amounts from then on.",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Download the invoice of an enrollment (Staff/Admin/Enrolled Student only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Enrollment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Invoice PDF",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Enrollment not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/enrollments/{id}/payments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the payments recorded against an enrollment and its outstanding balance. (Staff/Admin/Enrolled Student only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Get the payments of an enrollment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Enrollment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.EnrollmentPaymentsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Enrollment not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Record a cash, card or bank transfer payment received at the front desk against an enrollment. A pending enrollment paid in full becomes active.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Record a payment (Staff/Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Enrollment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payment details",
+                        "name": "payment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.RecordPaymentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.Payment"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Enrollment not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Amount exceeds balance or duplicate transaction",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/enrollments/{id}/payments/checkout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Start an online payment for the outstanding balance of an enrollment. The student completes it at the returned gateway URL. (Staff/Admin/Enrolled Student only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Pay an enrollment online",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Enrollment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.CheckoutResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Enrollment not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Nothing to pay",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "error: Payment gateway error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/enrollments/{id}/payments/credit": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Pay an enrollment partially or fully from the enrolled student's wallet. A pending enrollment paid in full becomes active. (Staff/Admin/Enrolled Student only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Pay an enrollment from credit",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Enrollment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Amount to pay",
+                        "name": "payment",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.PayWithCreditRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.Payment"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Enrollment not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Amount exceeds balance or credit",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/enrollments/{id}/payments/{paymentID}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a single payment recorded against an enrollment. (Staff/Admin/Enrolled Student only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Get a payment of an enrollment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Enrollment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Payment ID",
+                        "name": "paymentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.Payment"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Payment not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a cash, card or bank transfer payment recorded by mistake from the ledger. Payments that were refunded, refunds and other payments can't be deleted, refund them instead.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Delete a payment (Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Enrollment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Payment ID",
+                        "name": "paymentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Payment not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Payment can't be deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/enrollments/{id}/payments/{paymentID}/receipt.pdf": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Render the receipt of a succeeded payment as PDF. The receipt is numbered the first time it is downloaded, sequentially and without gaps.",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Download the receipt of a payment (Staff/Admin/Enrolled Student only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Enrollment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Payment ID",
+                        "name": "paymentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Receipt PDF",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Payment not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Payment not succeeded",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/enrollments/{id}/payments/{paymentID}/refund": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Refund what is left unrefunded of a succeeded payment, recorded as a refund payment of its own. The refunded amount is owed again on the enrollment balance. Online payments are paid back through the payment gateway, payments from credit and refunds as credit go to the student's wallet.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Refund a payment (Staff/Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Enrollment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Payment ID",
+                        "name": "paymentID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Refund options",
+                        "name": "refund",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.RefundPaymentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "The refund",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.Payment"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Payment not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Payment has not succeeded or was refunded",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "error: Payment gateway error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/enrollments/{id}/transfer": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move the remaining value of a paid up, active enrollment to a new enrollment, valid until the same expiration date. The remaining value is pro-rated by unused sessions or remaining time like a cancellation refund. Moving to another course prices the rest of the term with the new course's plan: the difference is owed on the new enrollment, or credited to the student's wallet when the new course costs less. Moving to another student hands over the remaining sessions and term as is. Upcoming bookings, pending freezes and freezes yet to start of the original enrollment are cancelled and it is kept as transferred.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enrollments"
+                ],
+                "summary": "Transfer an enrollment to another course or student (Student/Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Enrollment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Transfer target",
+                        "name": "transfer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.TransferEnrollmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.TransferResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Enrollment, course or student not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Enrollment not transferable, used up or already enrolled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/enrollments/{id}/transfers": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the transfers the enrollment was created by or transferred with, oldest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enrollments"
+                ],
+                "summary": "Get the transfers of an enrollment (Student/Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Enrollment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/yoga-guru_internal_models.EnrollmentTransfer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Enrollment not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/freezes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List enrollment freezes, the pending ones waiting for approval by default.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Freezes"
+                ],
+                "summary": "Get freezes by status (Admin only)",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "approved",
+                            "rejected",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "description": "Freeze status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/yoga-guru_internal_models.EnrollmentFreeze"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/freezes/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Approve a pending freeze of an active enrollment. The enrollment's expiration date moves out by the frozen days and bookings made for the frozen days are cancelled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Freezes"
+                ],
+                "summary": "Approve a freeze (Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Freeze ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.EnrollmentFreeze"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Freeze not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Freeze is not pending or enrollment not active",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/freezes/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reject a pending freeze. The enrollment is left unchanged.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Freezes"
+                ],
+                "summary": "Reject a freeze (Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Freeze ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.EnrollmentFreeze"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Freeze not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Freeze is not pending",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/gift-cards": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the issued gift cards, newest first, and who redeemed them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Get all gift cards (Staff/Admin only)",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/yoga-guru_internal_models.GiftCard"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issue a gift card sold at the front desk. Whoever redeems its code gets its amount as credit.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Issue a gift card (Staff/Admin only)",
+                "parameters": [
+                    {
+                        "description": "Gift card details",
+                        "name": "giftCard",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.CreateGiftCardRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.GiftCard"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Code already in use",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/households": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a household owned by the current user. Members added to it can book sessions on the owner's enrollments, which the owner pays for.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Households"
+                ],
+                "summary": "Create a household (Student/Admin only)",
+                "parameters": [
+                    {
+                        "description": "Household details",
+                        "name": "household",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.CreateHouseholdRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.HouseholdResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Household already exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/households/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the household the current user owns or else belongs to, with its members and the owner's current enrollments.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Households"
+                ],
+                "summary": "Get the current user's household (Student/Admin only)",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.HouseholdResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Household not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/households/me/accept": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Join the household the current user was invited to, so they can book sessions on its owner's enrollments. Invitations are declined by leaving the household.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Households"
+                ],
+                "summary": "Accept a household invitation (Student/Admin only)",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.HouseholdResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: No pending invitation",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/households/me/bookings": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The household owner lists the sessions members booked on the owner's enrollments, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Households"
+                ],
+                "summary": "Get the bookings of household members (Student/Admin only)",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_controllers.HouseholdBookingEntry"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Household not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/households/me/members": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Invite a registered user to the household by phone number. The user is notified and, once they accept, is listed as a member and can book sessions on the owner's enrollments, up to their session limit on each enrollment. A user belongs to at most one household. The response is the same whether or not the number belongs to a user who can be invited.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Households"
+                ],
+                "summary": "Invite a member to the current user's household (Student/Admin only)",
+                "parameters": [
+                    {
+                        "description": "Member details",
+                        "name": "member",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.AddHouseholdMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "message: Invitation sent",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Household not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/households/me/members/{userId}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change how many sessions a member of the current user's household can book on each of the owner's enrollments.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Households"
+                ],
+                "summary": "Change a household member's session limit (Student/Admin only)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member user ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Session limit",
+                        "name": "member",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.UpdateHouseholdMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.HouseholdMemberEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Household or member not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The household owner removes a member, or a member leaves their household by removing themselves. Sessions the member already booked stay booked.",
+                "tags": [
+                    "Households"
+                ],
+                "summary": "Remove a member from a household (Student/Admin only)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member user ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Member not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/instructors/me/payouts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve what the current instructor earned in a month under their payout rules, from the sessions taught, their attendance and the net revenue of their courses. Statements are recomputed until they are paid.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payouts"
+                ],
+                "summary": "Get my payout statement (Instructor only)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Month, e.g. 2025-10, defaults to the current month",
+                        "name": "month",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.PayoutStatement"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/instructors/{id}/payout-rules": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve how an instructor is paid. The instructor is paid the sum of their rules.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payouts"
+                ],
+                "summary": "Get the payout rules of an instructor (Admin only)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Instructor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/yoga-guru_internal_models.PayoutRule"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Instructor not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace how an instructor is paid: a flat amount per session taught, an amount per attending student, a share of the net revenue of their courses, or a sum of those. Rules can be limited to a course of the instructor. Open statements pick up the new rules, paid ones are kept.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payouts"
+                ],
+                "summary": "Set the payout rules of an instructor (Admin only)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Instructor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payout rules",
+                        "name": "rules",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.SetPayoutRulesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/yoga-guru_internal_models.PayoutRule"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Instructor not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/instructors/{id}/payouts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve what an instructor earned in a month under their payout rules. Statements are recomputed until they are paid.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payouts"
+                ],
+                "summary": "Get the payout statement of an instructor (Admin only)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Instructor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Month, e.g. 2025-10, defaults to the current month",
+                        "name": "month",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.PayoutStatement"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Instructor not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Authenticate user with email and password, returning a JWT token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Log in a user",
+                "parameters": [
+                    {
+                        "description": "User login credentials",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "token: JWT_TOKEN",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Invalid credentials",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "error: Too many failed login attempts, retryAfter in seconds",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "description": "End the device session of a refresh token. Access tokens stay valid until they expire.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Log out",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "refresh",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Invalid refresh token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/logout/all": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "End every device session of the current user, logging out all their devices once their access tokens expire.",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Log out of all devices",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/otp/request": {
+            "post": {
+                "description": "Send a one-time code to the phone number to log in or sign up with. The response is the same whether or not the number belongs to a user. Codes are limited per number per day, and every request counts against the failed login limit of the client IP.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Text a one-time login code",
+                "parameters": [
+                    {
+                        "description": "Phone number",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.OTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "message, expiresIn in seconds",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "error: A code was sent recently or too often, or too many failed logins",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/otp/verify": {
+            "post": {
+                "description": "Log in with a code texted by /otp/request, returning a JWT token. Phone numbers without an account are signed up as students.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Log in with a one-time code",
+                "parameters": [
+                    {
+                        "description": "Phone number and code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.VerifyOTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "token: JWT_TOKEN, refresh: REFRESH_TOKEN",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Invalid or expired code",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "error: Too many attempts or failed logins",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/password/forgot": {
+            "post": {
+                "description": "Send a code to reset the password with to the phone number of a user. The response is the same whether or not the number belongs to a user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Text a password reset code",
+                "parameters": [
+                    {
+                        "description": "Phone number",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.ForgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "message, expiresIn in seconds",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "error: A code was sent recently or too often, or too many failed logins",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/password/reset": {
+            "post": {
+                "description": "Set a new password with a code texted by /password/forgot. All device sessions of the user are logged out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Reset a forgotten password",
+                "parameters": [
+                    {
+                        "description": "Phone number, code and new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Invalid or expired code",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "error: Too many attempts",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/callback": {
+            "get": {
+                "description": "The payment gateway sends the payer back here. The result is verified with the gateway, the payment settled and a pending enrollment activated. What the payment paid over the outstanding balance, when the enrollment was paid otherwise in the meantime, is refunded. Repeated callbacks for a settled payment have no effect.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Payment gateway callback",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.PaymentCallbackResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid callback",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Payment not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "The payment gateway sends the payer back here. The result is verified with the gateway, the payment settled and a pending enrollment activated. What the payment paid over the outstanding balance, when the enrollment was paid otherwise in the meantime, is refunded. Repeated callbacks for a settled payment have no effect.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Payment gateway callback",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.PaymentCallbackResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid callback",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Payment not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/payouts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the payout statements of all instructors, optionally of a month or status. Only statements fetched before are listed, and open ones as last computed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payouts"
+                ],
+                "summary": "Get payout statements (Admin only)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Month, e.g. 2025-10",
+                        "name": "month",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "open",
+                            "paid"
+                        ],
+                        "type": "string",
+                        "description": "Statement status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/yoga-guru_internal_models.PayoutStatement"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/payouts/{id}/pay": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Recompute a statement of a past month one last time and mark it paid, after which it no longer changes. The instructor is notified.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payouts"
+                ],
+                "summary": "Mark a payout statement paid (Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Statement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payment reference",
+                        "name": "payment",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.MarkPayoutPaidRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.PayoutStatement"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Statement not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Statement already paid or month not over",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/plans": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve all price plans, including inactive ones.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price Plans"
+                ],
+                "summary": "Get all price plans (Admin only)",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/yoga-guru_internal_models.PricePlan"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a price plan for a course, a course type or all courses. The price is the course's per session price times the plan's sessions less the discount, unless a fixed price is set. A pack plan sells a number of sessions bookable in any course matching its pack filter.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price Plans"
+                ],
+                "summary": "Create a price plan (Admin only)",
+                "parameters": [
+                    {
+                        "description": "Price plan details",
+                        "name": "plan",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.PricePlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.PricePlan"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/plans/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update a price plan. Existing enrollments keep the price they were bought at.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price Plans"
+                ],
+                "summary": "Update a price plan (Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Price plan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Price plan details",
+                        "name": "plan",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.PricePlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.PricePlan"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Price plan not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a price plan. Enrollments bought with it are kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price Plans"
+                ],
+                "summary": "Delete a price plan (Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Price plan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Price plan not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/refresh": {
+            "post": {
+                "description": "Exchange a refresh token for a new access token and refresh token. Each refresh token can be used once, presenting a used one again logs out the login it belongs to.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Refresh an access token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "refresh",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "token: JWT_TOKEN, refresh: REFRESH_TOKEN",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Invalid refresh token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: User not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Register a new user with username, email, and password. Role defaults to 'student'.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User registration details",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "message: User registered successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: User with this email or username already exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{id}/attendance": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mark booked students as attended or no-show in bulk. The first mark of a booking spends a session of its enrollment, later marks only correct the attendance. Only the course instructor or an admin can record attendance.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Record attendance for a session (Instructor/Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Attendance records",
+                        "name": "attendance",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.RecordAttendanceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/yoga-guru_internal_models.Attendance"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Session or booking not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{id}/bookings": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Book the current user into a specific course session using one of their enrollments. Capacity is enforced per session. The booking's enrollment shows the sessions left on it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Book a session (Student/Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Enrollment to book with",
+                        "name": "booking",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.BookSessionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.Booking"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Session not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Session full or already booked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{id}/bookings/{bookingID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancel a booking or leave the waitlist. A released place goes to the first eligible student on the waitlist.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Cancel a session booking (Student/Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "bookingID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Booking not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Session has already started",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{id}/checkin.png": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Render a short-lived signed check-in token for the session as a PNG QR code, to be shown at the front desk. Only available while check-in is open.",
+                "produces": [
+                    "image/png"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Get the check-in QR code of a session (Instructor/Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "QR code image",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Session not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Check-in is not open",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{id}/roster": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the students booked into a session along with their attendance status. Only the course instructor or an admin can view it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Get the roster of a session (Instructor/Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_controllers.RosterEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Session not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{id}/waitlist": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queue the current user for a full session. When a booking is cancelled the first eligible student on the waitlist is booked automatically and notified.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Join the waitlist of a full session (Student/Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Enrollment to book with once promoted",
+                        "name": "booking",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.BookSessionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.Booking"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Session not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Session not full or already booked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the profile details of the authenticated user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get current user's profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.UserProfileResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: User not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/password": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the password of the current user. Their other device sessions are logged out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Change my password",
+                "parameters": [
+                    {
+                        "description": "Current and new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Current password is incorrect",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: User not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the devices the current user is logged in on, most recently used first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "List my device sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_controllers.DeviceSessionResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "End one of the current user's device sessions. Its access token stays valid until it expires.",
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Log out a device",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Session not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/role": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Allows an admin to update the role of any user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update a user's role (Admin only)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role for the user",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.UpdateUserRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/yoga-guru_internal_models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: User not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the devices a user is logged in on, most recently used first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "List a user's device sessions (Admin only)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_controllers.DeviceSessionResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid user ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/sessions/{sessionID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "End one of a user's device sessions. Its access token stays valid until it expires.",
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Log out a user's device (Admin only)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error: Invalid user ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Session not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Forget the failed logins of a user's phone number, lifting any wait or lockout on it. Lockouts of IP addresses are not lifted.",
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Unlock a user's login (Admin only)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error: Invalid user ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: User not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/wallet": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the store credit of a user and its transaction history.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Get a user's wallet (Staff/Admin only)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.WalletResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/wallet/adjustments": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add credit to or take credit off a user's wallet, e.g. as a goodwill gesture or to correct a mistake. The reason is kept in the wallet history.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Adjust a user's credit (Admin only)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Amount and reason",
+                        "name": "adjustment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.AdjustCreditRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.WalletResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error: Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: User not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: Not enough credit",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/wallet/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the store credit of the authenticated user and its transaction history.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Get the current user's wallet",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.WalletResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/wallet/redeem": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Load the credit of a gift card into the authenticated user's wallet. A gift card can be redeemed once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Redeem a gift card",
+                "parameters": [
+                    {
+                        "description": "Gift card code",
+                        "name": "giftCard",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.RedeemGiftCardRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controllers.WalletResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid gift card",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "gorm.DeletedAt": {
+            "type": "object",
+            "properties": {
+                "time": {
+                    "type": "string"
+                },
+                "valid": {
+                    "description": "Valid is true if Time is not NULL",
+                    "type": "boolean"
+                }
+            }
+        },
+        "internal_controllers.AddHouseholdMemberRequest": {
+            "type": "object",
+            "required": [
+                "phone"
+            ],
+            "properties": {
+                "phone": {
+                    "type": "string"
+                },
+                "sessionLimit": {
+                    "description": "Zero means unlimited",
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "internal_controllers.AdjustCreditRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "description"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "internal_controllers.AttendanceRecord": {
+            "type": "object",
+            "required": [
+                "bookingId"
+            ],
+            "properties": {
+                "attended": {
+                    "type": "boolean"
+                },
+                "bookingId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controllers.AutoRenewRequest": {
+            "type": "object",
+            "required": [
+                "autoRenew"
+            ],
+            "properties": {
+                "autoRenew": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "internal_controllers.BookSessionRequest": {
+            "type": "object",
+            "properties": {
+                "enrollmentId": {
+                    "description": "EnrollmentID selects the enrollment to book with. When omitted the\nfirst enrollment covering the session is used.",
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controllers.CancelEnrollmentRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "refundAsCredit": {
+                    "description": "RefundAsCredit refunds into the student's wallet instead of paying back.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "internal_controllers.CancellationResponse": {
+            "type": "object",
+            "properties": {
+                "enrollment": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.Enrollment"
+                },
+                "refunded": {
+                    "type": "integer"
+                },
+                "refunds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/yoga-guru_internal_models.Payment"
+                    }
+                }
+            }
+        },
+        "internal_controllers.ChangePasswordRequest": {
+            "type": "object",
+            "required": [
+                "newPassword"
+            ],
+            "properties": {
+                "currentPassword": {
+                    "type": "string"
+                },
+                "newPassword": {
+                    "type": "string",
+                    "minLength": 6
+                }
+            }
+        },
+        "internal_controllers.CheckInRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.CheckoutResponse": {
+            "type": "object",
+            "properties": {
+                "paymentId": {
+                    "type": "integer"
+                },
+                "redirectUrl": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.CouponRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "kind"
+            ],
+            "properties": {
+                "active": {
+                    "description": "Defaults to true",
+                    "type": "boolean"
+                },
+                "amount": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "code": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "courseId": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "kind": {
+                    "enum": [
+                        "percentage",
+                        "fixed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/yoga-guru_internal_models.CouponKind"
+                        }
+                    ]
+                },
+                "maxRedemptions": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "perUserLimit": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "pricePlanId": {
+                    "type": "integer"
+                },
+                "validFrom": {
+                    "type": "string"
+                },
+                "validUntil": {
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Value is the fraction of the price taken off by percentage coupons\n(e.g., 0.20 for 20%), Amount what fixed coupons take off, in minor\nunits of the studio currency.",
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                }
+            }
+        },
+        "internal_controllers.CourseSchedule": {
+            "type": "object",
+            "properties": {
+                "dayOfWeekMask": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.DayOfWeekMask"
+                },
+                "endTime": {
+                    "type": "string"
+                },
+                "recurrence": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.ScheduleRecurrence"
+                },
+                "startTime": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.CreateCourseRequest": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "integer"
+                },
+                "courseType": {
+                    "type": "string"
+                },
+                "level": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.CourseLevel"
+                },
+                "price": {
+                    "description": "Per session, in minor units of the studio currency",
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "schedules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_controllers.CourseSchedule"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.CreateGiftCardRequest": {
+            "type": "object",
+            "required": [
+                "amount"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "code": {
+                    "description": "Generated when empty",
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "expiresAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.CreateHouseholdRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "internal_controllers.DeviceSessionResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "current": {
+                    "description": "The session of the request's access token",
+                    "type": "boolean"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "userAgent": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.EnrollRequest": {
+            "type": "object",
+            "properties": {
+                "couponCode": {
+                    "description": "CouponCode is an optional promotional code taken off the price.",
+                    "type": "string"
+                },
+                "courseID": {
+                    "type": "integer"
+                },
+                "enrollmentType": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.EnrollmentType"
+                },
+                "payOnline": {
+                    "description": "PayOnline keeps the enrollment pending until it is paid through the\npayment gateway.",
+                    "type": "boolean"
+                },
+                "planID": {
+                    "description": "PlanID is the price plan to buy. Without it, the most specific plan of\nthe course for EnrollmentType is used.",
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controllers.EnrollResponse": {
+            "type": "object",
+            "properties": {
+                "attendances": {
+                    "description": "A user can have many attendance records under this enrollment.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/yoga-guru_internal_models.Attendance"
+                    }
+                },
+                "autoRenew": {
+                    "description": "AutoRenew renews the enrollment with the same plan when it expires.",
+                    "type": "boolean"
+                },
+                "balance": {
+                    "description": "Balance is the amount still owed, PricePaid minus succeeded payments,\nor zero once cancelled or transferred. It is computed from the payments\nledger and not stored.",
+                    "type": "integer"
+                },
+                "cancellationReason": {
+                    "type": "string"
+                },
+                "cancelledAt": {
+                    "description": "CancelledAt and CancellationReason are set once the enrollment is\ncancelled.",
+                    "type": "string"
+                },
+                "course": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.Course"
+                },
+                "courseID": {
+                    "description": "This is the main course this enrollment is for",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "Currency is what the amounts of the enrollment and its payments are\nin, the studio currency when it was bought.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/yoga-guru_internal_money.Currency"
+                        }
+                    ]
+                },
+                "deletedAt": {
+                    "$ref": "#/definitions/gorm.DeletedAt"
+                },
+                "discountApplied": {
+                    "description": "Fraction taken off by the plan and any coupon (e.g., 0.10 for 10%)",
+                    "type": "number",
+                    "format": "float64"
+                },
+                "enrollmentType": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.EnrollmentType"
+                },
+                "expirationDate": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "netPrice": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "packFilter": {
+                    "description": "PackFilter selects the courses a pack enrollment can be booked in,\nbesides the course it was bought for.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/yoga-guru_internal_models.CourseFilter"
+                        }
+                    ]
+                },
+                "paymentUrl": {
+                    "type": "string"
+                },
+                "payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/yoga-guru_internal_models.Payment"
+                    }
+                },
+                "pricePaid": {
+                    "description": "The gross price, NetPrice plus Tax",
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "pricePlan": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.PricePlan"
+                },
+                "pricePlanID": {
+                    "description": "The plan the enrollment was bought with",
+                    "type": "integer"
+                },
+                "renewalReminderSentAt": {
+                    "type": "string"
+                },
+                "renewedFromID": {
+                    "description": "The enrollment this one renewed",
+                    "type": "integer"
+                },
+                "sessionsLeft": {
+                    "description": "SessionsLeft is how many sessions of a fixed session package can\nstill be booked, nil when unlimited. It is not stored.",
+                    "type": "integer"
+                },
+                "sessionsUsed": {
+                    "description": "Counter for fixed session packages",
+                    "type": "integer"
+                },
+                "startDate": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.EnrollmentStatus"
+                },
+                "tax": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "taxRate": {
+                    "description": "Fraction of NetPrice charged as Tax (e.g., 0.09 for 9% VAT)",
+                    "type": "number",
+                    "format": "float64"
+                },
+                "totalSessions": {
+                    "description": "Only for fixed session packages, zero means unlimited",
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.User"
+                },
+                "userID": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.EnrollmentPaymentsResponse": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "integer"
+                },
+                "enrollmentId": {
+                    "type": "integer"
+                },
+                "payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/yoga-guru_internal_models.Payment"
+                    }
+                },
+                "pricePaid": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controllers.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "phone"
+            ],
+            "properties": {
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.FreezeRequest": {
+            "type": "object",
+            "required": [
+                "endDate",
+                "startDate"
+            ],
+            "properties": {
+                "endDate": {
+                    "description": "Last frozen day",
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "startDate": {
+                    "description": "First frozen day",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.HouseholdBookingEntry": {
+            "type": "object",
+            "properties": {
+                "bookingId": {
+                    "type": "integer"
+                },
+                "courseTitle": {
+                    "type": "string"
+                },
+                "enrollmentId": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scheduledAt": {
+                    "type": "string"
+                },
+                "sessionId": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.BookingStatus"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.HouseholdMemberEntry": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "sessionLimit": {
+                    "description": "Per enrollment of the owner, zero means unlimited",
+                    "type": "integer"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.HouseholdResponse": {
+            "type": "object",
+            "properties": {
+                "enrollments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/yoga-guru_internal_models.Enrollment"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_controllers.HouseholdMemberEntry"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "ownerId": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.LoginRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "phone"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.MarkPayoutPaidRequest": {
+            "type": "object",
+            "properties": {
+                "reference": {
+                    "description": "e.g. the bank transfer the instructor was paid with",
+                    "type": "string",
+                    "maxLength": 200
+                }
+            }
+        },
+        "internal_controllers.OTPRequest": {
+            "type": "object",
+            "required": [
+                "phone"
+            ],
+            "properties": {
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.PayWithCreditRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount defaults to as much of the balance as the wallet covers.",
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controllers.PaymentCallbackResponse": {
+            "type": "object",
+            "properties": {
+                "enrollmentId": {
+                    "type": "integer"
+                },
+                "paymentId": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.PaymentStatus"
+                }
+            }
+        },
+        "internal_controllers.PayoutRuleRequest": {
+            "type": "object",
+            "required": [
+                "kind"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Amount is paid per session or attendee, in minor units of the studio\ncurrency. Share is the fraction of revenue paid for revenue shares.",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "courseId": {
+                    "description": "Limits the rule to a course of the instructor",
+                    "type": "integer"
+                },
+                "kind": {
+                    "enum": [
+                        "per_session",
+                        "per_attendee",
+                        "revenue_share"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/yoga-guru_internal_models.PayoutRuleKind"
+                        }
+                    ]
+                },
+                "share": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                }
+            }
+        },
+        "internal_controllers.PlanQuote": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Inactive plans can no longer be bought",
+                    "type": "boolean"
+                },
+                "courseID": {
+                    "description": "Set for a plan of a single course",
+                    "type": "integer"
+                },
+                "courseType": {
+                    "description": "Set for a plan of a course type, empty for all courses",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "currency": {
+                    "$ref": "#/definitions/yoga-guru_internal_money.Currency"
+                },
+                "deletedAt": {
+                    "$ref": "#/definitions/gorm.DeletedAt"
+                },
+                "discount": {
+                    "description": "Stored as a fraction (e.g., 0.10 for 10%)",
+                    "type": "number",
+                    "format": "float64"
+                },
+                "discountApplied": {
+                    "type": "number"
+                },
+                "durationMonths": {
+                    "description": "How long the enrollment is valid",
+                    "type": "integer"
+                },
+                "enrollmentType": {
+                    "description": "Kind of enrollment the plan sells",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/yoga-guru_internal_models.EnrollmentType"
+                        }
+                    ]
+                },
+                "fixedPrice": {
+                    "description": "Overrides the price computed from sessions and discount",
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "netPrice": {
+                    "type": "integer"
+                },
+                "packFilter": {
+                    "description": "PackFilter selects the courses a pack can be booked in. It is only\nused by plans of the pack enrollment type.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/yoga-guru_internal_models.CourseFilter"
+                        }
+                    ]
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "priceDisplay": {
+                    "description": "PriceDisplay is Price formatted in the studio's display currency,\ne.g. \"125,000 Toman\".",
+                    "type": "string"
+                },
+                "sessionLimit": {
+                    "description": "Sessions the enrollment allows, zero means unlimited",
+                    "type": "integer"
+                },
+                "sessions": {
+                    "description": "Sessions priced into the plan, at the course's per session price",
+                    "type": "integer"
+                },
+                "tax": {
+                    "type": "integer"
+                },
+                "taxRate": {
+                    "type": "number"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.PricePlanRequest": {
+            "type": "object",
+            "required": [
+                "durationMonths",
+                "enrollmentType",
+                "name"
+            ],
+            "properties": {
+                "active": {
+                    "description": "Defaults to true",
+                    "type": "boolean"
+                },
+                "courseId": {
+                    "description": "CourseID limits the plan to a single course, CourseType to the courses\nof a type. With neither the plan applies to every course.",
+                    "type": "integer"
+                },
+                "courseType": {
+                    "type": "string"
+                },
+                "discount": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "durationMonths": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "enrollmentType": {
+                    "enum": [
+                        "pre_session",
+                        "monthly",
+                        "six_month",
+                        "yearly",
+                        "pack"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/yoga-guru_internal_models.EnrollmentType"
+                        }
+                    ]
+                },
+                "fixedPrice": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string"
+                },
+                "packCourseType": {
+                    "description": "PackCourseType, PackLevel and PackInstructorID restrict the courses a\npack can be booked in. They are ignored for other enrollment types.",
+                    "type": "string"
+                },
+                "packInstructorId": {
+                    "type": "string"
+                },
+                "packLevel": {
+                    "enum": [
+                        "beginner",
+                        "intermediate",
+                        "advanced"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/yoga-guru_internal_models.CourseLevel"
+                        }
+                    ]
+                },
+                "sessionLimit": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "sessions": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "internal_controllers.RecordAttendanceRequest": {
+            "type": "object",
+            "required": [
+                "records"
+            ],
+            "properties": {
+                "records": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/internal_controllers.AttendanceRecord"
+                    }
+                }
+            }
+        },
+        "internal_controllers.RecordPaymentRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "method"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "method": {
+                    "enum": [
+                        "cash",
+                        "card",
+                        "bank_transfer"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/yoga-guru_internal_models.PaymentMethod"
+                        }
+                    ]
+                },
+                "paymentDate": {
+                    "description": "Defaults to now",
+                    "type": "string"
+                },
+                "transactionId": {
+                    "description": "Card terminal or bank reference, if any",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.RedeemGiftCardRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.RefreshTokenRequest": {
+            "type": "object",
+            "required": [
+                "refreshToken"
+            ],
+            "properties": {
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.RefundPaymentRequest": {
+            "type": "object",
+            "properties": {
+                "asCredit": {
+                    "description": "AsCredit refunds into the student's wallet instead of paying back.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "internal_controllers.RegisterRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "password",
+                "phone"
+            ],
+            "properties": {
+                "gender": {
+                    "type": "string",
+                    "enum": [
+                        "male",
+                        "female"
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 6
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "student",
+                        "instructor"
+                    ]
+                }
+            }
+        },
+        "internal_controllers.ResetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "newPassword",
+                "phone"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "newPassword": {
+                    "type": "string",
+                    "minLength": 6
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.RosterEntry": {
+            "type": "object",
+            "properties": {
+                "bookingId": {
+                    "type": "integer"
+                },
+                "enrollmentId": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.BookingStatus"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.SetPayoutRulesRequest": {
+            "type": "object",
+            "properties": {
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_controllers.PayoutRuleRequest"
+                    }
+                }
+            }
+        },
+        "internal_controllers.TransferEnrollmentRequest": {
+            "type": "object",
+            "properties": {
+                "courseId": {
+                    "description": "CourseID moves the enrollment to another course, bought with PlanID\nor else the course's plan of the same enrollment type.",
+                    "type": "integer"
+                },
+                "phone": {
+                    "description": "Phone moves the enrollment to the student with this phone number.",
+                    "type": "string"
+                },
+                "planId": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "internal_controllers.TransferResponse": {
+            "type": "object",
+            "properties": {
+                "enrollment": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.Enrollment"
+                },
+                "transfer": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.EnrollmentTransfer"
+                }
+            }
+        },
+        "internal_controllers.UpdateCourseRequest": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "integer"
+                },
+                "courseType": {
+                    "type": "string"
+                },
+                "level": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.CourseLevel"
+                },
+                "price": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "schedules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_controllers.CourseSchedule"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.UpdateHouseholdMemberRequest": {
+            "type": "object",
+            "required": [
+                "sessionLimit"
+            ],
+            "properties": {
+                "sessionLimit": {
+                    "description": "Zero means unlimited",
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "internal_controllers.UpdateUserRoleRequest": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.UserProfileResponse": {
+            "type": "object",
+            "properties": {
+                "avatarURL": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.VerifyOTPRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "phone"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string",
+                    "enum": [
+                        "male",
+                        "female"
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controllers.WalletResponse": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "integer"
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/yoga-guru_internal_models.CreditTransaction"
+                    }
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "yoga-guru_internal_models.Attendance": {
+            "type": "object",
+            "properties": {
+                "attended": {
+                    "description": "` + "`" + `true` + "`" + ` if the user attended, ` + "`" + `false` + "`" + ` otherwise",
+                    "type": "boolean"
+                },
+                "bookingID": {
+                    "description": "The booking the attendance was recorded for",
+                    "type": "integer"
+                },
+                "courseSession": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.CourseSession"
+                },
+                "courseSessionID": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "$ref": "#/definitions/gorm.DeletedAt"
+                },
+                "enrollment": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.Enrollment"
+                },
+                "enrollmentID": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "recordedAt": {
+                    "description": "You might add a field like ` + "`" + `NoShow` + "`" + ` or ` + "`" + `CanceledByStudent` + "`" + ` for more detailed tracking.",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.User"
+                },
+                "userID": {
+                    "type": "string"
+                }
+            }
+        },
+        "yoga-guru_internal_models.Booking": {
+            "type": "object",
+            "properties": {
+                "cancelledAt": {
+                    "type": "string"
+                },
+                "courseSession": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.CourseSession"
+                },
+                "courseSessionID": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "$ref": "#/definitions/gorm.DeletedAt"
+                },
+                "enrollment": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.Enrollment"
+                },
+                "enrollmentID": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "position": {
+                    "description": "Place in the waitlist, zero once booked",
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.BookingStatus"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.User"
+                },
+                "userID": {
+                    "type": "string"
+                }
+            }
+        },
+        "yoga-guru_internal_models.BookingStatus": {
+            "type": "string",
+            "enum": [
+                "booked",
+                "waitlisted",
+                "cancelled",
+                "attended",
+                "no_show"
+            ],
+            "x-enum-varnames": [
+                "BookingBooked",
+                "BookingWaitlisted",
+                "BookingCancelled",
+                "BookingAttended",
+                "BookingNoShow"
+            ]
+        },
+        "yoga-guru_internal_models.Coupon": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "amount": {
+                    "description": "Only for fixed coupons",
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "code": {
+                    "description": "Stored upper case",
+                    "type": "string"
+                },
+                "courseID": {
+                    "description": "Restricts the coupon to a course when set",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "$ref": "#/definitions/gorm.DeletedAt"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.CouponKind"
+                },
+                "maxRedemptions": {
+                    "description": "Zero means unlimited",
+                    "type": "integer"
+                },
+                "perUserLimit": {
+                    "description": "Zero means unlimited",
+                    "type": "integer"
+                },
+                "pricePlanID": {
+                    "description": "Restricts the coupon to a price plan when set",
+                    "type": "integer"
+                },
+                "redemptions": {
+                    "description": "Counter of redemptions so far",
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "validFrom": {
+                    "description": "Open ended when not set",
+                    "type": "string"
+                },
+                "validUntil": {
+                    "description": "Open ended when not set",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Only for percentage coupons",
+                    "type": "number",
+                    "format": "float64"
+                }
+            }
+        },
+        "yoga-guru_internal_models.CouponKind": {
+            "type": "string",
+            "enum": [
+                "percentage",
+                "fixed"
+            ],
+            "x-enum-comments": {
+                "CouponFixed": "Amount is taken off the price",
+                "CouponPercentage": "Value is a fraction of the price (e.g., 0.20 for 20%)"
+            },
+            "x-enum-descriptions": [
+                "Value is a fraction of the price (e.g., 0.20 for 20%)",
+                "Amount is taken off the price"
+            ],
+            "x-enum-varnames": [
+                "CouponPercentage",
+                "CouponFixed"
+            ]
+        },
+        "yoga-guru_internal_models.CouponRedemption": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "The amount taken off the enrollment price",
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "coupon": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.Coupon"
+                },
+                "couponID": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "$ref": "#/definitions/gorm.DeletedAt"
+                },
+                "enrollmentID": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "string"
+                }
+            }
+        },
+        "yoga-guru_internal_models.Course": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "description": "Max number of students",
+                    "type": "integer"
+                },
+                "courseType": {
+                    "description": "e.g., Hatha, Vinyasa, Ashtanga",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "$ref": "#/definitions/gorm.DeletedAt"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "instructor": {
+                    "description": "GORM association",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/yoga-guru_internal_models.User"
+                        }
+                    ]
+                },
+                "instructorID": {
+                    "description": "ID of the instructor creating the course",
+                    "type": "string"
+                },
+                "level": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.CourseLevel"
+                },
+                "price": {
+                    "description": "Price per single session, in minor units of the studio currency",
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "schedules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/yoga-guru_internal_models.Schedule"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "yoga-guru_internal_models.CourseFilter": {
+            "type": "object",
+            "properties": {
+                "courseType": {
+                    "type": "string"
+                },
+                "instructorID": {
+                    "type": "string"
+                },
+                "level": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.CourseLevel"
+                }
+            }
+        },
+        "yoga-guru_internal_models.CourseLevel": {
+            "type": "string",
+            "enum": [
+                "beginner",
+                "intermediate",
+                "advanced"
+            ],
+            "x-enum-varnames": [
+                "Beginner",
+                "Intermediate",
+                "Advanced"
+            ]
+        },
+        "yoga-guru_internal_models.CourseSession": {
+            "type": "object",
+            "properties": {
+                "bookedCount": {
+                    "description": "Number of active bookings, never exceeds the course capacity",
+                    "type": "integer"
+                },
+                "course": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.Course"
+                },
+                "courseID": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "$ref": "#/definitions/gorm.DeletedAt"
+                },
+                "endsAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "isCanceled": {
+                    "description": "Optional: You could add fields like ` + "`" + `Room` + "`" + `, ` + "`" + `InstructorID` + "`" + ` here if they vary.",
+                    "type": "boolean"
+                },
+                "scheduleID": {
+                    "description": "The schedule this session was generated from",
+                    "type": "integer"
+                },
+                "scheduledAt": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "yoga-guru_internal_models.CreditTransaction": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Positive when credit is added, negative when spent",
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "$ref": "#/definitions/gorm.DeletedAt"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "giftCardID": {
+                    "description": "The gift card the credit was loaded from",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.CreditTransactionKind"
+                },
+                "paymentID": {
+                    "description": "The payment the credit was spent on or refunded from",
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "string"
+                }
+            }
+        },
+        "yoga-guru_internal_models.CreditTransactionKind": {
+            "type": "string",
+            "enum": [
+                "gift_card",
+                "payment",
+                "refund",
+                "adjustment"
+            ],
+            "x-enum-comments": {
+                "CreditAdjustment": "Credit changed by an admin",
+                "CreditGiftCard": "Credit loaded from a gift card",
+                "CreditPayment": "Credit spent on an enrollment",
+                "CreditRefund": "Credit issued for a refund"
+            },
+            "x-enum-descriptions": [
+                "Credit loaded from a gift card",
+                "Credit spent on an enrollment",
+                "Credit issued for a refund",
+                "Credit changed by an admin"
+            ],
+            "x-enum-varnames": [
+                "CreditGiftCard",
+                "CreditPayment",
+                "CreditRefund",
+                "CreditAdjustment"
+            ]
+        },
+        "yoga-guru_internal_models.DayOfWeekMask": {
+            "type": "integer",
+            "enum": [
+                1,
+                2,
+                4,
+                8,
+                16,
+                32,
+                64
+            ],
+            "x-enum-comments": {
+                "Friday": "64 (1000000)",
+                "Monday": "4 (0000100)",
+                "Saturday": "1 (0000001)",
+                "Sunday": "2 (0000010)",
+                "Thursday": "32 (0100000)",
+                "Tuesday": "8 (0001000)",
+                "Wednesday": "16 (0010000)"
+            },
+            "x-enum-descriptions": [
+                "1 (0000001)",
+                "2 (0000010)",
+                "4 (0000100)",
+                "8 (0001000)",
+                "16 (0010000)",
+                "32 (0100000)",
+                "64 (1000000)"
+            ],
+            "x-enum-varnames": [
+                "Saturday",
+                "Sunday",
+                "Monday",
+                "Tuesday",
+                "Wednesday",
+                "Thursday",
+                "Friday"
+            ]
+        },
+        "yoga-guru_internal_models.Enrollment": {
+            "type": "object",
+            "properties": {
+                "attendances": {
+                    "description": "A user can have many attendance records under this enrollment.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/yoga-guru_internal_models.Attendance"
+                    }
+                },
+                "autoRenew": {
+                    "description": "AutoRenew renews the enrollment with the same plan when it expires.",
+                    "type": "boolean"
+                },
+                "balance": {
+                    "description": "Balance is the amount still owed, PricePaid minus succeeded payments,\nor zero once cancelled or transferred. It is computed from the payments\nledger and not stored.",
+                    "type": "integer"
+                },
+                "cancellationReason": {
+                    "type": "string"
+                },
+                "cancelledAt": {
+                    "description": "CancelledAt and CancellationReason are set once the enrollment is\ncancelled.",
+                    "type": "string"
+                },
+                "course": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.Course"
+                },
+                "courseID": {
+                    "description": "This is the main course this enrollment is for",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "Currency is what the amounts of the enrollment and its payments are\nin, the studio currency when it was bought.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/yoga-guru_internal_money.Currency"
+                        }
+                    ]
+                },
+                "deletedAt": {
+                    "$ref": "#/definitions/gorm.DeletedAt"
+                },
+                "discountApplied": {
+                    "description": "Fraction taken off by the plan and any coupon (e.g., 0.10 for 10%)",
+                    "type": "number",
+                    "format": "float64"
+                },
+                "enrollmentType": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.EnrollmentType"
+                },
+                "expirationDate": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "netPrice": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "packFilter": {
+                    "description": "PackFilter selects the courses a pack enrollment can be booked in,\nbesides the course it was bought for.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/yoga-guru_internal_models.CourseFilter"
+                        }
+                    ]
+                },
+                "payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/yoga-guru_internal_models.Payment"
+                    }
+                },
+                "pricePaid": {
+                    "description": "The gross price, NetPrice plus Tax",
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "pricePlan": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.PricePlan"
+                },
+                "pricePlanID": {
+                    "description": "The plan the enrollment was bought with",
+                    "type": "integer"
+                },
+                "renewalReminderSentAt": {
+                    "type": "string"
+                },
+                "renewedFromID": {
+                    "description": "The enrollment this one renewed",
+                    "type": "integer"
+                },
+                "sessionsLeft": {
+                    "description": "SessionsLeft is how many sessions of a fixed session package can\nstill be booked, nil when unlimited. It is not stored.",
+                    "type": "integer"
+                },
+                "sessionsUsed": {
+                    "description": "Counter for fixed session packages",
+                    "type": "integer"
+                },
+                "startDate": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.EnrollmentStatus"
+                },
+                "tax": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "taxRate": {
+                    "description": "Fraction of NetPrice charged as Tax (e.g., 0.09 for 9% VAT)",
+                    "type": "number",
+                    "format": "float64"
+                },
+                "totalSessions": {
+                    "description": "Only for fixed session packages, zero means unlimited",
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.User"
                 },
-                "startTime": {
+                "userID": {
                     "type": "string"
                 }
             }
         },
-        "internal_controllers.CreateCourseRequest": {
+        "yoga-guru_internal_models.EnrollmentFreeze": {
             "type": "object",
             "properties": {
-                "capacity": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "days": {
                     "type": "integer"
                 },
-                "courseType": {
+                "decidedAt": {
                     "type": "string"
                 },
-                "level": {
-                    "$ref": "#/definitions/yoga-guru_internal_models.CourseLevel"
-                },
-                "price": {
-                    "type": "number",
-                    "format": "float64"
+                "decidedByID": {
+                    "description": "The admin who approved or rejected the freeze",
+                    "type": "string"
                 },
-                "schedules": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/internal_controllers.CourseSchedule"
-                    }
+                "deletedAt": {
+                    "$ref": "#/definitions/gorm.DeletedAt"
                 },
-                "title": {
+                "endDate": {
+                    "description": "Day the enrollment can be used again",
                     "type": "string"
-                }
-            }
-        },
-        "internal_controllers.EnrollRequest": {
-            "type": "object",
-            "properties": {
-                "courseID": {
+                },
+                "enrollment": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.Enrollment"
+                },
+                "enrollmentID": {
                     "type": "integer"
                 },
-                "enrollmentType": {
-                    "$ref": "#/definitions/yoga-guru_internal_models.EnrollmentType"
-                }
-            }
-        },
-        "internal_controllers.LoginRequest": {
-            "type": "object",
-            "required": [
-                "password",
-                "phone"
-            ],
-            "properties": {
-                "password": {
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
                     "type": "string"
                 },
-                "phone": {
+                "startDate": {
+                    "description": "First frozen day",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.FreezeStatus"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "userID": {
                     "type": "string"
                 }
             }
         },
-        "internal_controllers.RefreshTokenRequest": {
-            "type": "object",
-            "required": [
-                "refreshToken"
+        "yoga-guru_internal_models.EnrollmentStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "active",
+                "cancelled",
+                "expired",
+                "transferred"
             ],
-            "properties": {
-                "refreshToken": {
-                    "type": "string"
-                }
-            }
+            "x-enum-comments": {
+                "EnrollmentPending": "Waiting for an online payment",
+                "EnrollmentTransferred": "Its remaining value moved to another enrollment"
+            },
+            "x-enum-descriptions": [
+                "Waiting for an online payment",
+                "",
+                "",
+                "",
+                "Its remaining value moved to another enrollment"
+            ],
+            "x-enum-varnames": [
+                "EnrollmentPending",
+                "EnrollmentActive",
+                "EnrollmentCancelled",
+                "EnrollmentExpired",
+                "EnrollmentTransferred"
+            ]
         },
-        "internal_controllers.RegisterRequest": {
+        "yoga-guru_internal_models.EnrollmentTransfer": {
             "type": "object",
-            "required": [
-                "name",
-                "password",
-                "phone"
-            ],
             "properties": {
-                "gender": {
-                    "type": "string",
-                    "enum": [
-                        "male",
-                        "female"
-                    ]
-                },
-                "name": {
+                "createdAt": {
                     "type": "string"
                 },
-                "password": {
-                    "type": "string",
-                    "minLength": 6
+                "deletedAt": {
+                    "$ref": "#/definitions/gorm.DeletedAt"
                 },
-                "phone": {
+                "fromCourseID": {
+                    "type": "integer"
+                },
+                "fromEnrollmentID": {
+                    "type": "integer"
+                },
+                "fromUserID": {
                     "type": "string"
                 },
-                "role": {
-                    "type": "string",
-                    "enum": [
-                        "student",
-                        "instructor"
-                    ]
-                }
-            }
-        },
-        "internal_controllers.UpdateCourseRequest": {
-            "type": "object",
-            "properties": {
-                "capacity": {
+                "id": {
                     "type": "integer"
                 },
-                "courseType": {
+                "priceDifference": {
+                    "description": "PriceDifference is what the new enrollment costs over Value, owed on\nit when positive and credited to the wallet of FromUserID when negative.",
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "reason": {
                     "type": "string"
                 },
-                "level": {
-                    "$ref": "#/definitions/yoga-guru_internal_models.CourseLevel"
+                "toCourseID": {
+                    "type": "integer"
                 },
-                "price": {
-                    "type": "number",
-                    "format": "float64"
+                "toEnrollmentID": {
+                    "type": "integer"
                 },
-                "schedules": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/internal_controllers.CourseSchedule"
-                    }
+                "toUserID": {
+                    "type": "string"
                 },
-                "title": {
+                "transferredByID": {
+                    "description": "The student or admin who made the transfer",
                     "type": "string"
-                }
-            }
-        },
-        "internal_controllers.UpdateUserRoleRequest": {
-            "type": "object",
-            "properties": {
-                "role": {
+                },
+                "updatedAt": {
                     "type": "string"
+                },
+                "value": {
+                    "description": "Remaining value of the original enrollment",
+                    "type": "integer",
+                    "format": "int64"
                 }
             }
         },
-        "internal_controllers.UserProfileResponse": {
+        "yoga-guru_internal_models.EnrollmentType": {
+            "type": "string",
+            "enum": [
+                "pre_session",
+                "monthly",
+                "six_month",
+                "yearly",
+                "pack"
+            ],
+            "x-enum-comments": {
+                "Pack": "Sessions usable in any course matching the pack's filter"
+            },
+            "x-enum-descriptions": [
+                "",
+                "",
+                "",
+                "",
+                "Sessions usable in any course matching the pack's filter"
+            ],
+            "x-enum-varnames": [
+                "PreSession",
+                "Monthly",
+                "SixMonth",
+                "Yearly",
+                "Pack"
+            ]
+        },
+        "yoga-guru_internal_models.FreezeStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "approved",
+                "rejected",
+                "cancelled"
+            ],
+            "x-enum-comments": {
+                "FreezePending": "Waiting for an admin to approve it"
+            },
+            "x-enum-descriptions": [
+                "Waiting for an admin to approve it",
+                "",
+                "",
+                ""
+            ],
+            "x-enum-varnames": [
+                "FreezePending",
+                "FreezeApproved",
+                "FreezeRejected",
+                "FreezeCancelled"
+            ]
+        },
+        "yoga-guru_internal_models.GiftCard": {
             "type": "object",
             "properties": {
-                "avatarURL": {
+                "amount": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "code": {
                     "type": "string"
                 },
-                "gender": {
+                "createdAt": {
                     "type": "string"
                 },
-                "name": {
+                "deletedAt": {
+                    "$ref": "#/definitions/gorm.DeletedAt"
+                },
+                "expiresAt": {
+                    "description": "Never expires when not set",
                     "type": "string"
                 },
-                "phone": {
+                "id": {
+                    "type": "integer"
+                },
+                "issuedByID": {
+                    "description": "The staff member or admin who sold the gift card",
+                    "type": "string"
+                },
+                "redeemedAt": {
+                    "type": "string"
+                },
+                "redeemedByID": {
+                    "type": "string"
+                },
+                "updatedAt": {
                     "type": "string"
                 }
             }
         },
-        "yoga-guru_internal_models.Attendance": {
+        "yoga-guru_internal_models.Payment": {
             "type": "object",
             "properties": {
-                "attended": {
-                    "description": "` + "`" + `true` + "`" + ` if the user attended, ` + "`" + `false` + "`" + ` otherwise",
-                    "type": "boolean"
-                },
-                "courseSession": {
-                    "$ref": "#/definitions/yoga-guru_internal_models.CourseSession"
-                },
-                "courseSessionID": {
-                    "type": "integer"
+                "amount": {
+                    "description": "The amount of this specific payment, tax included",
+                    "type": "integer",
+                    "format": "int64"
                 },
                 "createdAt": {
                     "type": "string"
                 },
+                "currency": {
+                    "description": "The currency of the enrollment paid for",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/yoga-guru_internal_money.Currency"
+                        }
+                    ]
+                },
                 "deletedAt": {
                     "$ref": "#/definitions/gorm.DeletedAt"
                 },
@@ -1152,36 +8301,119 @@ const docTemplate = `{
                     "$ref": "#/definitions/yoga-guru_internal_models.Enrollment"
                 },
                 "enrollmentID": {
+                    "description": "Foreign key to the enrollment this payment is for",
                     "type": "integer"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "recordedAt": {
-                    "description": "You might add a field like ` + "`" + `NoShow` + "`" + ` or ` + "`" + `CanceledByStudent` + "`" + ` for more detailed tracking.",
-                    "type": "string"
+                "method": {
+                    "description": "e.g., 'card', 'cash'",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/yoga-guru_internal_models.PaymentMethod"
+                        }
+                    ]
                 },
-                "updatedAt": {
-                    "type": "string"
+                "netAmount": {
+                    "description": "Amount less Tax",
+                    "type": "integer",
+                    "format": "int64"
                 },
-                "user": {
-                    "$ref": "#/definitions/yoga-guru_internal_models.User"
+                "paymentDate": {
+                    "type": "string"
                 },
-                "userID": {
+                "refundOfID": {
                     "type": "integer"
+                },
+                "refunded": {
+                    "description": "Refunds are payments of their own, kept apart from the succeeded\npayment they pay back. Refunded is how much of a succeeded payment was\nrefunded so far, and RefundOfID links a refund to the payment.",
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "status": {
+                    "description": "e.g., 'succeeded', 'failed', 'pending'",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/yoga-guru_internal_models.PaymentStatus"
+                        }
+                    ]
+                },
+                "tax": {
+                    "description": "The part of Amount that is tax, at the enrollment's tax rate",
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "transactionID": {
+                    "description": "External ID from payment gateway (e.g., Stripe)",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
                 }
             }
         },
-        "yoga-guru_internal_models.Course": {
+        "yoga-guru_internal_models.PaymentMethod": {
+            "type": "string",
+            "enum": [
+                "card",
+                "cash",
+                "bank_transfer",
+                "online_payment",
+                "credit",
+                "transfer"
+            ],
+            "x-enum-comments": {
+                "Credit": "Paid from the user's wallet",
+                "Transfer": "Value moved from a transferred enrollment"
+            },
+            "x-enum-descriptions": [
+                "",
+                "",
+                "",
+                "",
+                "Paid from the user's wallet",
+                "Value moved from a transferred enrollment"
+            ],
+            "x-enum-varnames": [
+                "Card",
+                "Cash",
+                "BankTransfer",
+                "OnlinePayment",
+                "Credit",
+                "Transfer"
+            ]
+        },
+        "yoga-guru_internal_models.PaymentStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "succeeded",
+                "failed",
+                "refunded",
+                "refund_pending",
+                "refund_failed"
+            ],
+            "x-enum-varnames": [
+                "PaymentPending",
+                "PaymentSucceeded",
+                "PaymentFailed",
+                "PaymentRefunded",
+                "PaymentRefundPending",
+                "PaymentRefundFailed"
+            ]
+        },
+        "yoga-guru_internal_models.PayoutLine": {
             "type": "object",
             "properties": {
-                "capacity": {
-                    "description": "Max number of students",
-                    "type": "integer"
+                "amount": {
+                    "type": "integer",
+                    "format": "int64"
                 },
-                "courseType": {
-                    "description": "e.g., Hatha, Vinyasa, Ashtanga",
-                    "type": "string"
+                "basis": {
+                    "description": "Amount per session or attendee, or the revenue shared",
+                    "type": "integer",
+                    "format": "int64"
                 },
                 "createdAt": {
                     "type": "string"
@@ -1189,63 +8421,40 @@ const docTemplate = `{
                 "deletedAt": {
                     "$ref": "#/definitions/gorm.DeletedAt"
                 },
+                "description": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "instructor": {
-                    "description": "GORM association",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/yoga-guru_internal_models.User"
-                        }
-                    ]
-                },
-                "instructorID": {
-                    "description": "ID of the instructor creating the course",
+                "quantity": {
+                    "description": "Sessions or attendees, one for revenue shares",
                     "type": "integer"
                 },
-                "level": {
-                    "$ref": "#/definitions/yoga-guru_internal_models.CourseLevel"
-                },
-                "price": {
-                    "description": "Price per single session",
-                    "type": "number",
-                    "format": "float64"
-                },
-                "schedules": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/yoga-guru_internal_models.Schedule"
-                    }
+                "ruleID": {
+                    "type": "integer"
                 },
-                "title": {
-                    "type": "string"
+                "statementID": {
+                    "type": "integer"
                 },
                 "updatedAt": {
                     "type": "string"
                 }
             }
         },
-        "yoga-guru_internal_models.CourseLevel": {
-            "type": "string",
-            "enum": [
-                "beginner",
-                "intermediate",
-                "advanced"
-            ],
-            "x-enum-varnames": [
-                "Beginner",
-                "Intermediate",
-                "Advanced"
-            ]
-        },
-        "yoga-guru_internal_models.CourseSession": {
+        "yoga-guru_internal_models.PayoutRule": {
             "type": "object",
             "properties": {
+                "amount": {
+                    "description": "Per session or attendee, in minor units of the studio currency",
+                    "type": "integer",
+                    "format": "int64"
+                },
                 "course": {
                     "$ref": "#/definitions/yoga-guru_internal_models.Course"
                 },
                 "courseID": {
+                    "description": "Limits the rule to a single course when set",
                     "type": "integer"
                 },
                 "createdAt": {
@@ -1257,147 +8466,142 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
-                "isCanceled": {
-                    "description": "Optional: You could add fields like ` + "`" + `Room` + "`" + `, ` + "`" + `InstructorID` + "`" + ` here if they vary.\nYou might also add a boolean for ` + "`" + `IsCancelled` + "`" + `.",
-                    "type": "boolean"
-                },
-                "scheduledAt": {
+                "instructorID": {
                     "type": "string"
                 },
+                "kind": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.PayoutRuleKind"
+                },
+                "share": {
+                    "description": "Fraction of revenue for revenue shares (e.g., 0.40 for 40%)",
+                    "type": "number",
+                    "format": "float64"
+                },
                 "updatedAt": {
                     "type": "string"
                 }
             }
         },
-        "yoga-guru_internal_models.DayOfWeekMask": {
-            "type": "integer",
+        "yoga-guru_internal_models.PayoutRuleKind": {
+            "type": "string",
             "enum": [
-                1,
-                2,
-                4,
-                8,
-                16,
-                32,
-                64
+                "per_session",
+                "per_attendee",
+                "revenue_share"
             ],
             "x-enum-comments": {
-                "Friday": "64 (1000000)",
-                "Monday": "4 (0000100)",
-                "Saturday": "1 (0000001)",
-                "Sunday": "2 (0000010)",
-                "Thursday": "32 (0100000)",
-                "Tuesday": "8 (0001000)",
-                "Wednesday": "16 (0010000)"
+                "PayoutPerAttendee": "Amount for every student attending a session",
+                "PayoutPerSession": "Amount for every session taught",
+                "PayoutRevenueShare": "Share of the net revenue of the instructor's courses"
             },
             "x-enum-descriptions": [
-                "1 (0000001)",
-                "2 (0000010)",
-                "4 (0000100)",
-                "8 (0001000)",
-                "16 (0010000)",
-                "32 (0100000)",
-                "64 (1000000)"
+                "Amount for every session taught",
+                "Amount for every student attending a session",
+                "Share of the net revenue of the instructor's courses"
             ],
             "x-enum-varnames": [
-                "Saturday",
-                "Sunday",
-                "Monday",
-                "Tuesday",
-                "Wednesday",
-                "Thursday",
-                "Friday"
+                "PayoutPerSession",
+                "PayoutPerAttendee",
+                "PayoutRevenueShare"
             ]
         },
-        "yoga-guru_internal_models.Enrollment": {
+        "yoga-guru_internal_models.PayoutStatement": {
             "type": "object",
             "properties": {
-                "attendances": {
-                    "description": "A user can have many attendance records under this enrollment.",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/yoga-guru_internal_models.Attendance"
-                    }
-                },
-                "course": {
-                    "$ref": "#/definitions/yoga-guru_internal_models.Course"
+                "amount": {
+                    "description": "What the instructor is paid, the sum of Lines",
+                    "type": "integer",
+                    "format": "int64"
                 },
-                "courseID": {
-                    "description": "This is the main course this enrollment is for",
+                "attendees": {
+                    "description": "Students who attended them",
                     "type": "integer"
                 },
                 "createdAt": {
                     "type": "string"
                 },
+                "currency": {
+                    "$ref": "#/definitions/yoga-guru_internal_money.Currency"
+                },
                 "deletedAt": {
                     "$ref": "#/definitions/gorm.DeletedAt"
                 },
-                "discountApplied": {
-                    "type": "number",
-                    "format": "float64"
-                },
-                "enrollmentType": {
-                    "$ref": "#/definitions/yoga-guru_internal_models.EnrollmentType"
-                },
-                "expirationDate": {
-                    "type": "string"
-                },
                 "id": {
                     "type": "integer"
                 },
-                "payments": {
+                "instructorID": {
+                    "type": "string"
+                },
+                "lines": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/yoga-guru_internal_models.Payment"
+                        "$ref": "#/definitions/yoga-guru_internal_models.PayoutLine"
                     }
                 },
-                "pricePaid": {
-                    "type": "number",
-                    "format": "float64"
-                },
-                "sessionsUsed": {
-                    "description": "Counter for fixed session packages",
-                    "type": "integer"
+                "month": {
+                    "description": "First day of the month",
+                    "type": "string"
                 },
-                "startDate": {
+                "paidAt": {
                     "type": "string"
                 },
-                "totalSessions": {
-                    "description": "Only for fixed session packages",
-                    "type": "integer"
+                "paidByID": {
+                    "description": "The admin who marked the statement paid",
+                    "type": "string"
                 },
-                "updatedAt": {
+                "reference": {
+                    "description": "e.g. the bank transfer the instructor was paid with",
                     "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/yoga-guru_internal_models.User"
+                "revenue": {
+                    "description": "Net revenue of the instructor's courses",
+                    "type": "integer",
+                    "format": "int64"
                 },
-                "userID": {
+                "sessions": {
+                    "description": "Sessions taught",
                     "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/yoga-guru_internal_models.PayoutStatus"
+                },
+                "updatedAt": {
+                    "type": "string"
                 }
             }
         },
-        "yoga-guru_internal_models.EnrollmentType": {
+        "yoga-guru_internal_models.PayoutStatus": {
             "type": "string",
             "enum": [
-                "pre_session",
-                "monthly",
-                "six_month",
-                "yearly"
+                "open",
+                "paid"
+            ],
+            "x-enum-comments": {
+                "PayoutOpen": "Recomputed until it is paid"
+            },
+            "x-enum-descriptions": [
+                "Recomputed until it is paid",
+                ""
             ],
             "x-enum-varnames": [
-                "PreSession",
-                "Monthly",
-                "SixMonth",
-                "Yearly"
+                "PayoutOpen",
+                "PayoutPaid"
             ]
         },
-        "yoga-guru_internal_models.Payment": {
+        "yoga-guru_internal_models.PricePlan": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "description": "The amount of this specific payment",
-                    "type": "number",
-                    "format": "float64"
+                "active": {
+                    "description": "Inactive plans can no longer be bought",
+                    "type": "boolean"
+                },
+                "courseID": {
+                    "description": "Set for a plan of a single course",
+                    "type": "integer"
+                },
+                "courseType": {
+                    "description": "Set for a plan of a course type, empty for all courses",
+                    "type": "string"
                 },
                 "createdAt": {
                     "type": "string"
@@ -1405,74 +8609,55 @@ const docTemplate = `{
                 "deletedAt": {
                     "$ref": "#/definitions/gorm.DeletedAt"
                 },
-                "enrollment": {
-                    "$ref": "#/definitions/yoga-guru_internal_models.Enrollment"
-                },
-                "enrollmentID": {
-                    "description": "Foreign key to the enrollment this payment is for",
-                    "type": "integer"
+                "discount": {
+                    "description": "Stored as a fraction (e.g., 0.10 for 10%)",
+                    "type": "number",
+                    "format": "float64"
                 },
-                "id": {
+                "durationMonths": {
+                    "description": "How long the enrollment is valid",
                     "type": "integer"
                 },
-                "method": {
-                    "description": "e.g., 'card', 'cash'",
+                "enrollmentType": {
+                    "description": "Kind of enrollment the plan sells",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/yoga-guru_internal_models.PaymentMethod"
+                            "$ref": "#/definitions/yoga-guru_internal_models.EnrollmentType"
                         }
                     ]
                 },
-                "paymentDate": {
+                "fixedPrice": {
+                    "description": "Overrides the price computed from sessions and discount",
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
                 },
-                "status": {
-                    "description": "e.g., 'succeeded', 'failed', 'pending'",
+                "packFilter": {
+                    "description": "PackFilter selects the courses a pack can be booked in. It is only\nused by plans of the pack enrollment type.",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/yoga-guru_internal_models.PaymentStatus"
+                            "$ref": "#/definitions/yoga-guru_internal_models.CourseFilter"
                         }
                     ]
                 },
-                "transactionID": {
-                    "description": "External ID from payment gateway (e.g., Stripe)",
-                    "type": "string"
+                "sessionLimit": {
+                    "description": "Sessions the enrollment allows, zero means unlimited",
+                    "type": "integer"
+                },
+                "sessions": {
+                    "description": "Sessions priced into the plan, at the course's per session price",
+                    "type": "integer"
                 },
                 "updatedAt": {
                     "type": "string"
                 }
             }
         },
-        "yoga-guru_internal_models.PaymentMethod": {
-            "type": "string",
-            "enum": [
-                "card",
-                "cash",
-                "bank_transfer",
-                "online_payment"
-            ],
-            "x-enum-varnames": [
-                "Card",
-                "Cash",
-                "BankTransfer",
-                "OnlinePayment"
-            ]
-        },
-        "yoga-guru_internal_models.PaymentStatus": {
-            "type": "string",
-            "enum": [
-                "pending",
-                "succeeded",
-                "failed",
-                "refunded"
-            ],
-            "x-enum-varnames": [
-                "PaymentPending",
-                "PaymentSucceeded",
-                "PaymentFailed",
-                "PaymentRefunded"
-            ]
-        },
         "yoga-guru_internal_models.Profile": {
             "type": "object",
             "properties": {
@@ -1538,6 +8723,7 @@ const docTemplate = `{
                     ]
                 },
                 "startTime": {
+                    "description": "Only the clock part of StartTime and EndTime is meaningful. They are kept\nas datetime columns since the SQLite driver cannot scan a \"time\" column.",
                     "type": "string"
                 },
                 "updatedAt": {
@@ -1606,12 +8792,48 @@ const docTemplate = `{
             "enum": [
                 "admin",
                 "instructor",
-                "student"
+                "student",
+                "staff"
+            ],
+            "x-enum-comments": {
+                "Staff": "Front desk staff"
+            },
+            "x-enum-descriptions": [
+                "",
+                "",
+                "",
+                "Front desk staff"
             ],
             "x-enum-varnames": [
                 "Admin",
                 "Instructor",
-                "Student"
+                "Student",
+                "Staff"
+            ]
+        },
+        "yoga-guru_internal_money.Currency": {
+            "type": "string",
+            "enum": [
+                "IRR",
+                "IRT",
+                "USD",
+                "EUR"
+            ],
+            "x-enum-comments": {
+                "IRR": "Iranian rial",
+                "IRT": "Toman, ten rials, how prices are commonly quoted in Iran"
+            },
+            "x-enum-descriptions": [
+                "Iranian rial",
+                "Toman, ten rials, how prices are commonly quoted in Iran",
+                "",
+                ""
+            ],
+            "x-enum-varnames": [
+                "IRR",
+                "IRT",
+                "USD",
+                "EUR"
             ]
         }
     },
@@ -2,16 +2,175 @@ package config
 
 import (
 	"log"
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+	"yoga-guru/internal/money"
 
 	"github.com/joho/godotenv"
 )
 
 // Config holds all application configurations
 type Config struct {
-	DBPath     string
-	Port       string
-	JWTSecret  string
+	DBPath    string
+	Port      string
+	JWTSecret string
+	// AccessTokenTTL is how long access tokens are valid. They can't be
+	// revoked, so logging out only takes effect once they expire.
+	AccessTokenTTL time.Duration
+	// RefreshTokenTTL is how long a login lasts without refreshing.
+	RefreshTokenTTL time.Duration
+	// SessionHorizon is how far ahead course sessions are materialized.
+	SessionHorizon time.Duration
+	// PublicURL is the externally reachable base URL of the API, used to
+	// build payment gateway callback URLs.
+	PublicURL string
+	// PaymentGateway selects the online payment gateway implementation.
+	PaymentGateway string
+	// Cancellation decides how much is refunded when an enrollment is
+	// cancelled.
+	Cancellation CancellationPolicy
+	// Freeze limits how students can pause long-term enrollments.
+	Freeze FreezePolicy
+	// RenewalReminderDays is how many days before an enrollment expires its
+	// student is reminded to renew.
+	RenewalReminderDays int
+	// Studio is printed on invoices and receipts.
+	Studio StudioDetails
+	// Tax is the VAT charged on enrollments.
+	Tax TaxPolicy
+	// Currency is the studio currency, which prices are set and enrollments
+	// are charged in.
+	Currency money.Currency
+	// DisplayCurrency is the currency amounts are shown in on invoices and
+	// quotes, the studio currency or one at a fixed rate to it, e.g. tomans
+	// for a studio charging in rials.
+	DisplayCurrency money.Currency
+	// SMSSender selects how text messages are sent, "console" or "file".
+	SMSSender string
+	// SMSFile is where the file SMS sender appends messages to.
+	SMSFile string
+	// OTP limits the one-time codes users log in with.
+	OTP OTPPolicy
+	// Login slows down and locks out repeated failed password logins.
+	Login LoginPolicy
+	// TrustedProxies are the addresses or CIDR ranges of the reverse proxies
+	// in front of the API, whose X-Forwarded-For headers are believed. With
+	// none, the client IP is the address of the connection.
+	TrustedProxies []string
+}
+
+// LoginPolicy limits failed password logins, counted both per phone number
+// and per IP address.
+type LoginPolicy struct {
+	// FreeAttempts is how many logins can fail before each further attempt
+	// has to wait, doubling the wait with every failure.
+	FreeAttempts int
+	// Backoff is the wait after the first failure beyond the free attempts.
+	Backoff time.Duration
+	// MaxFailures is how many failed logins lock out a phone number.
+	MaxFailures int
+	// MaxFailuresPerIP is how many failed logins lock out an IP address,
+	// higher than for a phone number as many users can share an address.
+	MaxFailuresPerIP int
+	// Lockout is how long a lockout lasts. No wait is longer, and failures
+	// older than that are forgotten.
+	Lockout time.Duration
+}
+
+// Wait returns how long to wait before the next login after the given
+// number of recent failures, with maxFailures locking out.
+func (p LoginPolicy) Wait(failures, maxFailures int) time.Duration {
+	if failures >= maxFailures {
+		return p.Lockout
+	}
+	doublings := failures - p.FreeAttempts - 1
+	if doublings < 0 {
+		return 0
+	}
+	if doublings >= 32 || p.Backoff<<doublings > p.Lockout {
+		return p.Lockout
+	}
+	return p.Backoff << doublings
+}
+
+// OTPPolicy configures one-time login codes.
+type OTPPolicy struct {
+	// TTL is how long a code can be used after it was sent.
+	TTL time.Duration
+	// MaxAttempts is how many wrong codes can be tried before the code is
+	// no longer accepted.
+	MaxAttempts int
+	// ResendInterval is how long to wait before another code is sent to
+	// the same phone number.
+	ResendInterval time.Duration
+	// MaxPerDay is how many codes are sent to the same phone number in a
+	// day, zero means no limit.
+	MaxPerDay int
+}
+
+// TaxPolicy configures the tax charged on enrollments.
+type TaxPolicy struct {
+	// Rate is the fraction of the net price charged as tax (e.g., 0.09 for
+	// 9% VAT).
+	Rate float64
+	// PricesIncludeTax means course and plan prices are gross, with the tax
+	// already included, rather than net with the tax added on top.
+	PricesIncludeTax bool
+}
+
+// Apply splits a quoted price into its net amount and tax, and returns the
+// gross amount charged.
+func (p TaxPolicy) Apply(price money.Amount) (net, tax, gross money.Amount) {
+	if p.PricesIncludeTax {
+		net = price.Mul(1 / (1 + p.Rate))
+		return net, price - net, price
+	}
+	tax = price.Mul(p.Rate)
+	return price, tax, price + tax
+}
+
+// StudioDetails identifies the studio on invoices and receipts.
+type StudioDetails struct {
+	Name    string
+	Address string
+	Phone   string
+	TaxID   string
+	// InvoicePrefix starts the numbers of the studio's invoices and receipts,
+	// each numbered in its own gap-free sequence.
+	InvoicePrefix string
+}
+
+// Pro-rating modes of a CancellationPolicy.
+const (
+	ProRateBySessions = "sessions" // By unused sessions, or by time for unlimited enrollments
+	ProRateByTime     = "time"     // By the remaining part of the enrollment period
+)
+
+// CancellationPolicy configures refunds for cancelled enrollments.
+type CancellationPolicy struct {
+	// FullRefundDays is how many days after the start date a cancellation is
+	// refunded in full.
+	FullRefundDays int
+	// NoRefundAfterDays is how many days after the start date cancellations
+	// are no longer refunded. Zero means there is no cutoff.
+	NoRefundAfterDays int
+	// ProRateBy is how the refund is pro-rated between the two.
+	ProRateBy string
+}
+
+// FreezePolicy configures freezes of six month and yearly enrollments.
+type FreezePolicy struct {
+	// MaxDaysPerYear is how many days an enrollment can be frozen in a
+	// calendar year.
+	MaxDaysPerYear int
+	// MinNoticeDays is how many days before its start a freeze must be
+	// requested.
+	MinNoticeDays int
+	// RequireApproval keeps freezes pending until an admin approves them.
+	RequireApproval bool
 }
 
 // LoadConfig reads configuration from environment variables or .env file
@@ -36,14 +195,232 @@ func LoadConfig() *Config {
 		log.Fatal("JWT_SECRET environment variable is not set. This is required for authentication.")
 	}
 
+	sessionHorizonDays := envInt("SESSION_HORIZON_DAYS", 28) // Default to four weeks of upcoming sessions
+
+	publicURL := os.Getenv("PUBLIC_URL")
+	if publicURL == "" {
+		publicURL = "http://localhost:" + port
+	}
+
+	// The fake gateway approves every payment and forgets them on restart,
+	// so it has to be chosen on purpose rather than by default
+	paymentGateway := os.Getenv("PAYMENT_GATEWAY")
+	if paymentGateway == "" {
+		log.Fatal("PAYMENT_GATEWAY environment variable is not set. Set it to fake only for development and testing.")
+	}
+
+	proRateBy := os.Getenv("CANCELLATION_PRORATE_BY")
+	switch proRateBy {
+	case "":
+		proRateBy = ProRateBySessions
+	case ProRateBySessions, ProRateByTime:
+	default:
+		log.Fatalf("CANCELLATION_PRORATE_BY must be %q or %q, got %q", ProRateBySessions, ProRateByTime, proRateBy)
+	}
+
+	studioName := os.Getenv("STUDIO_NAME")
+	if studioName == "" {
+		studioName = "Yoga Guru"
+	}
+
+	currency := envCurrency("CURRENCY", money.IRR)
+	displayCurrency := envCurrency("DISPLAY_CURRENCY", currency)
+	if _, err := money.Convert(0, currency, displayCurrency); err != nil {
+		log.Fatalf("DISPLAY_CURRENCY must have a fixed rate to CURRENCY: %v", err)
+	}
+
+	smsSender := os.Getenv("SMS_SENDER")
+	if smsSender == "" {
+		smsSender = "console" // Logs messages instead of sending them
+	}
+	smsFile := os.Getenv("SMS_FILE")
+	if smsFile == "" {
+		smsFile = "./sms.log"
+	}
+
+	invoicePrefix := os.Getenv("INVOICE_PREFIX")
+	if invoicePrefix == "" {
+		invoicePrefix = "YG"
+	}
+
 	return &Config{
-		DBPath:     dbPath,
-		Port:       port,
-		JWTSecret:  jwtSecret,
+		DBPath:          dbPath,
+		Port:            port,
+		JWTSecret:       jwtSecret,
+		AccessTokenTTL:  time.Duration(envInt("ACCESS_TOKEN_TTL_MINUTES", 15)) * time.Minute,
+		RefreshTokenTTL: time.Duration(envInt("REFRESH_TOKEN_TTL_DAYS", 7)) * 24 * time.Hour,
+		SessionHorizon:  time.Duration(sessionHorizonDays) * 24 * time.Hour,
+		PublicURL:       publicURL,
+		PaymentGateway:  paymentGateway,
+		Cancellation: CancellationPolicy{
+			FullRefundDays:    envInt("CANCELLATION_FULL_REFUND_DAYS", 7),
+			NoRefundAfterDays: envInt("CANCELLATION_NO_REFUND_AFTER_DAYS", 0),
+			ProRateBy:         proRateBy,
+		},
+		Freeze: FreezePolicy{
+			MaxDaysPerYear:  envInt("FREEZE_MAX_DAYS_PER_YEAR", 30),
+			MinNoticeDays:   envInt("FREEZE_MIN_NOTICE_DAYS", 1),
+			RequireApproval: envBool("FREEZE_REQUIRE_APPROVAL", false),
+		},
+		RenewalReminderDays: envInt("RENEWAL_REMINDER_DAYS", 7),
+		Studio: StudioDetails{
+			Name:          studioName,
+			Address:       os.Getenv("STUDIO_ADDRESS"),
+			Phone:         os.Getenv("STUDIO_PHONE"),
+			TaxID:         os.Getenv("STUDIO_TAX_ID"),
+			InvoicePrefix: invoicePrefix,
+		},
+		Tax: TaxPolicy{
+			Rate:             envFloat("TAX_RATE", 0),
+			PricesIncludeTax: envBool("PRICES_INCLUDE_TAX", false),
+		},
+		Currency:        currency,
+		DisplayCurrency: displayCurrency,
+		SMSSender:       smsSender,
+		SMSFile:         smsFile,
+		OTP: OTPPolicy{
+			TTL:            time.Duration(envInt("OTP_TTL_MINUTES", 5)) * time.Minute,
+			MaxAttempts:    envInt("OTP_MAX_ATTEMPTS", 5),
+			ResendInterval: time.Duration(envInt("OTP_RESEND_SECONDS", 60)) * time.Second,
+			MaxPerDay:      envInt("OTP_MAX_PER_DAY", 10),
+		},
+		Login: LoginPolicy{
+			FreeAttempts:     envInt("LOGIN_FREE_ATTEMPTS", 3),
+			Backoff:          time.Duration(envInt("LOGIN_BACKOFF_SECONDS", 1)) * time.Second,
+			MaxFailures:      envInt("LOGIN_MAX_FAILURES", 10),
+			MaxFailuresPerIP: envInt("LOGIN_MAX_FAILURES_PER_IP", 100),
+			Lockout:          time.Duration(envInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+		},
+		TrustedProxies: envProxies("TRUSTED_PROXIES"),
+	}
+}
+
+// PaymentCallbackURL is where the payment gateway sends payers back to.
+func (c *Config) PaymentCallbackURL() string {
+	return c.PublicURL + "/payments/callback"
+}
+
+// FormatMoney formats an amount in currency for display, converted to the
+// display currency when it has a fixed rate to it.
+func (c *Config) FormatMoney(amount money.Amount, currency money.Currency) string {
+	display := c.DisplayCurrency
+	if display == "" {
+		display = currency
+	}
+	converted, err := money.Convert(amount, currency, display)
+	if err != nil {
+		return money.Format(amount, currency)
+	}
+	return money.Format(converted, display)
+}
+
+// envInt reads a non-negative integer from the environment, falling back to
+// def when the variable is not set.
+func envInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		log.Fatalf("%s must be a non-negative integer, got %q", key, v)
+	}
+	return n
+}
+
+// envFloat reads a non-negative number from the environment, falling back to
+// def when the variable is not set.
+func envFloat(key string, def float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 {
+		log.Fatalf("%s must be a non-negative number, got %q", key, v)
+	}
+	return f
+}
+
+// envCurrency reads a supported currency code from the environment, falling
+// back to def when the variable is not set.
+func envCurrency(key string, def money.Currency) money.Currency {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	c := money.Currency(v)
+	if !c.Valid() {
+		log.Fatalf("%s must be one of IRR, IRT, USD or EUR, got %q", key, v)
+	}
+	return c
+}
+
+// envBool reads a boolean from the environment, falling back to def when the
+// variable is not set.
+func envBool(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Fatalf("%s must be a boolean, got %q", key, v)
+	}
+	return b
+}
+
+// envProxies reads a comma separated list of IP addresses and CIDR ranges
+// from the environment, empty when the variable is not set.
+func envProxies(key string) []string {
+	var proxies []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(v); err != nil && net.ParseIP(v) == nil {
+			log.Fatalf("%s must be IP addresses or CIDR ranges separated by commas, got %q", key, v)
+		}
+		proxies = append(proxies, v)
 	}
+	return proxies
 }
 
 // You can create a .env file in the root of your project like this:
 // DB_PATH=./yoga.db
 // PORT=8080
 // JWT_SECRET=your_super_secret_jwt_key
+// ACCESS_TOKEN_TTL_MINUTES=15
+// REFRESH_TOKEN_TTL_DAYS=7
+// SESSION_HORIZON_DAYS=28
+// PUBLIC_URL=http://localhost:8080
+// PAYMENT_GATEWAY=fake
+// CANCELLATION_FULL_REFUND_DAYS=7
+// CANCELLATION_NO_REFUND_AFTER_DAYS=0
+// CANCELLATION_PRORATE_BY=sessions
+// FREEZE_MAX_DAYS_PER_YEAR=30
+// FREEZE_MIN_NOTICE_DAYS=1
+// FREEZE_REQUIRE_APPROVAL=false
+// RENEWAL_REMINDER_DAYS=7
+// STUDIO_NAME=Yoga Guru
+// STUDIO_ADDRESS=
+// STUDIO_PHONE=
+// STUDIO_TAX_ID=
+// INVOICE_PREFIX=YG
+// TAX_RATE=0.09
+// PRICES_INCLUDE_TAX=false
+// CURRENCY=IRR
+// DISPLAY_CURRENCY=IRT
+// SMS_SENDER=console
+// SMS_FILE=./sms.log
+// OTP_TTL_MINUTES=5
+// OTP_MAX_ATTEMPTS=5
+// OTP_RESEND_SECONDS=60
+// OTP_MAX_PER_DAY=10
+// LOGIN_FREE_ATTEMPTS=3
+// LOGIN_BACKOFF_SECONDS=1
+// LOGIN_MAX_FAILURES=10
+// LOGIN_MAX_FAILURES_PER_IP=100
+// LOGIN_LOCKOUT_MINUTES=15
+// TRUSTED_PROXIES=127.0.0.1,10.0.0.0/8
@@ -1,14 +1,18 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 	"yoga-guru/internal/config"
 	"yoga-guru/internal/middleware"
 	"yoga-guru/internal/models"
+	"yoga-guru/internal/notify"
 	"yoga-guru/internal/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -16,11 +20,12 @@ import (
 type AuthHandler struct {
 	DB  *gorm.DB
 	Cfg *config.Config
+	SMS notify.SMSSender
 }
 
 // NewAuthHandler creates a new AuthHandler instance.
-func NewAuthHandler(db *gorm.DB, cfg *config.Config) *AuthHandler {
-	return &AuthHandler{DB: db, Cfg: cfg}
+func NewAuthHandler(db *gorm.DB, cfg *config.Config, sms notify.SMSSender) *AuthHandler {
+	return &AuthHandler{DB: db, Cfg: cfg, SMS: sms}
 }
 
 // RegisterRequest defines the request body for user registration.
@@ -114,6 +119,7 @@ type LoginRequest struct {
 // @Success 200 {object} map[string]string "token: JWT_TOKEN"
 // @Failure 400 {object} map[string]string "error: Bad request"
 // @Failure 401 {object} map[string]string "error: Invalid credentials"
+// @Failure 429 {object} map[string]interface{} "error: Too many failed login attempts, retryAfter in seconds"
 // @Failure 500 {object} map[string]string "error: Internal server error"
 // @Router /login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -123,18 +129,32 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	if h.loginBlocked(c, req.Phone, c.ClientIP()) {
+		return
+	}
+
 	var user models.User
-	if err := h.DB.Where("phone = ?", req.Phone).First(&user).Error; err != nil {
+	err := h.DB.Where("phone = ?", req.Phone).First(&user).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return
+	}
+	// Unknown numbers count as failures too, so guessing them is as slow
+	if err != nil || !utils.CheckPasswordHash(req.Password, user.PasswordHash) {
+		if err := h.recordLoginFailure(req.Phone, c.ClientIP()); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
-	if !utils.CheckPasswordHash(req.Password, user.PasswordHash) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+	if err := h.clearLoginFailures(req.Phone); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
 		return
 	}
 
-	token, refresh, err := middleware.GenerateJWT(user.ID.String(), user.Role, h.Cfg)
+	token, refresh, err := h.startSession(c, user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
@@ -148,9 +168,14 @@ type RefreshTokenRequest struct {
 	RefreshToken string `json:"refreshToken" binding:"required"`
 }
 
+var (
+	errRefreshInvalid = errors.New("invalid refresh token")
+	errRefreshReused  = errors.New("refresh token reused")
+)
+
 // RefreshToken godoc
 // @Summary Refresh an access token
-// @Description Refresh a JWT access token using a valid refresh token.
+// @Description Exchange a refresh token for a new access token and refresh token. Each refresh token can be used once, presenting a used one again logs out the login it belongs to.
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -168,23 +193,191 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	claims, err := middleware.ValidateToken(req.RefreshToken, h.Cfg)
+	stored, err := h.refreshToken(req.RefreshToken)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
 		return
 	}
 
+	now := time.Now()
 	var user models.User
-	if err := h.DB.First(&user, "id = ?", claims.Subject).Error; err != nil {
+	var token, newRefresh string
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&stored).Where("used_at IS NULL").Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errRefreshReused
+		}
+
+		expiresAt := now.Add(h.Cfg.RefreshTokenTTL)
+		result = tx.Model(&models.DeviceSession{}).
+			Where("id = ? AND revoked_at IS NULL", stored.SessionID).
+			Updates(map[string]interface{}{
+				"last_used_at": now,
+				"expires_at":   expiresAt,
+				"ip":           c.ClientIP(),
+				"user_agent":   c.Request.UserAgent(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errRefreshInvalid
+		}
+
+		if err := tx.First(&user, "id = ?", stored.UserID).Error; err != nil {
+			return err
+		}
+		var err error
+		token, newRefresh, err = h.issueTokens(tx, user, stored.SessionID, expiresAt)
+		return err
+	})
+	switch {
+	case errors.Is(err, errRefreshInvalid):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	case errors.Is(err, errRefreshReused):
+		// Either the client or someone who stole the token already used it,
+		// so neither can be trusted with the session any longer
+		if _, err := revokeSessions(h.DB.Where("id = ?", stored.SessionID)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token was already used, please log in again"})
+		return
+	case errors.Is(err, gorm.ErrRecordNotFound):
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate new token"})
+		return
 	}
 
-	token, newRefresh, err := middleware.GenerateJWT(user.ID.String(), user.Role, h.Cfg)
+	c.JSON(http.StatusOK, gin.H{"token": token, "refresh": newRefresh, "role": user.Role})
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description End the device session of a refresh token. Access tokens stay valid until they expire.
+// @Tags Auth
+// @Accept json
+// @Param refresh body RefreshTokenRequest true "Refresh token"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Invalid refresh token"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	stored, err := h.refreshToken(req.RefreshToken)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate new token"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token, "refresh": newRefresh, "role": user.Role})
+	if _, err := revokeSessions(h.DB.Where("id = ?", stored.SessionID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// LogoutAll godoc
+// @Summary Log out of all devices
+// @Description End every device session of the current user, logging out all their devices once their access tokens expire.
+// @Tags Auth
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /logout/all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userIDAny, _ := c.Get("userID")
+	userID := uuid.MustParse(userIDAny.(string))
+
+	if _, err := revokeSessions(h.DB.Where("user_id = ?", userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// startSession starts a device session for user logging in with the
+// request, returning its first access token and refresh token.
+func (h *AuthHandler) startSession(c *gin.Context, user models.User) (string, string, error) {
+	now := time.Now()
+	session := models.DeviceSession{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		UserAgent:  c.Request.UserAgent(),
+		IP:         c.ClientIP(),
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(h.Cfg.RefreshTokenTTL),
+	}
+
+	var token, refresh string
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+		var err error
+		token, refresh, err = h.issueTokens(tx, user, session.ID, session.ExpiresAt)
+		return err
+	})
+	return token, refresh, err
+}
+
+// issueTokens signs an access token for user and stores and signs a refresh
+// token for the device session, valid until expiresAt.
+func (h *AuthHandler) issueTokens(tx *gorm.DB, user models.User, sessionID uuid.UUID, expiresAt time.Time) (string, string, error) {
+	token, err := middleware.GenerateAccessToken(user.ID.String(), user.Role, sessionID.String(), h.Cfg)
+	if err != nil {
+		return "", "", err
+	}
+
+	stored := models.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		SessionID: sessionID,
+		ExpiresAt: expiresAt,
+	}
+	if err := tx.Create(&stored).Error; err != nil {
+		return "", "", err
+	}
+	refresh, err := middleware.GenerateRefreshToken(user.ID.String(), stored.ID.String(), stored.ExpiresAt, h.Cfg)
+	if err != nil {
+		return "", "", err
+	}
+	return token, refresh, nil
+}
+
+// refreshToken finds the stored token of a signed refresh token.
+func (h *AuthHandler) refreshToken(tokenString string) (models.RefreshToken, error) {
+	var stored models.RefreshToken
+	claims, err := middleware.ValidateRefreshToken(tokenString, h.Cfg)
+	if err != nil {
+		return stored, err
+	}
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return stored, err
+	}
+	err = h.DB.First(&stored, "id = ? AND user_id = ?", tokenID, claims.Subject).Error
+	return stored, err
+}
+
+// revokeSessions revokes the device sessions matched by scope, returning
+// how many were still active.
+func revokeSessions(scope *gorm.DB) (int64, error) {
+	result := scope.Model(&models.DeviceSession{}).Where("revoked_at IS NULL").Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
 }
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/middleware"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRefreshTokenRotation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	cfg := &config.Config{JWTSecret: "secret", AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour}
+	h := NewAuthHandler(db, cfg, nil)
+
+	hash, _ := utils.HashPassword("secret1")
+	user := models.User{Phone: "+989120000001", PasswordHash: hash, Role: models.Student}
+	db.Create(&user)
+
+	r := gin.New()
+	r.POST("/login", h.Login)
+	r.POST("/refresh", h.RefreshToken)
+	r.POST("/logout", h.Logout)
+	authorized := r.Group("/", middleware.AuthMiddleware(cfg))
+	authorized.GET("/users/me", func(c *gin.Context) { c.Status(http.StatusOK) })
+	authorized.POST("/logout/all", h.LogoutAll)
+	serve := func(method, path, bearer, body string, want int) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != want {
+			t.Fatalf("%s %s: got status %d, want %d: %s", method, path, rr.Code, want, rr.Body)
+		}
+		return rr
+	}
+	type tokens struct {
+		Token   string
+		Refresh string
+	}
+	decode := func(rr *httptest.ResponseRecorder) tokens {
+		t.Helper()
+		var got tokens
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	login := func() tokens {
+		t.Helper()
+		return decode(serve(http.MethodPost, "/login", "", `{"phone": "+989120000001", "password": "secret1"}`, http.StatusOK))
+	}
+	refresh := func(token string, want int) tokens {
+		t.Helper()
+		rr := serve(http.MethodPost, "/refresh", "", `{"refreshToken": "`+token+`"}`, want)
+		if want != http.StatusOK {
+			return tokens{}
+		}
+		return decode(rr)
+	}
+
+	// Each token type is only accepted where it belongs
+	first := login()
+	serve(http.MethodGet, "/users/me", first.Token, "", http.StatusOK)
+	serve(http.MethodGet, "/users/me", first.Refresh, "", http.StatusUnauthorized)
+	refresh(first.Token, http.StatusUnauthorized)
+
+	// Refreshing uses up the token, and reusing it revokes the whole login
+	second := refresh(first.Refresh, http.StatusOK)
+	serve(http.MethodGet, "/users/me", second.Token, "", http.StatusOK)
+	refresh(first.Refresh, http.StatusUnauthorized)
+	refresh(second.Refresh, http.StatusUnauthorized)
+
+	// Logging out revokes that login only
+	phone, laptop := login(), login()
+	serve(http.MethodPost, "/logout", "", `{"refreshToken": "`+phone.Refresh+`"}`, http.StatusNoContent)
+	refresh(phone.Refresh, http.StatusUnauthorized)
+	laptop = refresh(laptop.Refresh, http.StatusOK)
+
+	// Logging out of all devices revokes every login
+	tablet := login()
+	serve(http.MethodPost, "/logout/all", tablet.Token, "", http.StatusNoContent)
+	refresh(laptop.Refresh, http.StatusUnauthorized)
+	refresh(tablet.Refresh, http.StatusUnauthorized)
+}
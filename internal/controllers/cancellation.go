@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/money"
+	"yoga-guru/internal/payment"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// errEnrollmentCancelled is returned when an enrollment was cancelled already.
+var errEnrollmentCancelled = errors.New("enrollment already cancelled")
+
+// refundAmount computes how much of paid is refunded when the enrollment is
+// cancelled at now, according to the cancellation policy. The value of the
+// enrollment used up so far is kept and the rest of what was paid returned.
+func refundAmount(policy config.CancellationPolicy, enrollment *models.Enrollment, paid money.Amount, now time.Time) money.Amount {
+	if paid <= 0 {
+		return 0
+	}
+
+	elapsed := now.Sub(enrollment.StartDate)
+	if elapsed < time.Duration(policy.FullRefundDays)*24*time.Hour {
+		return paid
+	}
+	if policy.NoRefundAfterDays > 0 && elapsed >= time.Duration(policy.NoRefundAfterDays)*24*time.Hour {
+		return 0
+	}
+
+	used := usedFraction(policy, enrollment, now)
+	refund := paid - enrollment.PricePaid.Mul(used)
+	return min(max(refund, 0), paid)
+}
+
+// usedFraction returns the fraction of the enrollment used up at now, by
+// sessions or by time as the cancellation policy pro-rates.
+func usedFraction(policy config.CancellationPolicy, enrollment *models.Enrollment, now time.Time) float64 {
+	var used float64
+	if policy.ProRateBy == config.ProRateBySessions && enrollment.TotalSessions > 0 {
+		used = float64(enrollment.SessionsUsed) / float64(enrollment.TotalSessions)
+	} else if term := enrollment.ExpirationDate.Sub(enrollment.StartDate); term > 0 {
+		used = float64(now.Sub(enrollment.StartDate)) / float64(term)
+	} else {
+		used = 1
+	}
+	return min(max(used, 0), 1)
+}
+
+// cancelUpcomingBookings cancels the bookings and waitlist places of the
+// enrollment for sessions yet to start. It returns the waitlisted bookings
+// promoted into the freed places.
+func cancelUpcomingBookings(tx *gorm.DB, enrollmentID uint) ([]*models.Booking, error) {
+	var bookings []models.Booking
+	if err := tx.Joins("CourseSession").
+		Where("bookings.enrollment_id = ? AND bookings.status IN ? AND CourseSession.scheduled_at > ?", enrollmentID,
+			[]models.BookingStatus{models.BookingBooked, models.BookingWaitlisted}, time.Now()).
+		Find(&bookings).Error; err != nil {
+		return nil, err
+	}
+
+	var promoted []*models.Booking
+	for i := range bookings {
+		booking, err := cancelBooking(tx, &bookings[i])
+		if err != nil {
+			return nil, err
+		}
+		if booking != nil {
+			promoted = append(promoted, booking)
+		}
+	}
+	return promoted, nil
+}
+
+// refundEnrollment records refunds of amount against the succeeded payments
+// of the enrollment, newest first, one for each payment it pays back. The
+// refunds are paid back by method, or the way each payment was made when
+// method is empty. Refunds of online payments stay pending, to be settled
+// with the gateway once the transaction is committed.
+func refundEnrollment(tx *gorm.DB, enrollment *models.Enrollment, amount money.Amount, method models.PaymentMethod) ([]models.Payment, error) {
+	if amount <= 0 {
+		return nil, nil
+	}
+
+	var payments []models.Payment
+	if err := tx.Where("enrollment_id = ? AND status = ? AND amount > refunded", enrollment.ID, models.PaymentSucceeded).
+		Order("payment_date DESC, id DESC").Find(&payments).Error; err != nil {
+		return nil, err
+	}
+
+	var refunds []models.Payment
+	for _, p := range payments {
+		if amount <= 0 {
+			break
+		}
+		part := min(amount, p.Amount-p.Refunded)
+		via := method
+		if via == "" {
+			via = p.Method
+		}
+
+		refund, err := refundPayment(tx, enrollment, &p, part, via)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, *refund)
+		amount -= part
+	}
+	return refunds, nil
+}
+
+// refundPayment records a refund of amount of the succeeded payment p, paid
+// back by method, failing with errPaymentNotRefundable when less than that is
+// left unrefunded. Refunds as credit go into the wallet of the enrolled
+// student, refunds of online payments stay pending until settled with the
+// payment gateway.
+func refundPayment(tx *gorm.DB, enrollment *models.Enrollment, p *models.Payment, amount money.Amount, method models.PaymentMethod) (*models.Payment, error) {
+	// Guard against concurrent refunds paying back more than was paid
+	update := tx.Model(p).Where("status = ? AND amount - refunded >= ?", models.PaymentSucceeded, amount).
+		Update("refunded", gorm.Expr("refunded + ?", amount))
+	if update.Error != nil {
+		return nil, update.Error
+	}
+	if update.RowsAffected == 0 {
+		return nil, errPaymentNotRefundable
+	}
+
+	refund := models.Payment{
+		EnrollmentID:  enrollment.ID,
+		Amount:        amount,
+		Status:        models.PaymentRefunded,
+		Method:        method,
+		TransactionID: uuid.NewString(),
+		PaymentDate:   time.Now(),
+		RefundOfID:    &p.ID,
+	}
+	if method == models.OnlinePayment {
+		refund.Status = models.PaymentRefundPending
+	}
+	if err := tx.Create(&refund).Error; err != nil {
+		return nil, err
+	}
+	if method != models.Credit {
+		return &refund, nil
+	}
+	err := addCredit(tx, &models.CreditTransaction{
+		UserID:      enrollment.UserID,
+		Kind:        models.CreditRefund,
+		Amount:      amount,
+		Description: fmt.Sprintf("Refund of payment %d", p.ID),
+		PaymentID:   &refund.ID,
+	})
+	return &refund, err
+}
+
+// settleRefund refunds a pending refund with the payment gateway. When the
+// gateway turns it down, the refund is marked failed and the amount can be
+// refunded again.
+func settleRefund(ctx context.Context, db *gorm.DB, gateway payment.Gateway, refund *models.Payment) error {
+	var original models.Payment
+	if err := db.First(&original, *refund.RefundOfID).Error; err != nil {
+		return err
+	}
+
+	if err := gateway.Refund(ctx, original.TransactionID, refund.Amount); err != nil {
+		refund.Status = models.PaymentRefundFailed
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			update := tx.Model(refund).Where("status = ?", models.PaymentRefundPending).Update("status", refund.Status)
+			if update.Error != nil || update.RowsAffected == 0 {
+				return update.Error
+			}
+			return tx.Model(&original).Update("refunded", gorm.Expr("refunded - ?", refund.Amount)).Error
+		}); err != nil {
+			return err
+		}
+		return fmt.Errorf("failed to refund payment %d with the gateway: %w", original.ID, err)
+	}
+
+	refund.Status = models.PaymentRefunded
+	return db.Model(refund).Where("status = ?", models.PaymentRefundPending).Update("status", refund.Status).Error
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/money"
+	"yoga-guru/internal/notify"
+	"yoga-guru/internal/payment"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRefundAmount(t *testing.T) {
+	start := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	policy := config.CancellationPolicy{FullRefundDays: 7, NoRefundAfterDays: 90, ProRateBy: config.ProRateBySessions}
+	byTime := policy
+	byTime.ProRateBy = config.ProRateByTime
+
+	pack := models.Enrollment{StartDate: start, ExpirationDate: start.AddDate(0, 3, 0), PricePaid: 100, TotalSessions: 10, SessionsUsed: 4}
+	monthly := models.Enrollment{StartDate: start, ExpirationDate: start.AddDate(0, 0, 40), PricePaid: 100}
+
+	tests := []struct {
+		name       string
+		policy     config.CancellationPolicy
+		enrollment models.Enrollment
+		paid       money.Amount
+		days       int
+		want       money.Amount
+	}{
+		{"within the full refund period", policy, pack, 100, 3, 100},
+		{"pro-rated by unused sessions", policy, pack, 100, 20, 60},
+		{"pro-rated by remaining time", byTime, monthly, 100, 10, 75},
+		{"unlimited enrollments are pro-rated by time", policy, monthly, 100, 30, 25},
+		{"used value is kept from a partial payment", policy, pack, 50, 20, 10},
+		{"used value exceeds the partial payment", policy, pack, 30, 20, 0},
+		{"after the cutoff", policy, pack, 100, 90, 0},
+		{"nothing paid", policy, pack, 0, 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := refundAmount(tt.policy, &tt.enrollment, tt.paid, start.AddDate(0, 0, tt.days))
+			if got != tt.want {
+				t.Errorf("got refund %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCancelEnrollmentSettlesRefundsAfterCommit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	gateway := payment.NewFakeGateway()
+	cfg := &config.Config{Cancellation: config.CancellationPolicy{FullRefundDays: 7}}
+	h := NewEnrollmentHandler(db, cfg, notify.LogNotifier{}, gateway)
+
+	student := models.User{Phone: "+989120000520", Role: models.Student}
+	db.Create(&student)
+	course := models.Course{Title: "Vinyasa"}
+	db.Create(&course)
+	now := time.Now()
+	enrollment := models.Enrollment{UserID: student.ID, CourseID: course.ID, Status: models.EnrollmentActive,
+		StartDate: now, ExpirationDate: now.AddDate(0, 1, 0), PricePaid: 100}
+	db.Create(&enrollment)
+	checkout, err := gateway.CreatePayment(context.Background(), payment.Request{Amount: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The gateway knows the newer payment but turns down refunding the older
+	unknown := models.Payment{EnrollmentID: enrollment.ID, Amount: 40, Status: models.PaymentSucceeded,
+		Method: models.OnlinePayment, TransactionID: "fake-unknown", PaymentDate: now.Add(-time.Hour)}
+	known := models.Payment{EnrollmentID: enrollment.ID, Amount: 60, Status: models.PaymentSucceeded,
+		Method: models.OnlinePayment, TransactionID: checkout.TransactionID, PaymentDate: now}
+	db.Create(&unknown)
+	db.Create(&known)
+
+	r := gin.New()
+	r.Use(withUser(student.ID, models.Student))
+	r.DELETE("/enrollments/:id", h.CancelEnrollment)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/enrollments/%d", enrollment.ID), nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("cancelling: got status %d, want %d: %s", rr.Code, http.StatusOK, rr.Body)
+	}
+	var got CancellationResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cancellation stands, and the refund turned down is left to retry
+	if got.Enrollment.Status != models.EnrollmentCancelled || got.Refunded != 60 || len(got.Refunds) != 2 ||
+		got.Refunds[0].Status != models.PaymentRefunded || got.Refunds[1].Status != models.PaymentRefundFailed {
+		t.Fatalf("got cancellation %+v, want it cancelled with 60 refunded and 40 failed", got)
+	}
+	db.First(&unknown, unknown.ID)
+	db.First(&known, known.ID)
+	if unknown.Refunded != 0 || known.Refunded != 60 {
+		t.Errorf("got %v and %v refunded, want 0 and 60", unknown.Refunded, known.Refunded)
+	}
+}
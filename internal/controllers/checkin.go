@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+	"yoga-guru/internal/middleware"
+	"yoga-guru/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+)
+
+const (
+	// checkInTokenTTL is how long a check-in QR code stays valid. The front
+	// desk display is expected to refresh it.
+	checkInTokenTTL = 5 * time.Minute
+	// checkInOpensBefore is how early before the session check-in opens.
+	checkInOpensBefore = 30 * time.Minute
+)
+
+// checkInWindow returns the period during which students can check in.
+func checkInWindow(session *models.CourseSession) (time.Time, time.Time) {
+	closes := session.EndsAt
+	if closes.IsZero() {
+		closes = session.ScheduledAt
+	}
+	return session.ScheduledAt.Add(-checkInOpensBefore), closes
+}
+
+// GetCheckInQRCode godoc
+// @Summary Get the check-in QR code of a session (Instructor/Admin only)
+// @Description Render a short-lived signed check-in token for the session as a PNG QR code, to be shown at the front desk. Only available while check-in is open.
+// @Tags Sessions
+// @Security BearerAuth
+// @Produce png
+// @Param id path int true "Session ID"
+// @Success 200 {file} binary "QR code image"
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Session not found"
+// @Failure 409 {object} map[string]string "error: Check-in is not open"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /sessions/{id}/checkin.png [get]
+func (h *SessionHandler) GetCheckInQRCode(c *gin.Context) {
+	session, ok := h.managedSession(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	opens, closes := checkInWindow(session)
+	if session.IsCanceled || now.Before(opens) || now.After(closes) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Check-in is not open for this session"})
+		return
+	}
+
+	expiresAt := now.Add(checkInTokenTTL)
+	if expiresAt.After(closes) {
+		expiresAt = closes
+	}
+	token, err := middleware.GenerateCheckInToken(session.ID, expiresAt, h.Cfg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate check-in token"})
+		return
+	}
+
+	png, err := qrcode.Encode(token, qrcode.Medium, 512)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render QR code"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Expires", expiresAt.UTC().Format(http.TimeFormat))
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// CheckInRequest defines the request body for a self check-in.
+type CheckInRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// CheckIn godoc
+// @Summary Check in to a session (Student only)
+// @Description Check the current student in to a booked session using the token scanned from the front desk QR code.
+// @Tags Sessions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param checkin body CheckInRequest true "Scanned check-in token"
+// @Success 200 {object} models.Attendance
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized or invalid token"
+// @Failure 404 {object} map[string]string "error: Booking not found"
+// @Failure 409 {object} map[string]string "error: Check-in closed or already checked in"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /checkin [post]
+func (h *SessionHandler) CheckIn(c *gin.Context) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	studentID := uuid.MustParse(userIDAny.(string))
+
+	var req CheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID, err := middleware.ValidateCheckInToken(req.Token, h.Cfg)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired check-in token"})
+		return
+	}
+
+	var session models.CourseSession
+	if err := h.DB.First(&session, sessionID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch session"})
+		return
+	}
+
+	now := time.Now()
+	opens, closes := checkInWindow(&session)
+	if session.IsCanceled || now.Before(opens) || now.After(closes) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Check-in is not open for this session"})
+		return
+	}
+
+	var booking models.Booking
+	if err := h.DB.Where("course_session_id = ? AND user_id = ? AND status IN ?", session.ID, studentID,
+		[]models.BookingStatus{models.BookingBooked, models.BookingAttended, models.BookingNoShow}).
+		First(&booking).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "You have no booking for this session"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch booking"})
+		return
+	}
+	if booking.Status == models.BookingAttended {
+		c.JSON(http.StatusConflict, gin.H{"error": "You are already checked in"})
+		return
+	}
+
+	var attendance *models.Attendance
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		attendance, err = recordAttendance(tx, &booking, true)
+		return err
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check in"})
+		return
+	}
+
+	c.JSON(http.StatusOK, attendance)
+}
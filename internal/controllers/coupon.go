@@ -0,0 +1,333 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/money"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	errCouponInvalid       = errors.New("coupon is not valid")
+	errCouponNotApplicable = errors.New("coupon does not apply")
+	errCouponExhausted     = errors.New("coupon fully redeemed")
+	errCouponUserLimit     = errors.New("coupon redeemed too often by user")
+)
+
+// CouponHandler provides methods for coupon management.
+type CouponHandler struct {
+	DB *gorm.DB
+}
+
+// NewCouponHandler creates a new CouponHandler instance.
+func NewCouponHandler(db *gorm.DB) *CouponHandler {
+	return &CouponHandler{DB: db}
+}
+
+// CouponRequest defines the request body for creating or updating a coupon.
+type CouponRequest struct {
+	Code        string            `json:"code" binding:"required,max=64"`
+	Description string            `json:"description"`
+	Kind        models.CouponKind `json:"kind" binding:"required,oneof=percentage fixed"`
+	// Value is the fraction of the price taken off by percentage coupons
+	// (e.g., 0.20 for 20%), Amount what fixed coupons take off, in minor
+	// units of the studio currency.
+	Value          float64      `json:"value" binding:"min=0,max=1"`
+	Amount         money.Amount `json:"amount" binding:"min=0"`
+	ValidFrom      *time.Time   `json:"validFrom"`
+	ValidUntil     *time.Time   `json:"validUntil"`
+	MaxRedemptions int          `json:"maxRedemptions" binding:"min=0"`
+	PerUserLimit   int          `json:"perUserLimit" binding:"min=0"`
+	CourseID       *uint        `json:"courseId"`
+	PricePlanID    *uint        `json:"pricePlanId"`
+	Active         *bool        `json:"active"` // Defaults to true
+}
+
+// GetCoupons godoc
+// @Summary Get all coupons (Admin only)
+// @Description Retrieve all coupons with their redemption counts.
+// @Tags Coupons
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.Coupon
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /coupons [get]
+func (h *CouponHandler) GetCoupons(c *gin.Context) {
+	var coupons []models.Coupon
+	if err := h.DB.Order("id").Find(&coupons).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch coupons"})
+		return
+	}
+	c.JSON(http.StatusOK, coupons)
+}
+
+// CreateCoupon godoc
+// @Summary Create a coupon (Admin only)
+// @Description Create a promotional code taking a percentage or a fixed amount off the enrollment price, optionally limited in time, in number of redemptions, per student, and to a course or price plan.
+// @Tags Coupons
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param coupon body CouponRequest true "Coupon details"
+// @Success 201 {object} models.Coupon
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 409 {object} map[string]string "error: Code already in use"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /coupons [post]
+func (h *CouponHandler) CreateCoupon(c *gin.Context) {
+	var coupon models.Coupon
+	if !h.bindCoupon(c, &coupon) {
+		return
+	}
+
+	if err := h.DB.Create(&coupon).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create coupon"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, coupon)
+}
+
+// UpdateCoupon godoc
+// @Summary Update a coupon (Admin only)
+// @Description Update a coupon. Its redemptions so far are kept.
+// @Tags Coupons
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Coupon ID"
+// @Param coupon body CouponRequest true "Coupon details"
+// @Success 200 {object} models.Coupon
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Coupon not found"
+// @Failure 409 {object} map[string]string "error: Code already in use"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /coupons/{id} [put]
+func (h *CouponHandler) UpdateCoupon(c *gin.Context) {
+	coupon, ok := h.findCoupon(c)
+	if !ok {
+		return
+	}
+	if !h.bindCoupon(c, coupon) {
+		return
+	}
+
+	// Redemptions is left out so concurrent redemptions are not lost
+	if err := h.DB.Model(coupon).Select("*").Omit("id", "created_at", "deleted_at", "redemptions").
+		Updates(coupon).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update coupon"})
+		return
+	}
+
+	c.JSON(http.StatusOK, coupon)
+}
+
+// DeleteCoupon godoc
+// @Summary Delete a coupon (Admin only)
+// @Description Delete a coupon so it can no longer be redeemed. Its redemptions are kept.
+// @Tags Coupons
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Coupon ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Coupon not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /coupons/{id} [delete]
+func (h *CouponHandler) DeleteCoupon(c *gin.Context) {
+	coupon, ok := h.findCoupon(c)
+	if !ok {
+		return
+	}
+
+	if err := h.DB.Delete(coupon).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete coupon"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetCouponRedemptions godoc
+// @Summary Get the redemptions of a coupon (Admin only)
+// @Description Retrieve who redeemed a coupon, for which enrollment and how much it took off.
+// @Tags Coupons
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Coupon ID"
+// @Success 200 {array} models.CouponRedemption
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Coupon not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /coupons/{id}/redemptions [get]
+func (h *CouponHandler) GetCouponRedemptions(c *gin.Context) {
+	coupon, ok := h.findCoupon(c)
+	if !ok {
+		return
+	}
+
+	var redemptions []models.CouponRedemption
+	if err := h.DB.Where("coupon_id = ?", coupon.ID).Order("created_at").Find(&redemptions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch coupon redemptions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, redemptions)
+}
+
+// findCoupon fetches the coupon from the path, writing an error response
+// when it cannot be found.
+func (h *CouponHandler) findCoupon(c *gin.Context) (*models.Coupon, bool) {
+	couponID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid coupon ID"})
+		return nil, false
+	}
+
+	var coupon models.Coupon
+	if err := h.DB.First(&coupon, uint(couponID)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Coupon not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch coupon"})
+		return nil, false
+	}
+	return &coupon, true
+}
+
+// bindCoupon validates the request body and copies it into coupon, writing
+// an error response when it is invalid.
+func (h *CouponHandler) bindCoupon(c *gin.Context, coupon *models.Coupon) bool {
+	var req CouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	if req.Kind == models.CouponPercentage && req.Value == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A percentage coupon takes a value between 0 and 1"})
+		return false
+	}
+	if req.Kind == models.CouponFixed && req.Amount == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A fixed coupon takes an amount greater than 0"})
+		return false
+	}
+	if req.ValidFrom != nil && req.ValidUntil != nil && !req.ValidUntil.After(*req.ValidFrom) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The validity window must end after it starts"})
+		return false
+	}
+	if req.CourseID != nil {
+		if err := h.DB.First(&models.Course{}, *req.CourseID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Course not found"})
+			return false
+		}
+	}
+	if req.PricePlanID != nil {
+		if err := h.DB.First(&models.PricePlan{}, *req.PricePlanID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Price plan not found"})
+			return false
+		}
+	}
+
+	// Codes of deleted coupons stay taken, so old codes are never revived
+	code := normalizeCouponCode(req.Code)
+	if h.DB.Unscoped().Where("code = ? AND id <> ?", code, coupon.ID).First(&models.Coupon{}).Error == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "This coupon code is already in use"})
+		return false
+	}
+
+	coupon.Code = code
+	coupon.Description = req.Description
+	coupon.Kind = req.Kind
+	coupon.Value, coupon.Amount = 0, 0
+	if req.Kind == models.CouponPercentage {
+		coupon.Value = req.Value
+	} else {
+		coupon.Amount = req.Amount
+	}
+	coupon.ValidFrom = req.ValidFrom
+	coupon.ValidUntil = req.ValidUntil
+	coupon.MaxRedemptions = req.MaxRedemptions
+	coupon.PerUserLimit = req.PerUserLimit
+	coupon.CourseID = req.CourseID
+	coupon.PricePlanID = req.PricePlanID
+	coupon.Active = req.Active == nil || *req.Active
+	return true
+}
+
+// normalizeCouponCode makes coupon codes case insensitive.
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// redeemCoupon checks that the coupon code can be used by the user for the
+// enrollment about to be created and counts the redemption. It returns the
+// redemption with the amount taken off price, for the caller to save once the
+// enrollment exists. Call it inside the transaction creating the enrollment.
+func redeemCoupon(tx *gorm.DB, code string, userID uuid.UUID, enrollment *models.Enrollment, price money.Amount, now time.Time) (*models.CouponRedemption, error) {
+	var coupon models.Coupon
+	if err := tx.Where("code = ?", normalizeCouponCode(code)).First(&coupon).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errCouponInvalid
+		}
+		return nil, err
+	}
+
+	if !coupon.Active ||
+		(coupon.ValidFrom != nil && now.Before(*coupon.ValidFrom)) ||
+		(coupon.ValidUntil != nil && !now.Before(*coupon.ValidUntil)) {
+		return nil, errCouponInvalid
+	}
+	if (coupon.CourseID != nil && *coupon.CourseID != enrollment.CourseID) ||
+		(coupon.PricePlanID != nil && (enrollment.PricePlanID == nil || *coupon.PricePlanID != *enrollment.PricePlanID)) {
+		return nil, errCouponNotApplicable
+	}
+
+	// Count the redemption first, the guarded update keeps concurrent
+	// enrollments from going over the limit
+	update := tx.Model(&models.Coupon{}).
+		Where("id = ? AND (max_redemptions = 0 OR redemptions < max_redemptions)", coupon.ID).
+		Update("redemptions", gorm.Expr("redemptions + 1"))
+	if update.Error != nil {
+		return nil, update.Error
+	}
+	if update.RowsAffected == 0 {
+		return nil, errCouponExhausted
+	}
+
+	if coupon.PerUserLimit > 0 {
+		var used int64
+		if err := tx.Model(&models.CouponRedemption{}).
+			Where("coupon_id = ? AND user_id = ?", coupon.ID, userID).
+			Count(&used).Error; err != nil {
+			return nil, err
+		}
+		if used >= int64(coupon.PerUserLimit) {
+			return nil, errCouponUserLimit
+		}
+	}
+
+	return &models.CouponRedemption{
+		CouponID: coupon.ID,
+		UserID:   userID,
+		Amount:   coupon.Discount(price),
+	}, nil
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/notify"
+	"yoga-guru/internal/payment"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEnrollWithCouponTracksRedemptions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	h := NewEnrollmentHandler(db, &config.Config{}, notify.LogNotifier{}, payment.NewFakeGateway())
+
+	course := models.Course{Title: "Yin", Price: 100}
+	db.Create(&course)
+	plans := models.DefaultPricePlans()
+	db.Create(&plans)
+	db.Create(&models.Coupon{Code: "NOWRUZ", Kind: models.CouponPercentage, Value: 0.20, MaxRedemptions: 1, Active: true})
+
+	enroll := func(phone string) *httptest.ResponseRecorder {
+		user := models.User{Phone: phone, Role: models.Student}
+		db.Create(&user)
+		r := gin.New()
+		r.Use(withUser(user.ID, models.Student))
+		r.POST("/enrollments", h.EnrollInCourse)
+		rr := httptest.NewRecorder()
+		body := fmt.Sprintf(`{"CourseID":%d,"EnrollmentType":"monthly","CouponCode":"nowruz"}`, course.ID)
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/enrollments", strings.NewReader(body)))
+		return rr
+	}
+
+	rr := enroll("+989120000400")
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("got status %d: %s", rr.Code, rr.Body)
+	}
+	var enrollment models.Enrollment
+	if err := json.Unmarshal(rr.Body.Bytes(), &enrollment); err != nil {
+		t.Fatal(err)
+	}
+	// Four sessions at 100 less 10% for the plan, less 20% for the coupon
+	if enrollment.PricePaid != 288 || math.Abs(enrollment.DiscountApplied-0.28) > 1e-9 {
+		t.Errorf("got price %v with discount %v, want 288 with 0.28", enrollment.PricePaid, enrollment.DiscountApplied)
+	}
+
+	if rr := enroll("+989120000401"); rr.Code != http.StatusConflict {
+		t.Errorf("redeeming a used up coupon: got status %d, want %d", rr.Code, http.StatusConflict)
+	}
+
+	var redemptions []models.CouponRedemption
+	db.Find(&redemptions)
+	if len(redemptions) != 1 || redemptions[0].EnrollmentID != enrollment.ID || redemptions[0].Amount != 72 {
+		t.Errorf("got redemptions %+v, want one of 72 for enrollment %d", redemptions, enrollment.ID)
+	}
+	var enrollments int64
+	db.Model(&models.Enrollment{}).Count(&enrollments)
+	if enrollments != 1 {
+		t.Errorf("got %d enrollments, want 1", enrollments)
+	}
+}
+
+func TestEnrollOnlineWithFullCouponActivates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	h := NewEnrollmentHandler(db, &config.Config{PublicURL: "http://studio.test"}, notify.LogNotifier{}, payment.NewFakeGateway())
+
+	student := models.User{Phone: "+989120000410", Role: models.Student}
+	db.Create(&student)
+	course := models.Course{Title: "Yin", Price: 100}
+	db.Create(&course)
+	plans := models.DefaultPricePlans()
+	db.Create(&plans)
+	db.Create(&models.Coupon{Code: "FREE", Kind: models.CouponPercentage, Value: 1, Active: true})
+
+	r := gin.New()
+	r.Use(withUser(student.ID, models.Student))
+	r.POST("/enrollments", h.EnrollInCourse)
+	rr := httptest.NewRecorder()
+	body := fmt.Sprintf(`{"CourseID":%d,"EnrollmentType":"monthly","CouponCode":"free","PayOnline":true}`, course.ID)
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/enrollments", strings.NewReader(body)))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("got status %d: %s", rr.Code, rr.Body)
+	}
+	var enrolled EnrollResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &enrolled); err != nil {
+		t.Fatal(err)
+	}
+	// Nothing is left to pay, so the gateway is skipped
+	if enrolled.Status != models.EnrollmentActive || enrolled.PricePaid != 0 || enrolled.PaymentURL != "" {
+		t.Errorf("got status %q, price %v and payment URL %q, want an active free enrollment without a payment URL",
+			enrolled.Status, enrolled.PricePaid, enrolled.PaymentURL)
+	}
+	var payments int64
+	db.Model(&models.Payment{}).Count(&payments)
+	if payments != 0 {
+		t.Errorf("got %d payments, want none", payments)
+	}
+}
@@ -1,24 +1,46 @@
 package controllers
 
 import (
+	"log"
 	"net/http"
 	"strconv"
 	"time"
+	"yoga-guru/internal/config"
 	"yoga-guru/internal/models"
+	"yoga-guru/internal/money"
+	"yoga-guru/internal/notify"
+	"yoga-guru/internal/scheduler"
 	"yoga-guru/internal/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 // CourseHandler provides methods for course management.
 type CourseHandler struct {
-	DB *gorm.DB
+	DB       *gorm.DB
+	Cfg      *config.Config
+	Notifier notify.Notifier
 }
 
 // NewCourseHandler creates a new CourseHandler instance.
-func NewCourseHandler(db *gorm.DB) *CourseHandler {
-	return &CourseHandler{DB: db}
+func NewCourseHandler(db *gorm.DB, cfg *config.Config, notifier notify.Notifier) *CourseHandler {
+	return &CourseHandler{DB: db, Cfg: cfg, Notifier: notifier}
+}
+
+// generateSessions materializes the upcoming sessions of a course. Failures
+// are logged only, the background scheduler will catch up on its next run.
+func (h *CourseHandler) generateSessions(courseID uint) {
+	from := time.Now()
+	if err := scheduler.GenerateSessions(h.DB, h.Notifier, courseID, from, from.Add(h.Cfg.SessionHorizon)); err != nil {
+		log.Printf("failed to generate sessions for course %d: %v", courseID, err)
+	}
+}
+
+// canManageCourse reports whether the user is the course's instructor or an admin.
+func canManageCourse(course *models.Course, userID uuid.UUID, role models.UserRole) bool {
+	return course.InstructorID == userID || role == models.Admin
 }
 
 // CreateCourseRequest defines the request body for creating a course.
@@ -27,7 +49,7 @@ type CreateCourseRequest struct {
 	CourseType string
 	Schedules  []CourseSchedule
 	Level      models.CourseLevel
-	Price      float64
+	Price      money.Amount // Per session, in minor units of the studio currency
 	Capacity   int
 }
 
@@ -58,7 +80,7 @@ func (h *CourseHandler) CreateCourse(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
 		return
 	}
-	instructorID := userIDAny.(uint)
+	instructorID := uuid.MustParse(userIDAny.(string))
 
 	var req CreateCourseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -109,6 +131,7 @@ func (h *CourseHandler) CreateCourse(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create course"})
 		return
 	}
+	h.generateSessions(course.ID)
 
 	c.JSON(http.StatusCreated, course)
 }
@@ -166,7 +189,7 @@ type UpdateCourseRequest struct {
 	CourseType *string
 	Schedules  []CourseSchedule
 	Level      *models.CourseLevel
-	Price      *float64
+	Price      *money.Amount
 	Capacity   *int
 }
 
@@ -192,7 +215,7 @@ func (h *CourseHandler) UpdateCourse(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
 		return
 	}
-	currentUserID := userIDAny.(uint)
+	currentUserID := uuid.MustParse(userIDAny.(string))
 	userRoleAny := c.MustGet("userRole")
 	currentUserRole := userRoleAny.(models.UserRole)
 
@@ -213,7 +236,7 @@ func (h *CourseHandler) UpdateCourse(c *gin.Context) {
 	}
 
 	// Check if the current user is the instructor of the course or an admin
-	if existingCourse.InstructorID != currentUserID && currentUserRole != models.Admin {
+	if !canManageCourse(&existingCourse, currentUserID, currentUserRole) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to update this course"})
 		return
 	}
@@ -230,8 +253,9 @@ func (h *CourseHandler) UpdateCourse(c *gin.Context) {
 	if req.CourseType != nil {
 		existingCourse.CourseType = *req.CourseType
 	}
-	schedules := make([]models.Schedule, len(req.Schedules))
+	var schedules []models.Schedule
 	if req.Schedules != nil {
+		schedules = make([]models.Schedule, len(req.Schedules))
 		for i, val := range req.Schedules {
 			if val.DayOfWeekMask < 0 && val.DayOfWeekMask > 128 {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid day of week"})
@@ -249,6 +273,7 @@ func (h *CourseHandler) UpdateCourse(c *gin.Context) {
 				StartTime:  time.Time(val.StartTime),
 				EndTime:    time.Time(val.EndTime),
 				DaysMask:   val.DayOfWeekMask,
+				CourseID:   existingCourse.ID,
 			}
 		}
 	}
@@ -277,17 +302,37 @@ func (h *CourseHandler) UpdateCourse(c *gin.Context) {
 		existingCourse.Capacity = *req.Capacity
 	}
 
-	if err := h.DB.Save(&existingCourse).Error; err != nil {
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&existingCourse).Error; err != nil {
+			return err
+		}
+		if schedules == nil {
+			return nil
+		}
+		// Replace the schedules, upcoming sessions are regenerated from them
+		if err := tx.Where("course_id = ?", existingCourse.ID).Delete(&models.Schedule{}).Error; err != nil {
+			return err
+		}
+		if len(schedules) == 0 {
+			return nil
+		}
+		return tx.Create(&schedules).Error
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update course"})
 		return
 	}
+	if schedules != nil {
+		existingCourse.Schedules = schedules
+		h.generateSessions(existingCourse.ID)
+	}
 
 	c.JSON(http.StatusOK, existingCourse)
 }
 
 // DeleteCourse godoc
 // @Summary Delete a course (Instructor/Admin only)
-// @Description Delete an existing yoga course. Only the course instructor or an admin can delete a course.
+// @Description Delete an existing yoga course. Only the course instructor or an admin can delete a course. Bookings and waitlist places on its upcoming sessions are cancelled and their students notified.
 // @Tags Courses
 // @Security BearerAuth
 // @Produce json
@@ -304,7 +349,7 @@ func (h *CourseHandler) DeleteCourse(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
 		return
 	}
-	currentUserID := userIDAny.(uint)
+	currentUserID := uuid.MustParse(userIDAny.(string))
 	userRoleAny := c.MustGet("userRole")
 	currentUserRole := userRoleAny.(models.UserRole)
 
@@ -325,21 +370,39 @@ func (h *CourseHandler) DeleteCourse(c *gin.Context) {
 	}
 
 	// Check if the current user is the instructor of the course or an admin
-	if existingCourse.InstructorID != currentUserID && currentUserRole != models.Admin {
+	if !canManageCourse(&existingCourse, currentUserID, currentUserRole) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to delete this course"})
 		return
 	}
 
-	// Delete associated enrollments first to maintain referential integrity if not set up with CASCADE DELETE
-	if err := h.DB.Where("course_id = ?", courseID).Delete(&models.Enrollment{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete associated enrollments"})
-		return
-	}
+	var bookings []models.Booking
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		// Upcoming sessions of a deleted course will never take place
+		var upcoming []uint
+		if err := tx.Model(&models.CourseSession{}).Where("course_id = ? AND scheduled_at > ? AND is_canceled = ?", courseID, time.Now(), false).
+			Pluck("id", &upcoming).Error; err != nil {
+			return err
+		}
+		var err error
+		bookings, err = scheduler.CancelSessions(tx, upcoming)
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("course_id = ? AND scheduled_at > ?", courseID, time.Now()).Delete(&models.CourseSession{}).Error; err != nil {
+			return err
+		}
 
-	if err := h.DB.Delete(&existingCourse).Error; err != nil {
+		// Delete associated enrollments first to maintain referential integrity if not set up with CASCADE DELETE
+		if err := tx.Where("course_id = ?", courseID).Delete(&models.Enrollment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&existingCourse).Error
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete course"})
 		return
 	}
+	scheduler.NotifyCancelledBookings(h.Notifier, bookings)
 
 	c.Status(http.StatusNoContent)
 }
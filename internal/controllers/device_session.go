@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+	"yoga-guru/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// DeviceSessionResponse describes a device a user is logged in on.
+type DeviceSessionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"userAgent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	Current    bool      `json:"current"` // The session of the request's access token
+}
+
+// GetMySessions godoc
+// @Summary List my device sessions
+// @Description List the devices the current user is logged in on, most recently used first.
+// @Tags Users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} DeviceSessionResponse
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /users/me/sessions [get]
+func (h *AuthHandler) GetMySessions(c *gin.Context) {
+	userIDAny, _ := c.Get("userID")
+	h.respondSessions(c, uuid.MustParse(userIDAny.(string)))
+}
+
+// RevokeMySession godoc
+// @Summary Log out a device
+// @Description End one of the current user's device sessions. Its access token stays valid until it expires.
+// @Tags Users
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 404 {object} map[string]string "error: Session not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /users/me/sessions/{id} [delete]
+func (h *AuthHandler) RevokeMySession(c *gin.Context) {
+	userIDAny, _ := c.Get("userID")
+	h.revokeSession(c, uuid.MustParse(userIDAny.(string)), c.Param("id"))
+}
+
+// GetUserSessions godoc
+// @Summary List a user's device sessions (Admin only)
+// @Description List the devices a user is logged in on, most recently used first.
+// @Tags Users
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {array} DeviceSessionResponse
+// @Failure 400 {object} map[string]string "error: Invalid user ID"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /users/{id}/sessions [get]
+func (h *AuthHandler) GetUserSessions(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	h.respondSessions(c, userID)
+}
+
+// RevokeUserSession godoc
+// @Summary Log out a user's device (Admin only)
+// @Description End one of a user's device sessions. Its access token stays valid until it expires.
+// @Tags Users
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param sessionID path string true "Session ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "error: Invalid user ID"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Session not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /users/{id}/sessions/{sessionID} [delete]
+func (h *AuthHandler) RevokeUserSession(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	h.revokeSession(c, userID, c.Param("sessionID"))
+}
+
+// respondSessions responds with the active device sessions of a user.
+func (h *AuthHandler) respondSessions(c *gin.Context, userID uuid.UUID) {
+	var sessions []models.DeviceSession
+	if err := h.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	current := c.GetString("sessionID")
+	resp := make([]DeviceSessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		resp = append(resp, DeviceSessionResponse{
+			ID:         s.ID,
+			UserAgent:  s.UserAgent,
+			IP:         s.IP,
+			CreatedAt:  s.CreatedAt,
+			LastUsedAt: s.LastUsedAt,
+			ExpiresAt:  s.ExpiresAt,
+			Current:    s.ID.String() == current,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// revokeSession revokes one of the device sessions of a user.
+func (h *AuthHandler) revokeSession(c *gin.Context, userID uuid.UUID, sessionID string) {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	revoked, err := revokeSessions(h.DB.Where("id = ? AND user_id = ?", id, userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+	if revoked == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/middleware"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDeviceSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	cfg := &config.Config{JWTSecret: "secret", AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour}
+	h := NewAuthHandler(db, cfg, nil)
+
+	hash, _ := utils.HashPassword("secret1")
+	student := models.User{Phone: "+989120000001", PasswordHash: hash, Role: models.Student}
+	admin := models.User{Phone: "+989120000002", PasswordHash: hash, Role: models.Admin}
+	db.Create(&student)
+	db.Create(&admin)
+
+	r := gin.New()
+	r.POST("/login", h.Login)
+	r.POST("/refresh", h.RefreshToken)
+	authorized := r.Group("/", middleware.AuthMiddleware(cfg))
+	authorized.GET("/users/me/sessions", h.GetMySessions)
+	authorized.DELETE("/users/me/sessions/:id", h.RevokeMySession)
+	authorized.GET("/users/:id/sessions", middleware.AuthorizeRole(models.Admin), h.GetUserSessions)
+	authorized.DELETE("/users/:id/sessions/:sessionID", middleware.AuthorizeRole(models.Admin), h.RevokeUserSession)
+	serve := func(method, path, bearer, userAgent, body string, want int) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("User-Agent", userAgent)
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != want {
+			t.Fatalf("%s %s: got status %d, want %d: %s", method, path, rr.Code, want, rr.Body)
+		}
+		return rr
+	}
+	type tokens struct {
+		Token   string
+		Refresh string
+	}
+	login := func(user models.User, userAgent string) tokens {
+		t.Helper()
+		rr := serve(http.MethodPost, "/login", "", userAgent, fmt.Sprintf(`{"phone": %q, "password": "secret1"}`, user.Phone), http.StatusOK)
+		var got tokens
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	sessions := func(path, bearer string) []DeviceSessionResponse {
+		t.Helper()
+		rr := serve(http.MethodGet, path, bearer, "", "", http.StatusOK)
+		var got []DeviceSessionResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	phone := login(student, "Phone")
+	tablet := login(student, "Front desk tablet")
+	adminLogin := login(admin, "Laptop")
+
+	// Refreshing keeps the session and records its use
+	serve(http.MethodPost, "/refresh", "", "Phone v2", `{"refreshToken": "`+phone.Refresh+`"}`, http.StatusOK)
+	got := sessions("/users/me/sessions", tablet.Token)
+	if len(got) != 2 || got[0].UserAgent != "Phone v2" || got[0].Current || got[1].UserAgent != "Front desk tablet" || !got[1].Current {
+		t.Fatalf("got sessions %+v, want the refreshed phone and the current tablet", got)
+	}
+	phoneSession, tabletSession := got[0].ID, got[1].ID
+
+	// Users can only revoke their own sessions
+	serve(http.MethodDelete, fmt.Sprintf("/users/me/sessions/%s", phoneSession), adminLogin.Token, "", "", http.StatusNotFound)
+	serve(http.MethodDelete, fmt.Sprintf("/users/me/sessions/%s", phoneSession), tablet.Token, "", "", http.StatusNoContent)
+	serve(http.MethodDelete, fmt.Sprintf("/users/me/sessions/%s", phoneSession), tablet.Token, "", "", http.StatusNotFound)
+
+	// Admins can see and revoke anyone's sessions
+	got = sessions(fmt.Sprintf("/users/%s/sessions", student.ID), adminLogin.Token)
+	if len(got) != 1 || got[0].ID != tabletSession {
+		t.Fatalf("got sessions %+v, want only the tablet", got)
+	}
+	serve(http.MethodGet, fmt.Sprintf("/users/%s/sessions", admin.ID), tablet.Token, "", "", http.StatusForbidden)
+	serve(http.MethodDelete, fmt.Sprintf("/users/%s/sessions/%s", student.ID, tabletSession), adminLogin.Token, "", "", http.StatusNoContent)
+	serve(http.MethodPost, "/refresh", "", "", `{"refreshToken": "`+tablet.Refresh+`"}`, http.StatusUnauthorized)
+	if got := sessions("/users/me/sessions", tablet.Token); len(got) != 0 {
+		t.Errorf("got sessions %+v, want none", got)
+	}
+}
@@ -1,79 +1,73 @@
 package controllers
 
 import (
-	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
 	"time"
+	"yoga-guru/internal/config"
 	"yoga-guru/internal/models"
+	"yoga-guru/internal/money"
+	"yoga-guru/internal/notify"
+	"yoga-guru/internal/payment"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 // EnrollmentHandler provides methods for enrollment management.
 type EnrollmentHandler struct {
-	DB *gorm.DB
+	DB       *gorm.DB
+	Cfg      *config.Config
+	Notifier notify.Notifier
+	Gateway  payment.Gateway
 }
 
 // NewEnrollmentHandler creates a new EnrollmentHandler instance.
-func NewEnrollmentHandler(db *gorm.DB) *EnrollmentHandler {
-	return &EnrollmentHandler{DB: db}
+func NewEnrollmentHandler(db *gorm.DB, cfg *config.Config, notifier notify.Notifier, gateway payment.Gateway) *EnrollmentHandler {
+	return &EnrollmentHandler{DB: db, Cfg: cfg, Notifier: notifier, Gateway: gateway}
 }
 
 // EnrollRequest defines the request body for course enrollment.
 type EnrollRequest struct {
-	CourseID       uint
+	CourseID uint
+	// PlanID is the price plan to buy. Without it, the most specific plan of
+	// the course for EnrollmentType is used.
+	PlanID         uint
 	EnrollmentType models.EnrollmentType
+	// CouponCode is an optional promotional code taken off the price.
+	CouponCode string
+	// PayOnline keeps the enrollment pending until it is paid through the
+	// payment gateway.
+	PayOnline bool
 	// Additional fields can be added for specific session dates for 'pre_session' if needed
 }
 
-// calculateEnrollmentPrice calculates the total price and applies discounts based on enrollment type.
-func calculateEnrollmentPrice(coursePrice float64, enrollmentType models.EnrollmentType) (float64, float64, error) {
-	var totalPrice float64
-	var discount float64 // Stored as a percentage (e.g., 0.10 for 10%)
-
-	switch enrollmentType {
-	case models.PreSession:
-		totalPrice = coursePrice // No discount for single session
-		discount = 0.0
-	case models.Monthly:
-		// Assume 4 sessions in a month for calculation, apply a small discount
-		totalPrice = coursePrice * 4
-		discount = 0.10 // 10% discount for monthly
-		totalPrice *= (1 - discount)
-	case models.SixMonth:
-		// Assume 24 sessions in 6 months
-		totalPrice = coursePrice * 24
-		discount = 0.20 // 20% discount for 6-month
-		totalPrice *= (1 - discount)
-	case models.Yearly:
-		// Assume 48 sessions in a year
-		totalPrice = coursePrice * 48
-		discount = 0.30 // 30% discount for yearly
-		totalPrice *= (1 - discount)
-	default:
-		return 0, 0, fmt.Errorf("invalid enrollment type: %s", enrollmentType)
-	}
-
-	return totalPrice, discount, nil
+// EnrollResponse is the created enrollment. For online payments it carries
+// the gateway URL the student is redirected to.
+type EnrollResponse struct {
+	models.Enrollment
+	PaymentURL string `json:"paymentUrl,omitempty"`
 }
 
 // EnrollInCourse godoc
 // @Summary Enroll a student in a course (Student only)
-// @Description Allows a student to enroll in a yoga course with various enrollment packages.
+// @Description Allows a student to enroll in a yoga course with one of its price plans, optionally redeeming a coupon code. A pack bought for a course can be booked in any course matching the pack's filter. Enrollments paid online stay pending until the payment gateway confirms the payment, unless a coupon leaves nothing to pay.
 // @Tags Enrollments
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param enrollment body EnrollRequest true "Enrollment details"
-// @Success 201 {object} models.Enrollment
+// @Success 201 {object} EnrollResponse
 // @Failure 400 {object} map[string]string "error: Bad request"
 // @Failure 401 {object} map[string]string "error: Unauthorized"
 // @Failure 403 {object} map[string]string "error: Forbidden"
 // @Failure 404 {object} map[string]string "error: Course not found"
-// @Failure 409 {object} map[string]string "error: Already enrolled or course full"
+// @Failure 409 {object} map[string]string "error: Already enrolled or coupon used up"
 // @Failure 500 {object} map[string]string "error: Internal server error"
+// @Failure 502 {object} map[string]string "error: Payment gateway error"
 // @Router /enrollments [post]
 func (h *EnrollmentHandler) EnrollInCourse(c *gin.Context) {
 	userIDAny, exists := c.Get("userID")
@@ -81,7 +75,7 @@ func (h *EnrollmentHandler) EnrollInCourse(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
 		return
 	}
-	studentID := userIDAny.(uint)
+	studentID := uuid.MustParse(userIDAny.(string))
 
 	var req EnrollRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -100,59 +94,141 @@ func (h *EnrollmentHandler) EnrollInCourse(c *gin.Context) {
 		return
 	}
 
-	// Check course capacity
-	var currentEnrollments int64
-	h.DB.Model(&models.Enrollment{}).Where("course_id = ?", req.CourseID).Count(&currentEnrollments)
-	if int(currentEnrollments) >= course.Capacity {
-		c.JSON(http.StatusConflict, gin.H{"error": "Course is full"})
+	// Capacity is enforced per session when booking, not per enrollment
+
+	plan, err := h.enrollmentPlan(&course, &req)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No such price plan for this course"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch price plan"})
 		return
 	}
 
 	// Check if student is already enrolled in this course for the same period (optional, depending on business logic)
 	// For simplicity, we'll prevent duplicate enrollments for any type for now.
+	// Packs are not tied to the course and can be bought again to top up.
 	var existingEnrollment models.Enrollment
-	if h.DB.Where("user_id = ? AND course_id = ?", studentID, req.CourseID).First(&existingEnrollment).Error == nil {
+	if plan.EnrollmentType != models.Pack &&
+		h.DB.Where("user_id = ? AND course_id = ? AND enrollment_type <> ? AND status NOT IN ?", studentID, req.CourseID,
+			models.Pack, []models.EnrollmentStatus{models.EnrollmentCancelled, models.EnrollmentExpired, models.EnrollmentTransferred}).
+			First(&existingEnrollment).Error == nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "You are already enrolled in this course"})
 		return
 	}
 
 	// Calculate price and discount
-	totalPrice, discount, err := calculateEnrollmentPrice(course.Price, req.EnrollmentType)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+	totalPrice, discount := plan.Quote(&course)
 
 	now := time.Now()
-	var endDate time.Time
 
-	switch req.EnrollmentType {
-	case models.PreSession:
-		endDate = now // For pre-session, end date might just be the session date itself or not applicable
-	case models.Monthly:
-		endDate = now.AddDate(0, 1, 0) // 1 month from now
-	case models.SixMonth:
-		endDate = now.AddDate(0, 6, 0) // 6 months from now
-	case models.Yearly:
-		endDate = now.AddDate(1, 0, 0) // 1 year from now
+	status := models.EnrollmentActive
+	if req.PayOnline {
+		status = models.EnrollmentPending
 	}
 
 	enrollment := models.Enrollment{
 		UserID:          studentID,
 		CourseID:        req.CourseID,
-		EnrollmentType:  req.EnrollmentType,
+		EnrollmentType:  plan.EnrollmentType,
+		PricePlanID:     &plan.ID,
+		Status:          status,
+		Currency:        h.Cfg.Currency,
 		StartDate:       now,
-		ExpirationDate:  endDate,
+		ExpirationDate:  now.AddDate(0, plan.DurationMonths, 0),
 		PricePaid:       totalPrice,
 		DiscountApplied: discount,
+		TotalSessions:   plan.SessionLimit,
+		PackFilter:      plan.PackFilter,
 	}
 
-	if err := h.DB.Create(&enrollment).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enroll in course"})
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		var redemption *models.CouponRedemption
+		if req.CouponCode != "" {
+			redemption, err = redeemCoupon(tx, req.CouponCode, studentID, &enrollment, totalPrice, now)
+			if err != nil {
+				return err
+			}
+			enrollment.PricePaid -= redemption.Amount
+			if totalPrice > 0 {
+				// The discount of the plan and the coupon combined
+				enrollment.DiscountApplied = 1 - (1-discount)*float64(enrollment.PricePaid)/float64(totalPrice)
+			}
+		}
+		enrollment.NetPrice, enrollment.Tax, enrollment.PricePaid = h.Cfg.Tax.Apply(enrollment.PricePaid)
+		enrollment.TaxRate = h.Cfg.Tax.Rate
+		if enrollment.PricePaid == 0 {
+			enrollment.Status = models.EnrollmentActive // Nothing to pay online
+		}
+
+		if err := tx.Create(&enrollment).Error; err != nil {
+			return err
+		}
+		if redemption != nil {
+			redemption.EnrollmentID = enrollment.ID
+			return tx.Create(redemption).Error
+		}
+		return nil
+	})
+	if err != nil {
+		switch err {
+		case errCouponInvalid:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "This coupon code is not valid"})
+		case errCouponNotApplicable:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "This coupon code does not apply to this course or plan"})
+		case errCouponExhausted:
+			c.JSON(http.StatusConflict, gin.H{"error": "This coupon code has been fully redeemed"})
+		case errCouponUserLimit:
+			c.JSON(http.StatusConflict, gin.H{"error": "You have already used this coupon code"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enroll in course"})
+		}
 		return
 	}
+	enrollment.Balance = enrollment.PricePaid // Nothing paid yet
+	if enrollment.TotalSessions > 0 {
+		left := enrollment.TotalSessions
+		enrollment.SessionsLeft = &left
+	}
 
-	c.JSON(http.StatusCreated, enrollment)
+	response := EnrollResponse{Enrollment: enrollment}
+	if req.PayOnline && enrollment.Balance > 0 {
+		_, checkout, err := payment.StartOnline(c.Request.Context(), h.DB, h.Gateway, h.Cfg.PaymentCallbackURL(), &enrollment)
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to start online payment"})
+			return
+		}
+		response.PaymentURL = checkout.RedirectURL
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// enrollmentPlan finds the price plan the enrollment request buys for the
+// course. It returns gorm.ErrRecordNotFound when there is no such plan.
+func (h *EnrollmentHandler) enrollmentPlan(course *models.Course, req *EnrollRequest) (*models.PricePlan, error) {
+	if req.PlanID != 0 {
+		var plan models.PricePlan
+		if err := h.DB.First(&plan, req.PlanID).Error; err != nil {
+			return nil, err
+		}
+		if !plan.Active || !plan.Applies(course) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return &plan, nil
+	}
+
+	plans, err := applicablePlans(h.DB, course)
+	if err != nil {
+		return nil, err
+	}
+	for i := range plans {
+		if plans[i].EnrollmentType == req.EnrollmentType {
+			return &plans[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
 }
 
 // GetStudentEnrollments godoc
@@ -171,7 +247,7 @@ func (h *EnrollmentHandler) GetStudentEnrollments(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
 		return
 	}
-	studentID := userIDAny.(uint)
+	studentID := uuid.MustParse(userIDAny.(string))
 
 	var enrollments []models.Enrollment
 	if err := h.DB.Preload("Course.Instructor").Where("user_id = ?", studentID).Find(&enrollments).Error; err != nil {
@@ -179,12 +255,21 @@ func (h *EnrollmentHandler) GetStudentEnrollments(c *gin.Context) {
 		return
 	}
 
+	refs := make([]*models.Enrollment, len(enrollments))
+	for i := range enrollments {
+		refs[i] = &enrollments[i]
+	}
+	if err := loadBalances(h.DB, refs...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute enrollment balances"})
+		return
+	}
+
 	c.JSON(http.StatusOK, enrollments)
 }
 
 // GetEnrollmentByID godoc
 // @Summary Get enrollment by ID
-// @Description Retrieve details of a specific enrollment by its ID. (Admin/Enrolled Student only)
+// @Description Retrieve details of a specific enrollment by its ID. (Admin/Enrolled Student/Household member only)
 // @Tags Enrollments
 // @Security BearerAuth
 // @Produce json
@@ -201,7 +286,7 @@ func (h *EnrollmentHandler) GetEnrollmentByID(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
 		return
 	}
-	currentUserID := userIDAny.(uint)
+	currentUserID := uuid.MustParse(userIDAny.(string))
 	userRoleAny := c.MustGet("userRole")
 	currentUserRole := userRoleAny.(models.UserRole)
 
@@ -221,26 +306,132 @@ func (h *EnrollmentHandler) GetEnrollmentByID(c *gin.Context) {
 		return
 	}
 
-	// Only admin or the enrolled student can view this enrollment
+	// Only admin, the enrolled student or their household members can view this enrollment
+	if currentUserRole != models.Admin && enrollment.UserID != currentUserID {
+		member, err := householdMember(h.DB, currentUserID, enrollment.UserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch household"})
+			return
+		}
+		if member == nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to view this enrollment"})
+			return
+		}
+	}
+
+	if err := loadBalances(h.DB, &enrollment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute enrollment balance"})
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// AutoRenewRequest defines the request body for toggling auto-renewal.
+type AutoRenewRequest struct {
+	AutoRenew *bool `json:"autoRenew" binding:"required"`
+}
+
+// SetAutoRenew godoc
+// @Summary Turn auto-renewal of an enrollment on or off (Student/Admin only)
+// @Description An enrollment set to auto-renew is renewed with the same price plan when it expires. The renewal is pending until it is paid online.
+// @Tags Enrollments
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Enrollment ID"
+// @Param autoRenew body AutoRenewRequest true "Auto-renewal setting"
+// @Success 200 {object} models.Enrollment
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Enrollment not found"
+// @Failure 409 {object} map[string]string "error: Enrollment cannot be renewed"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /enrollments/{id}/auto-renew [put]
+func (h *EnrollmentHandler) SetAutoRenew(c *gin.Context) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	currentUserID := uuid.MustParse(userIDAny.(string))
+	currentUserRole := c.MustGet("userRole").(models.UserRole)
+
+	enrollmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid enrollment ID"})
+		return
+	}
+
+	var req AutoRenewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var enrollment models.Enrollment
+	if err := h.DB.First(&enrollment, uint(enrollmentID)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Enrollment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch enrollment"})
+		return
+	}
+
 	if currentUserRole != models.Admin && enrollment.UserID != currentUserID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to view this enrollment"})
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to change this enrollment"})
+		return
+	}
+
+	if *req.AutoRenew && (enrollment.PricePlanID == nil ||
+		(enrollment.Status != models.EnrollmentActive && enrollment.Status != models.EnrollmentPending)) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only current enrollments bought with a price plan can be renewed"})
+		return
+	}
+
+	if err := h.DB.Model(&enrollment).Update("auto_renew", *req.AutoRenew).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update enrollment"})
+		return
+	}
+	if err := loadBalances(h.DB, &enrollment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute enrollment balance"})
 		return
 	}
 
 	c.JSON(http.StatusOK, enrollment)
 }
 
+// CancelEnrollmentRequest defines the optional request body for cancelling an enrollment.
+type CancelEnrollmentRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+	// RefundAsCredit refunds into the student's wallet instead of paying back.
+	RefundAsCredit bool `json:"refundAsCredit"`
+}
+
+// CancellationResponse is the cancelled enrollment and the refunds issued for it.
+type CancellationResponse struct {
+	Enrollment models.Enrollment `json:"enrollment"`
+	Refunded   money.Amount      `json:"refunded"`
+	Refunds    []models.Payment  `json:"refunds"`
+}
+
 // CancelEnrollment godoc
 // @Summary Cancel an enrollment (Student/Admin only)
-// @Description Allows a student to cancel their enrollment, or an admin to cancel any enrollment.
+// @Description Allows a student to cancel their enrollment, or an admin to cancel any enrollment. Upcoming bookings made with it are cancelled and their places offered to the waitlist, as are its pending freezes and those yet to start. What was paid is refunded according to the cancellation policy: in full shortly after the start date, pro-rated by unused sessions or remaining time afterwards, and not at all after the cutoff. The enrollment is kept as cancelled. Refunds the payment gateway turns down are listed as failed and can be refunded again by staff.
 // @Tags Enrollments
 // @Security BearerAuth
+// @Accept json
 // @Produce json
 // @Param id path int true "Enrollment ID"
-// @Success 204 "No Content"
+// @Param cancellation body CancelEnrollmentRequest false "Cancellation reason and refund options"
+// @Success 200 {object} CancellationResponse
+// @Failure 400 {object} map[string]string "error: Bad request"
 // @Failure 401 {object} map[string]string "error: Unauthorized"
 // @Failure 403 {object} map[string]string "error: Forbidden"
 // @Failure 404 {object} map[string]string "error: Enrollment not found"
+// @Failure 409 {object} map[string]string "error: Already cancelled"
 // @Failure 500 {object} map[string]string "error: Internal server error"
 // @Router /enrollments/{id} [delete]
 func (h *EnrollmentHandler) CancelEnrollment(c *gin.Context) {
@@ -249,7 +440,7 @@ func (h *EnrollmentHandler) CancelEnrollment(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
 		return
 	}
-	currentUserID := userIDAny.(uint)
+	currentUserID := uuid.MustParse(userIDAny.(string))
 	userRoleAny := c.MustGet("userRole")
 	currentUserRole := userRoleAny.(models.UserRole)
 
@@ -275,10 +466,100 @@ func (h *EnrollmentHandler) CancelEnrollment(c *gin.Context) {
 		return
 	}
 
-	if err := h.DB.Delete(&enrollment).Error; err != nil {
+	if enrollment.Status == models.EnrollmentCancelled {
+		c.JSON(http.StatusConflict, gin.H{"error": "This enrollment is already cancelled"})
+		return
+	}
+	if enrollment.Status == models.EnrollmentTransferred {
+		c.JSON(http.StatusConflict, gin.H{"error": "This enrollment was transferred, cancel the new enrollment instead"})
+		return
+	}
+
+	var req CancelEnrollmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF { // The body is optional
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	var refund money.Amount
+	var refunds []models.Payment
+	var promoted []*models.Booking
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		// The refund is priced from the sessions used and payments as they
+		// are where it is written, attendance or payments may have been
+		// recorded meanwhile
+		if err := tx.First(&enrollment, enrollment.ID).Error; err != nil {
+			return err
+		}
+		if err := loadBalances(tx, &enrollment); err != nil {
+			return err
+		}
+		paid := enrollment.PricePaid - enrollment.Balance
+
+		// Guard against a concurrent cancellation refunding twice
+		update := tx.Model(&enrollment).Where("status NOT IN ?",
+			[]models.EnrollmentStatus{models.EnrollmentCancelled, models.EnrollmentTransferred}).Updates(models.Enrollment{
+			Status:             models.EnrollmentCancelled,
+			CancelledAt:        &now,
+			CancellationReason: req.Reason,
+		})
+		if update.Error != nil {
+			return update.Error
+		}
+		if update.RowsAffected == 0 {
+			return errEnrollmentCancelled
+		}
+
+		// Payments still open at the gateway can no longer settle the enrollment
+		if err := tx.Model(&models.Payment{}).
+			Where("enrollment_id = ? AND status = ?", enrollment.ID, models.PaymentPending).
+			Update("status", models.PaymentFailed).Error; err != nil {
+			return err
+		}
+
+		promoted, err = cancelUpcomingBookings(tx, enrollment.ID)
+		if err != nil {
+			return err
+		}
+		if err := cancelFreezes(tx, &enrollment); err != nil {
+			return err
+		}
+
+		refund = refundAmount(h.Cfg.Cancellation, &enrollment, paid, now)
+		var method models.PaymentMethod
+		if req.RefundAsCredit {
+			method = models.Credit
+		}
+		refunds, err = refundEnrollment(tx, &enrollment, refund, method)
+		return err
+	})
+	if err != nil {
+		if err == errEnrollmentCancelled {
+			c.JSON(http.StatusConflict, gin.H{"error": "This enrollment is already cancelled"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel enrollment"})
 		return
 	}
+	for _, booking := range promoted {
+		notifyPromoted(h.Notifier, booking)
+	}
+
+	// The gateway is only asked to pay back refunds already recorded, and
+	// those it turns down are left failed for staff to refund again
+	for i := range refunds {
+		if refunds[i].Status != models.PaymentRefundPending {
+			continue
+		}
+		if err := settleRefund(c.Request.Context(), h.DB, h.Gateway, &refunds[i]); err != nil {
+			log.Printf("failed to settle refund %d of enrollment %d: %v", refunds[i].ID, enrollment.ID, err)
+		}
+		if refunds[i].Status == models.PaymentRefundFailed {
+			refund -= refunds[i].Amount
+		}
+	}
 
-	c.Status(http.StatusNoContent)
+	enrollment.Balance = 0 // Nothing is owed on a cancelled enrollment
+	c.JSON(http.StatusOK, CancellationResponse{Enrollment: enrollment, Refunded: refund, Refunds: refunds})
 }
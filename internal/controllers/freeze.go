@@ -0,0 +1,556 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/notify"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	errFreezeOverlaps      = errors.New("freeze overlaps another freeze")
+	errFreezeNotPending    = errors.New("freeze is not pending")
+	errEnrollmentFrozen    = errors.New("enrollment is frozen")
+	errFreezeTooLong       = errors.New("freeze exceeds the yearly limit")
+	errFreezeNotFound      = errors.New("freeze not found")
+	errFreezeNotCancelable = errors.New("freeze can no longer be cancelled")
+	errFreezeNotActive     = errors.New("enrollment of the freeze is not active")
+)
+
+// freezeDateFormat is the format of freeze dates in requests.
+const freezeDateFormat = "2006-01-02"
+
+// FreezeHandler provides methods for enrollment freezes.
+type FreezeHandler struct {
+	DB       *gorm.DB
+	Cfg      *config.Config
+	Notifier notify.Notifier
+}
+
+// NewFreezeHandler creates a new FreezeHandler instance.
+func NewFreezeHandler(db *gorm.DB, cfg *config.Config, notifier notify.Notifier) *FreezeHandler {
+	return &FreezeHandler{DB: db, Cfg: cfg, Notifier: notifier}
+}
+
+// FreezeRequest defines the request body for freezing an enrollment.
+type FreezeRequest struct {
+	StartDate string `json:"startDate" binding:"required,datetime=2006-01-02"` // First frozen day
+	EndDate   string `json:"endDate" binding:"required,datetime=2006-01-02"`   // Last frozen day
+	Reason    string `json:"reason" binding:"max=500"`
+}
+
+// RequestFreeze godoc
+// @Summary Freeze an enrollment (Student/Admin only)
+// @Description Pause a six month or yearly enrollment, for travel or an injury. The freeze must be requested in advance and is limited in days per calendar year. Once approved, which may be immediate depending on the studio, the enrollment cannot be booked during the freeze, bookings made for it are cancelled and the expiration date moves out by the frozen days.
+// @Tags Freezes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Enrollment ID"
+// @Param freeze body FreezeRequest true "Freeze dates"
+// @Success 201 {object} models.EnrollmentFreeze
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Enrollment not found"
+// @Failure 409 {object} map[string]string "error: Overlapping freeze or yearly limit reached"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /enrollments/{id}/freezes [post]
+func (h *FreezeHandler) RequestFreeze(c *gin.Context) {
+	enrollment, ok := h.ownEnrollment(c)
+	if !ok {
+		return
+	}
+
+	var req FreezeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	start, _ := time.ParseInLocation(freezeDateFormat, req.StartDate, time.Local)
+	last, _ := time.ParseInLocation(freezeDateFormat, req.EndDate, time.Local)
+	end := last.AddDate(0, 0, 1)
+
+	if enrollment.Status != models.EnrollmentActive ||
+		(enrollment.EnrollmentType != models.SixMonth && enrollment.EnrollmentType != models.Yearly) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only active six month and yearly enrollments can be frozen"})
+		return
+	}
+	if !end.After(start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The freeze must end on or after its start date"})
+		return
+	}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if start.Before(today.AddDate(0, 0, h.Cfg.Freeze.MinNoticeDays)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A freeze must be requested at least %d days before it starts", h.Cfg.Freeze.MinNoticeDays)})
+		return
+	}
+	if !start.Before(enrollment.ExpirationDate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The freeze must start before the enrollment expires"})
+		return
+	}
+
+	userIDAny, _ := c.Get("userID")
+	freeze := models.EnrollmentFreeze{
+		EnrollmentID: enrollment.ID,
+		UserID:       enrollment.UserID,
+		StartDate:    start,
+		EndDate:      end,
+		Days:         int(end.Sub(start).Hours()/24 + 0.5), // Rounded, days around DST changes are not 24 hours long
+		Reason:       req.Reason,
+		Status:       models.FreezePending,
+	}
+
+	var promoted []*models.Booking
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var freezes []models.EnrollmentFreeze
+		if err := tx.Where("enrollment_id = ? AND status IN ?", enrollment.ID,
+			[]models.FreezeStatus{models.FreezePending, models.FreezeApproved}).
+			Find(&freezes).Error; err != nil {
+			return err
+		}
+		for _, other := range freezes {
+			if other.StartDate.Before(freeze.EndDate) && freeze.StartDate.Before(other.EndDate) {
+				return errFreezeOverlaps
+			}
+		}
+		// Freezes over New Year count towards the limit of both years
+		for year := freeze.StartDate.Year(); year <= last.Year(); year++ {
+			days := freezeDaysIn(&freeze, year)
+			for i := range freezes {
+				days += freezeDaysIn(&freezes[i], year)
+			}
+			if days > h.Cfg.Freeze.MaxDaysPerYear {
+				return errFreezeTooLong
+			}
+		}
+
+		if err := tx.Create(&freeze).Error; err != nil {
+			return err
+		}
+		if h.Cfg.Freeze.RequireApproval {
+			return nil
+		}
+
+		var err error
+		promoted, err = approveFreeze(tx, &freeze, uuid.MustParse(userIDAny.(string)))
+		return err
+	})
+	if err != nil {
+		switch err {
+		case errFreezeOverlaps:
+			c.JSON(http.StatusConflict, gin.H{"error": "The freeze overlaps another freeze of this enrollment"})
+		case errFreezeTooLong:
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("An enrollment can be frozen for at most %d days a year", h.Cfg.Freeze.MaxDaysPerYear)})
+		case errFreezeNotActive:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Only active six month and yearly enrollments can be frozen"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to freeze enrollment"})
+		}
+		return
+	}
+	for _, booking := range promoted {
+		notifyPromoted(h.Notifier, booking)
+	}
+
+	c.JSON(http.StatusCreated, freeze)
+}
+
+// GetEnrollmentFreezes godoc
+// @Summary Get the freezes of an enrollment
+// @Description List the freezes requested for an enrollment. (Admin/Enrolled Student only)
+// @Tags Freezes
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Enrollment ID"
+// @Success 200 {array} models.EnrollmentFreeze
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Enrollment not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /enrollments/{id}/freezes [get]
+func (h *FreezeHandler) GetEnrollmentFreezes(c *gin.Context) {
+	enrollment, ok := h.ownEnrollment(c)
+	if !ok {
+		return
+	}
+
+	var freezes []models.EnrollmentFreeze
+	if err := h.DB.Where("enrollment_id = ?", enrollment.ID).Order("start_date").Find(&freezes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch freezes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, freezes)
+}
+
+// CancelFreeze godoc
+// @Summary Cancel a freeze (Student/Admin only)
+// @Description Withdraw a freeze that has not started yet. An approved freeze gives back the days it added to the enrollment.
+// @Tags Freezes
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Enrollment ID"
+// @Param freezeID path int true "Freeze ID"
+// @Success 200 {object} models.EnrollmentFreeze
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Freeze not found"
+// @Failure 409 {object} map[string]string "error: Freeze already started or decided"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /enrollments/{id}/freezes/{freezeID} [delete]
+func (h *FreezeHandler) CancelFreeze(c *gin.Context) {
+	enrollment, ok := h.ownEnrollment(c)
+	if !ok {
+		return
+	}
+
+	freezeID, err := strconv.ParseUint(c.Param("freezeID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid freeze ID"})
+		return
+	}
+
+	var freeze models.EnrollmentFreeze
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("enrollment_id = ?", enrollment.ID).First(&freeze, uint(freezeID)).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errFreezeNotFound
+			}
+			return err
+		}
+		wasApproved := freeze.Status == models.FreezeApproved
+		if (freeze.Status != models.FreezePending && !wasApproved) || !time.Now().Before(freeze.StartDate) {
+			return errFreezeNotCancelable
+		}
+
+		update := tx.Model(&freeze).Where("status = ?", freeze.Status).Update("status", models.FreezeCancelled)
+		if update.Error != nil {
+			return update.Error
+		}
+		if update.RowsAffected == 0 {
+			return errFreezeNotCancelable
+		}
+		if !wasApproved {
+			return nil
+		}
+
+		// Read the expiration again, other freezes may have moved it meanwhile
+		if err := tx.First(enrollment, enrollment.ID).Error; err != nil {
+			return err
+		}
+		// The enrollment now expires earlier, remind the student again then
+		return tx.Model(enrollment).Updates(map[string]any{
+			"expiration_date":          enrollment.ExpirationDate.AddDate(0, 0, -freeze.Days),
+			"renewal_reminder_sent_at": nil,
+		}).Error
+	})
+	if err != nil {
+		switch err {
+		case errFreezeNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Freeze not found"})
+		case errFreezeNotCancelable:
+			c.JSON(http.StatusConflict, gin.H{"error": "Only pending or approved freezes that have not started can be cancelled"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel freeze"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, freeze)
+}
+
+// GetFreezes godoc
+// @Summary Get freezes by status (Admin only)
+// @Description List enrollment freezes, the pending ones waiting for approval by default.
+// @Tags Freezes
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Freeze status" Enums(pending, approved, rejected, cancelled)
+// @Success 200 {array} models.EnrollmentFreeze
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /freezes [get]
+func (h *FreezeHandler) GetFreezes(c *gin.Context) {
+	status := models.FreezeStatus(c.DefaultQuery("status", string(models.FreezePending)))
+
+	var freezes []models.EnrollmentFreeze
+	if err := h.DB.Preload("Enrollment").Where("status = ?", status).Order("start_date").Find(&freezes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch freezes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, freezes)
+}
+
+// ApproveFreeze godoc
+// @Summary Approve a freeze (Admin only)
+// @Description Approve a pending freeze of an active enrollment. The enrollment's expiration date moves out by the frozen days and bookings made for the frozen days are cancelled.
+// @Tags Freezes
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Freeze ID"
+// @Success 200 {object} models.EnrollmentFreeze
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Freeze not found"
+// @Failure 409 {object} map[string]string "error: Freeze is not pending or enrollment not active"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /freezes/{id}/approve [post]
+func (h *FreezeHandler) ApproveFreeze(c *gin.Context) {
+	h.decideFreeze(c, true)
+}
+
+// RejectFreeze godoc
+// @Summary Reject a freeze (Admin only)
+// @Description Reject a pending freeze. The enrollment is left unchanged.
+// @Tags Freezes
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Freeze ID"
+// @Success 200 {object} models.EnrollmentFreeze
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Freeze not found"
+// @Failure 409 {object} map[string]string "error: Freeze is not pending"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /freezes/{id}/reject [post]
+func (h *FreezeHandler) RejectFreeze(c *gin.Context) {
+	h.decideFreeze(c, false)
+}
+
+// decideFreeze approves or rejects the pending freeze from the path and
+// tells the student.
+func (h *FreezeHandler) decideFreeze(c *gin.Context, approve bool) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	adminID := uuid.MustParse(userIDAny.(string))
+
+	freezeID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid freeze ID"})
+		return
+	}
+
+	var freeze models.EnrollmentFreeze
+	var promoted []*models.Booking
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&freeze, uint(freezeID)).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errFreezeNotFound
+			}
+			return err
+		}
+		if approve {
+			promoted, err = approveFreeze(tx, &freeze, adminID)
+			return err
+		}
+
+		now := time.Now()
+		update := tx.Model(&freeze).Where("status = ?", models.FreezePending).Updates(models.EnrollmentFreeze{
+			Status:      models.FreezeRejected,
+			DecidedByID: &adminID,
+			DecidedAt:   &now,
+		})
+		if update.Error != nil {
+			return update.Error
+		}
+		if update.RowsAffected == 0 {
+			return errFreezeNotPending
+		}
+		return nil
+	})
+	if err != nil {
+		switch err {
+		case errFreezeNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Freeze not found"})
+		case errFreezeNotPending:
+			c.JSON(http.StatusConflict, gin.H{"error": "This freeze has already been decided"})
+		case errFreezeNotActive:
+			c.JSON(http.StatusConflict, gin.H{"error": "The enrollment of this freeze is no longer active"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decide freeze"})
+		}
+		return
+	}
+	for _, booking := range promoted {
+		notifyPromoted(h.Notifier, booking)
+	}
+
+	var student models.User
+	if err := h.DB.First(&student, "id = ?", freeze.UserID).Error; err == nil {
+		decision := "rejected"
+		if approve {
+			decision = "approved"
+		}
+		message := fmt.Sprintf("Your freeze from %s to %s was %s.",
+			freeze.StartDate.Format(freezeDateFormat), freeze.EndDate.AddDate(0, 0, -1).Format(freezeDateFormat), decision)
+		if err := h.Notifier.Notify(student, message); err != nil {
+			log.Printf("failed to notify user %s of freeze %d: %v", student.ID, freeze.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, freeze)
+}
+
+// ownEnrollment fetches the enrollment from the path, writing an error
+// response unless the current user is its student or an admin.
+func (h *FreezeHandler) ownEnrollment(c *gin.Context) (*models.Enrollment, bool) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return nil, false
+	}
+	currentUserID := uuid.MustParse(userIDAny.(string))
+	currentUserRole := c.MustGet("userRole").(models.UserRole)
+
+	enrollmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid enrollment ID"})
+		return nil, false
+	}
+
+	var enrollment models.Enrollment
+	if err := h.DB.First(&enrollment, uint(enrollmentID)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Enrollment not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch enrollment"})
+		return nil, false
+	}
+
+	if currentUserRole != models.Admin && enrollment.UserID != currentUserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to manage this enrollment"})
+		return nil, false
+	}
+	return &enrollment, true
+}
+
+// approveFreeze approves a pending freeze, extends the enrollment by the
+// frozen days and cancels the bookings made for them. It returns the
+// waitlisted bookings promoted into the freed places.
+func approveFreeze(tx *gorm.DB, freeze *models.EnrollmentFreeze, decidedBy uuid.UUID) ([]*models.Booking, error) {
+	var enrollment models.Enrollment
+	if err := tx.First(&enrollment, freeze.EnrollmentID).Error; err != nil {
+		return nil, err
+	}
+	// A cancelled or transferred enrollment has no term left to extend
+	if enrollment.Status != models.EnrollmentActive {
+		return nil, errFreezeNotActive
+	}
+
+	now := time.Now()
+	update := tx.Model(freeze).Where("status = ?", models.FreezePending).Updates(models.EnrollmentFreeze{
+		Status:      models.FreezeApproved,
+		DecidedByID: &decidedBy,
+		DecidedAt:   &now,
+	})
+	if update.Error != nil {
+		return nil, update.Error
+	}
+	if update.RowsAffected == 0 {
+		return nil, errFreezeNotPending
+	}
+
+	// The enrollment now expires later, remind the student again then
+	if err := tx.Model(&enrollment).Updates(map[string]any{
+		"expiration_date":          enrollment.ExpirationDate.AddDate(0, 0, freeze.Days),
+		"renewal_reminder_sent_at": nil,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	var bookings []models.Booking
+	if err := tx.Joins("CourseSession").
+		Where("bookings.enrollment_id = ? AND bookings.status IN ? AND CourseSession.scheduled_at >= ? AND CourseSession.scheduled_at < ?",
+			freeze.EnrollmentID, []models.BookingStatus{models.BookingBooked, models.BookingWaitlisted}, freeze.StartDate, freeze.EndDate).
+		Find(&bookings).Error; err != nil {
+		return nil, err
+	}
+	var promoted []*models.Booking
+	for i := range bookings {
+		booking, err := cancelBooking(tx, &bookings[i])
+		if err != nil {
+			return nil, err
+		}
+		if booking != nil {
+			promoted = append(promoted, booking)
+		}
+	}
+	return promoted, nil
+}
+
+// cancelFreezes cancels the pending freezes of the enrollment and the
+// approved ones yet to start, when it is cancelled or transferred. The
+// expiration date of the enrollment moves back by the days it was extended.
+func cancelFreezes(tx *gorm.DB, enrollment *models.Enrollment) error {
+	var freezes []models.EnrollmentFreeze
+	if err := tx.Where("enrollment_id = ? AND (status = ? OR status = ? AND start_date > ?)", enrollment.ID,
+		models.FreezePending, models.FreezeApproved, time.Now()).Find(&freezes).Error; err != nil {
+		return err
+	}
+
+	days := 0
+	for i := range freezes {
+		if freezes[i].Status == models.FreezeApproved {
+			days += freezes[i].Days
+		}
+		if err := tx.Model(&freezes[i]).Update("status", models.FreezeCancelled).Error; err != nil {
+			return err
+		}
+	}
+	if days == 0 {
+		return nil
+	}
+	// Read the expiration again, other freezes may have moved it meanwhile
+	var current models.Enrollment
+	if err := tx.Select("expiration_date").First(&current, enrollment.ID).Error; err != nil {
+		return err
+	}
+	enrollment.ExpirationDate = current.ExpirationDate.AddDate(0, 0, -days)
+	return tx.Model(enrollment).Update("expiration_date", enrollment.ExpirationDate).Error
+}
+
+// freezeDaysIn counts the days of the freeze that fall in year.
+func freezeDaysIn(freeze *models.EnrollmentFreeze, year int) int {
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(1, 0, 0)
+	if freeze.StartDate.After(from) {
+		from = freeze.StartDate
+	}
+	if freeze.EndDate.Before(to) {
+		to = freeze.EndDate
+	}
+	if !to.After(from) {
+		return 0
+	}
+	return int(to.Sub(from).Hours()/24 + 0.5) // Rounded, days around DST changes are not 24 hours long
+}
+
+// isFrozen reports whether an approved freeze of the enrollment covers at.
+func isFrozen(tx *gorm.DB, enrollmentID uint, at time.Time) (bool, error) {
+	var count int64
+	err := tx.Model(&models.EnrollmentFreeze{}).
+		Where("enrollment_id = ? AND status = ? AND start_date <= ? AND end_date > ?", enrollmentID, models.FreezeApproved, at, at).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/notify"
+	"yoga-guru/internal/payment"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestFreezeExtendsEnrollmentAndBlocksBooking(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	cfg := &config.Config{Freeze: config.FreezePolicy{MaxDaysPerYear: 14, MinNoticeDays: 1}}
+	freezes := NewFreezeHandler(db, cfg, notify.LogNotifier{})
+	sessions := NewSessionHandler(db, cfg, notify.LogNotifier{})
+
+	student := models.User{Phone: "+989120000600", Role: models.Student}
+	db.Create(&student)
+	course := models.Course{Title: "Iyengar", Capacity: 10}
+	db.Create(&course)
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	start := today.AddDate(0, 0, 7)
+	if start.Month() == time.December {
+		// Keep both freezes in the same year for the yearly limit
+		start = time.Date(start.Year()+1, time.January, 1, 0, 0, 0, 0, time.Local)
+	}
+	expiration := today.AddDate(1, 0, 0)
+	enrollment := models.Enrollment{
+		UserID:         student.ID,
+		CourseID:       course.ID,
+		EnrollmentType: models.Yearly,
+		StartDate:      now,
+		ExpirationDate: expiration,
+	}
+	db.Create(&enrollment)
+	session := models.CourseSession{CourseID: course.ID, ScheduledAt: start.AddDate(0, 0, 2).Add(10 * time.Hour)}
+	db.Create(&session)
+
+	r := gin.New()
+	r.Use(withUser(student.ID, models.Student))
+	r.POST("/enrollments/:id/freezes", freezes.RequestFreeze)
+	r.POST("/sessions/:id/bookings", sessions.BookSession)
+	serve := func(path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return rr
+	}
+
+	freezePath := fmt.Sprintf("/enrollments/%d/freezes", enrollment.ID)
+	body := fmt.Sprintf(`{"startDate":%q,"endDate":%q}`, start.Format(freezeDateFormat), start.AddDate(0, 0, 9).Format(freezeDateFormat))
+	if rr := serve(freezePath, body); rr.Code != http.StatusCreated {
+		t.Fatalf("freezing: got status %d: %s", rr.Code, rr.Body)
+	}
+
+	db.First(&enrollment, enrollment.ID)
+	if want := expiration.AddDate(0, 0, 10); !enrollment.ExpirationDate.Equal(want) {
+		t.Errorf("got expiration %v, want %v", enrollment.ExpirationDate, want)
+	}
+
+	if rr := serve(fmt.Sprintf("/sessions/%d/bookings", session.ID), ""); rr.Code != http.StatusConflict {
+		t.Errorf("booking while frozen: got status %d, want %d", rr.Code, http.StatusConflict)
+	}
+
+	// Ten days are used, another week goes over the yearly limit
+	later := start.AddDate(0, 0, 14)
+	body = fmt.Sprintf(`{"startDate":%q,"endDate":%q}`, later.Format(freezeDateFormat), later.AddDate(0, 0, 6).Format(freezeDateFormat))
+	if rr := serve(freezePath, body); rr.Code != http.StatusConflict {
+		t.Errorf("freezing over the limit: got status %d, want %d", rr.Code, http.StatusConflict)
+	}
+}
+
+func TestFreezeYearlyLimitSplitsNewYear(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	cfg := &config.Config{Freeze: config.FreezePolicy{MaxDaysPerYear: 14, MinNoticeDays: 1}}
+	h := NewFreezeHandler(db, cfg, notify.LogNotifier{})
+
+	student := models.User{Phone: "+989120000610", Role: models.Student}
+	db.Create(&student)
+	course := models.Course{Title: "Iyengar"}
+	db.Create(&course)
+	now := time.Now()
+	enrollment := models.Enrollment{UserID: student.ID, CourseID: course.ID, EnrollmentType: models.Yearly,
+		Status: models.EnrollmentActive, StartDate: now, ExpirationDate: now.AddDate(3, 0, 0)}
+	db.Create(&enrollment)
+	reminded := now
+
+	r := gin.New()
+	r.Use(withUser(student.ID, models.Student))
+	r.POST("/enrollments/:id/freezes", h.RequestFreeze)
+	r.DELETE("/enrollments/:id/freezes/:freezeID", h.CancelFreeze)
+	year := now.Year() + 1
+	freeze := func(from, to time.Time, want int) models.EnrollmentFreeze {
+		t.Helper()
+		body := fmt.Sprintf(`{"startDate":%q,"endDate":%q}`, from.Format(freezeDateFormat), to.Format(freezeDateFormat))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/enrollments/%d/freezes", enrollment.ID), strings.NewReader(body)))
+		if rr.Code != want {
+			t.Fatalf("freezing %s to %s: got status %d, want %d: %s", from.Format(freezeDateFormat), to.Format(freezeDateFormat), rr.Code, want, rr.Body)
+		}
+		var freeze models.EnrollmentFreeze
+		json.Unmarshal(rr.Body.Bytes(), &freeze)
+		return freeze
+	}
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+	}
+
+	// Six days in December, then eight more in December and two in January
+	freeze(date(year, time.December, 1), date(year, time.December, 6), http.StatusCreated)
+	newYear := freeze(date(year, time.December, 24), date(year+1, time.January, 2), http.StatusCreated)
+	// Thirteen more days in January go over its limit, twelve don't
+	freeze(date(year+1, time.January, 10), date(year+1, time.January, 22), http.StatusConflict)
+	freeze(date(year+1, time.January, 10), date(year+1, time.January, 21), http.StatusCreated)
+
+	// Cancelling gives the days back and the renewal reminder is sent again
+	db.Model(&enrollment).Update("renewal_reminder_sent_at", &reminded)
+	db.First(&enrollment, enrollment.ID)
+	expiration := enrollment.ExpirationDate
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/enrollments/%d/freezes/%d", enrollment.ID, newYear.ID), nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("cancelling freeze: got status %d: %s", rr.Code, rr.Body)
+	}
+	var cancelled models.Enrollment
+	db.First(&cancelled, enrollment.ID)
+	if want := expiration.AddDate(0, 0, -10); !cancelled.ExpirationDate.Equal(want) || cancelled.RenewalReminderSentAt != nil {
+		t.Errorf("got expiration %v and reminder %v, want %v and none", cancelled.ExpirationDate, cancelled.RenewalReminderSentAt, want)
+	}
+}
+
+func TestCancelEnrollmentCancelsFreezes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	freezes := NewFreezeHandler(db, &config.Config{}, notify.LogNotifier{})
+	enrollments := NewEnrollmentHandler(db, &config.Config{}, notify.LogNotifier{}, payment.NewFakeGateway())
+
+	admin := models.User{Phone: "+989120000620", Role: models.Admin}
+	student := models.User{Phone: "+989120000621", Role: models.Student}
+	db.Create(&admin)
+	db.Create(&student)
+	course := models.Course{Title: "Iyengar"}
+	db.Create(&course)
+	now := time.Now()
+	expiration := now.AddDate(1, 0, 0)
+	enrollment := models.Enrollment{UserID: student.ID, CourseID: course.ID, EnrollmentType: models.Yearly,
+		Status: models.EnrollmentActive, StartDate: now, ExpirationDate: expiration.AddDate(0, 0, 7)}
+	db.Create(&enrollment)
+	approved := models.EnrollmentFreeze{EnrollmentID: enrollment.ID, UserID: student.ID, Status: models.FreezeApproved,
+		StartDate: now.AddDate(0, 1, 0), EndDate: now.AddDate(0, 1, 7), Days: 7}
+	pending := models.EnrollmentFreeze{EnrollmentID: enrollment.ID, UserID: student.ID, Status: models.FreezePending,
+		StartDate: now.AddDate(0, 2, 0), EndDate: now.AddDate(0, 2, 7), Days: 7}
+	db.Create(&approved)
+	db.Create(&pending)
+
+	r := gin.New()
+	r.Use(withUser(admin.ID, models.Admin))
+	r.DELETE("/enrollments/:id", enrollments.CancelEnrollment)
+	r.POST("/freezes/:id/approve", freezes.ApproveFreeze)
+	serve := func(method, path string) int {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(method, path, nil))
+		return rr.Code
+	}
+
+	if code := serve(http.MethodDelete, fmt.Sprintf("/enrollments/%d", enrollment.ID)); code != http.StatusOK {
+		t.Fatalf("cancelling the enrollment: got status %d", code)
+	}
+	var left int64
+	db.Model(&models.EnrollmentFreeze{}).Where("status <> ?", models.FreezeCancelled).Count(&left)
+	var cancelled models.Enrollment
+	db.First(&cancelled, enrollment.ID)
+	if left != 0 || !cancelled.ExpirationDate.Equal(expiration) {
+		t.Errorf("got %d freezes left and expiration %v, want none and %v", left, cancelled.ExpirationDate, expiration)
+	}
+
+	// A freeze still pending on an enrollment that is no longer active can't be approved
+	stale := models.EnrollmentFreeze{EnrollmentID: enrollment.ID, UserID: student.ID, Status: models.FreezePending,
+		StartDate: now.AddDate(0, 3, 0), EndDate: now.AddDate(0, 3, 7), Days: 7}
+	db.Create(&stale)
+	if code := serve(http.MethodPost, fmt.Sprintf("/freezes/%d/approve", stale.ID)); code != http.StatusConflict {
+		t.Errorf("approving a freeze of a cancelled enrollment: got status %d, want %d", code, http.StatusConflict)
+	}
+}
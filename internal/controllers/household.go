@@ -0,0 +1,528 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/notify"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var errMemberLimitReached = errors.New("household member session limit reached")
+
+// HouseholdHandler provides methods for households sharing enrollments.
+type HouseholdHandler struct {
+	DB       *gorm.DB
+	Notifier notify.Notifier
+}
+
+// NewHouseholdHandler creates a new HouseholdHandler instance.
+func NewHouseholdHandler(db *gorm.DB, notifier notify.Notifier) *HouseholdHandler {
+	return &HouseholdHandler{DB: db, Notifier: notifier}
+}
+
+// HouseholdMemberEntry describes a member of a household.
+type HouseholdMemberEntry struct {
+	UserID       uuid.UUID `json:"userId"`
+	Name         string    `json:"name"`
+	Phone        string    `json:"phone"`
+	SessionLimit int       `json:"sessionLimit"` // Per enrollment of the owner, zero means unlimited
+}
+
+// HouseholdResponse is a household with its members and the enrollments of
+// its owner that members can book sessions on.
+type HouseholdResponse struct {
+	ID          uint                   `json:"id"`
+	Name        string                 `json:"name"`
+	OwnerID     uuid.UUID              `json:"ownerId"`
+	Members     []HouseholdMemberEntry `json:"members"`
+	Enrollments []models.Enrollment    `json:"enrollments"`
+}
+
+// HouseholdBookingEntry describes a booking a member made on an enrollment of
+// the household owner.
+type HouseholdBookingEntry struct {
+	BookingID    uint                 `json:"bookingId"`
+	EnrollmentID uint                 `json:"enrollmentId"`
+	UserID       uuid.UUID            `json:"userId"`
+	Name         string               `json:"name"`
+	SessionID    uint                 `json:"sessionId"`
+	CourseTitle  string               `json:"courseTitle"`
+	ScheduledAt  time.Time            `json:"scheduledAt"`
+	Status       models.BookingStatus `json:"status"`
+}
+
+// CreateHouseholdRequest defines the request body for creating a household.
+type CreateHouseholdRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// CreateHousehold godoc
+// @Summary Create a household (Student/Admin only)
+// @Description Create a household owned by the current user. Members added to it can book sessions on the owner's enrollments, which the owner pays for.
+// @Tags Households
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param household body CreateHouseholdRequest true "Household details"
+// @Success 201 {object} HouseholdResponse
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 409 {object} map[string]string "error: Household already exists"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /households [post]
+func (h *HouseholdHandler) CreateHousehold(c *gin.Context) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	ownerID := uuid.MustParse(userIDAny.(string))
+
+	var req CreateHouseholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if h.DB.Unscoped().Where("owner_id = ?", ownerID).First(&models.Household{}).Error == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "You already have a household"})
+		return
+	}
+
+	household := models.Household{Name: req.Name, OwnerID: ownerID}
+	if err := h.DB.Create(&household).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create household"})
+		return
+	}
+
+	h.respondHousehold(c, http.StatusCreated, &household)
+}
+
+// GetMyHousehold godoc
+// @Summary Get the current user's household (Student/Admin only)
+// @Description Retrieve the household the current user owns or else belongs to, with its members and the owner's current enrollments.
+// @Tags Households
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} HouseholdResponse
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 404 {object} map[string]string "error: Household not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /households/me [get]
+func (h *HouseholdHandler) GetMyHousehold(c *gin.Context) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	userID := uuid.MustParse(userIDAny.(string))
+
+	var household models.Household
+	err := h.DB.Where("owner_id = ?", userID).First(&household).Error
+	if err == gorm.ErrRecordNotFound {
+		err = h.DB.Where("id = (SELECT household_id FROM household_members WHERE user_id = ? AND deleted_at IS NULL)", userID).
+			First(&household).Error
+	}
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "You are not in a household"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch household"})
+		return
+	}
+
+	h.respondHousehold(c, http.StatusOK, &household)
+}
+
+// AddHouseholdMemberRequest defines the request body for adding a member.
+type AddHouseholdMemberRequest struct {
+	Phone        string `json:"phone" binding:"required"`
+	SessionLimit int    `json:"sessionLimit" binding:"min=0"` // Zero means unlimited
+}
+
+// AddHouseholdMember godoc
+// @Summary Invite a member to the current user's household (Student/Admin only)
+// @Description Invite a registered user to the household by phone number. The user is notified and, once they accept, is listed as a member and can book sessions on the owner's enrollments, up to their session limit on each enrollment. A user belongs to at most one household. The response is the same whether or not the number belongs to a user who can be invited.
+// @Tags Households
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param member body AddHouseholdMemberRequest true "Member details"
+// @Success 202 {object} map[string]string "message: Invitation sent"
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 404 {object} map[string]string "error: Household not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /households/me/members [post]
+func (h *HouseholdHandler) AddHouseholdMember(c *gin.Context) {
+	household, ok := h.ownHousehold(c)
+	if !ok {
+		return
+	}
+
+	var req AddHouseholdMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var owner models.User
+	if err := h.DB.First(&owner, "id = ?", household.OwnerID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+	if req.Phone == owner.Phone {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot add yourself to your household"})
+		return
+	}
+
+	// Numbers without an account, or of users in a household already, get
+	// the same response, so invitations don't tell who has an account
+	var user models.User
+	err := h.DB.Where("phone = ?", req.Phone).First(&user).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+	if err == nil {
+		if err := h.invite(household, &user, req.SessionLimit); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add household member"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Invitation sent"})
+}
+
+// invite adds the user to the household, pending until they accept, and
+// notifies them. Users in a household already are left alone.
+func (h *HouseholdHandler) invite(household *models.Household, user *models.User, sessionLimit int) error {
+	member := models.HouseholdMember{HouseholdID: household.ID, UserID: user.ID, SessionLimit: sessionLimit}
+	if err := h.DB.Create(&member).Error; err != nil {
+		if isDuplicate(h.DB, err) {
+			return nil
+		}
+		return err
+	}
+
+	message := fmt.Sprintf("You were invited to join the %s household and book sessions on its enrollments. Accept the invitation to join, or decline it by leaving the household.", household.Name)
+	if err := h.Notifier.Notify(*user, message); err != nil {
+		log.Printf("failed to notify user %s of household %d: %v", user.ID, household.ID, err)
+	}
+	return nil
+}
+
+// AcceptHouseholdInvitation godoc
+// @Summary Accept a household invitation (Student/Admin only)
+// @Description Join the household the current user was invited to, so they can book sessions on its owner's enrollments. Invitations are declined by leaving the household.
+// @Tags Households
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} HouseholdResponse
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 404 {object} map[string]string "error: No pending invitation"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /households/me/accept [post]
+func (h *HouseholdHandler) AcceptHouseholdInvitation(c *gin.Context) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	userID := uuid.MustParse(userIDAny.(string))
+
+	var member models.HouseholdMember
+	if err := h.DB.Where("user_id = ? AND joined_at IS NULL", userID).First(&member).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "You have no pending household invitation"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch household invitation"})
+		return
+	}
+	if err := h.DB.Model(&member).Update("joined_at", time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept household invitation"})
+		return
+	}
+
+	var household models.Household
+	if err := h.DB.First(&household, member.HouseholdID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch household"})
+		return
+	}
+	h.respondHousehold(c, http.StatusOK, &household)
+}
+
+// UpdateHouseholdMemberRequest defines the request body for changing a
+// member's session limit.
+type UpdateHouseholdMemberRequest struct {
+	SessionLimit *int `json:"sessionLimit" binding:"required,min=0"` // Zero means unlimited
+}
+
+// UpdateHouseholdMember godoc
+// @Summary Change a household member's session limit (Student/Admin only)
+// @Description Change how many sessions a member of the current user's household can book on each of the owner's enrollments.
+// @Tags Households
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param userId path string true "Member user ID"
+// @Param member body UpdateHouseholdMemberRequest true "Session limit"
+// @Success 200 {object} HouseholdMemberEntry
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 404 {object} map[string]string "error: Household or member not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /households/me/members/{userId} [put]
+func (h *HouseholdHandler) UpdateHouseholdMember(c *gin.Context) {
+	household, ok := h.ownHousehold(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateHouseholdMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	memberID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var member models.HouseholdMember
+	if err := h.DB.Preload("User.Profile").Where("household_id = ? AND user_id = ?", household.ID, memberID).
+		First(&member).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Household member not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch household member"})
+		return
+	}
+
+	if err := h.DB.Model(&member).Update("session_limit", *req.SessionLimit).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update household member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, householdMemberEntry(&member))
+}
+
+// RemoveHouseholdMember godoc
+// @Summary Remove a member from a household (Student/Admin only)
+// @Description The household owner removes a member, or a member leaves their household by removing themselves. Sessions the member already booked stay booked.
+// @Tags Households
+// @Security BearerAuth
+// @Param userId path string true "Member user ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 404 {object} map[string]string "error: Member not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /households/me/members/{userId} [delete]
+func (h *HouseholdHandler) RemoveHouseholdMember(c *gin.Context) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	currentUserID := uuid.MustParse(userIDAny.(string))
+
+	memberID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	query := h.DB.Where("user_id = ?", memberID)
+	if memberID != currentUserID {
+		query = query.Where("household_id = (SELECT id FROM households WHERE owner_id = ? AND deleted_at IS NULL)", currentUserID)
+	}
+	// Members are removed for good so that they can join another household
+	result := query.Unscoped().Delete(&models.HouseholdMember{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove household member"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Household member not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetHouseholdBookings godoc
+// @Summary Get the bookings of household members (Student/Admin only)
+// @Description The household owner lists the sessions members booked on the owner's enrollments, newest first.
+// @Tags Households
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} HouseholdBookingEntry
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 404 {object} map[string]string "error: Household not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /households/me/bookings [get]
+func (h *HouseholdHandler) GetHouseholdBookings(c *gin.Context) {
+	household, ok := h.ownHousehold(c)
+	if !ok {
+		return
+	}
+
+	var bookings []models.Booking
+	if err := h.DB.Preload("User.Profile").Preload("CourseSession.Course").
+		Where("enrollment_id IN (SELECT id FROM enrollments WHERE user_id = ?) AND user_id <> ?", household.OwnerID, household.OwnerID).
+		Order("id DESC").Find(&bookings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch household bookings"})
+		return
+	}
+
+	entries := make([]HouseholdBookingEntry, len(bookings))
+	for i, booking := range bookings {
+		entries[i] = HouseholdBookingEntry{
+			BookingID:    booking.ID,
+			EnrollmentID: booking.EnrollmentID,
+			UserID:       booking.UserID,
+			Name:         booking.User.Profile.Name,
+			SessionID:    booking.CourseSessionID,
+			CourseTitle:  booking.CourseSession.Course.Title,
+			ScheduledAt:  booking.CourseSession.ScheduledAt,
+			Status:       booking.Status,
+		}
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// ownHousehold fetches the household owned by the current user, writing the
+// error response and returning false if there is none.
+func (h *HouseholdHandler) ownHousehold(c *gin.Context) (*models.Household, bool) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return nil, false
+	}
+
+	var household models.Household
+	if err := h.DB.Where("owner_id = ?", uuid.MustParse(userIDAny.(string))).First(&household).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "You do not own a household"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch household"})
+		return nil, false
+	}
+	return &household, true
+}
+
+// respondHousehold writes the household with the members who joined it and
+// the owner's current enrollments. Pending invitations are left out, they
+// would tell who has an account.
+func (h *HouseholdHandler) respondHousehold(c *gin.Context, status int, household *models.Household) {
+	var members []models.HouseholdMember
+	if err := h.DB.Preload("User.Profile").Where("household_id = ? AND joined_at IS NOT NULL", household.ID).Order("id").Find(&members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch household members"})
+		return
+	}
+
+	var enrollments []models.Enrollment
+	if err := h.DB.Preload("Course").Where("user_id = ? AND status = ?", household.OwnerID, models.EnrollmentActive).
+		Order("expiration_date").Find(&enrollments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch household enrollments"})
+		return
+	}
+	refs := make([]*models.Enrollment, len(enrollments))
+	for i := range enrollments {
+		refs[i] = &enrollments[i]
+	}
+	if err := loadBalances(h.DB, refs...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute enrollment balances"})
+		return
+	}
+
+	response := HouseholdResponse{
+		ID:          household.ID,
+		Name:        household.Name,
+		OwnerID:     household.OwnerID,
+		Members:     make([]HouseholdMemberEntry, len(members)),
+		Enrollments: enrollments,
+	}
+	for i := range members {
+		response.Members[i] = householdMemberEntry(&members[i])
+	}
+
+	c.JSON(status, response)
+}
+
+// householdMemberEntry describes the member, whose user must be loaded.
+func householdMemberEntry(member *models.HouseholdMember) HouseholdMemberEntry {
+	return HouseholdMemberEntry{
+		UserID:       member.UserID,
+		Name:         member.User.Profile.Name,
+		Phone:        member.User.Phone,
+		SessionLimit: member.SessionLimit,
+	}
+}
+
+// enrollmentHolders returns the users whose enrollments the user can book
+// sessions on: the user and the owner of the household they joined.
+func enrollmentHolders(tx *gorm.DB, userID uuid.UUID) ([]uuid.UUID, error) {
+	var owners []uuid.UUID
+	if err := tx.Model(&models.Household{}).
+		Where("id = (SELECT household_id FROM household_members WHERE user_id = ? AND joined_at IS NOT NULL AND deleted_at IS NULL)", userID).
+		Pluck("owner_id", &owners).Error; err != nil {
+		return nil, err
+	}
+	return append([]uuid.UUID{userID}, owners...), nil
+}
+
+// householdMember returns the membership of the user in the household owned
+// by ownerID, or nil when they are not a member or only invited.
+func householdMember(tx *gorm.DB, userID, ownerID uuid.UUID) (*models.HouseholdMember, error) {
+	var member models.HouseholdMember
+	err := tx.Where("user_id = ? AND joined_at IS NOT NULL AND household_id = (SELECT id FROM households WHERE owner_id = ? AND deleted_at IS NULL)", userID, ownerID).
+		First(&member).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// withinMemberLimit reports whether the user can book one more session on
+// the enrollment. Its owner always can, household members up to their
+// session limit.
+func withinMemberLimit(tx *gorm.DB, userID uuid.UUID, enrollment *models.Enrollment) (bool, error) {
+	if enrollment.UserID == userID {
+		return true, nil
+	}
+	member, err := householdMember(tx, userID, enrollment.UserID)
+	if err != nil || member == nil {
+		return false, err
+	}
+	if member.SessionLimit == 0 {
+		return true, nil
+	}
+
+	var used int64
+	if err := tx.Model(&models.Booking{}).
+		Where("enrollment_id = ? AND user_id = ? AND status IN ?", enrollment.ID, userID,
+			[]models.BookingStatus{models.BookingBooked, models.BookingAttended, models.BookingNoShow}).
+		Count(&used).Error; err != nil {
+		return false, err
+	}
+	return int(used) < member.SessionLimit, nil
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/notify"
+	"yoga-guru/internal/payment"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHouseholdMembersBookOnOwnersEnrollment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	households := NewHouseholdHandler(db, notify.LogNotifier{})
+	sessions := NewSessionHandler(db, &config.Config{JWTSecret: "secret"}, notify.LogNotifier{})
+	enrollments := NewEnrollmentHandler(db, &config.Config{}, notify.LogNotifier{}, payment.NewFakeGateway())
+
+	course := models.Course{Title: "Kids yoga", Capacity: 10}
+	db.Create(&course)
+	var parent, child, stranger models.User
+	for i, user := range []*models.User{&parent, &child, &stranger} {
+		*user = models.User{Phone: fmt.Sprintf("+98912000000%d", i), Role: models.Student}
+		db.Create(user)
+	}
+	enrollment := models.Enrollment{
+		UserID:         parent.ID,
+		CourseID:       course.ID,
+		EnrollmentType: models.Monthly,
+		Status:         models.EnrollmentActive,
+		StartDate:      time.Now(),
+		ExpirationDate: time.Now().AddDate(0, 1, 0),
+	}
+	db.Create(&enrollment)
+
+	router := func(user models.User) *gin.Engine {
+		r := gin.New()
+		r.Use(withUser(user.ID, models.Student))
+		r.POST("/households", households.CreateHousehold)
+		r.GET("/households/me", households.GetMyHousehold)
+		r.POST("/households/me/members", households.AddHouseholdMember)
+		r.POST("/households/me/accept", households.AcceptHouseholdInvitation)
+		r.GET("/households/me/bookings", households.GetHouseholdBookings)
+		r.POST("/sessions/:id/bookings", sessions.BookSession)
+		r.GET("/enrollments/:id", enrollments.GetEnrollmentByID)
+		return r
+	}
+	serve := func(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rr
+	}
+	book := func(r *gin.Engine) int {
+		session := models.CourseSession{CourseID: course.ID, ScheduledAt: time.Now().Add(24 * time.Hour)}
+		db.Create(&session)
+		return serve(r, http.MethodPost, fmt.Sprintf("/sessions/%d/bookings", session.ID), "").Code
+	}
+
+	parentRouter, childRouter, strangerRouter := router(parent), router(child), router(stranger)
+	if rr := serve(parentRouter, http.MethodPost, "/households", `{"name": "Family"}`); rr.Code != http.StatusCreated {
+		t.Fatalf("creating household: got status %d: %s", rr.Code, rr.Body)
+	}
+	body := fmt.Sprintf(`{"phone": %q, "sessionLimit": 1}`, child.Phone)
+	if rr := serve(parentRouter, http.MethodPost, "/households/me/members", body); rr.Code != http.StatusAccepted {
+		t.Fatalf("adding member: got status %d: %s", rr.Code, rr.Body)
+	}
+	// Inviting a number without an account looks the same
+	if rr := serve(parentRouter, http.MethodPost, "/households/me/members", `{"phone": "+989129999999"}`); rr.Code != http.StatusAccepted {
+		t.Errorf("inviting an unknown number: got status %d: %s", rr.Code, rr.Body)
+	}
+
+	// The invited member books only after accepting
+	if code := book(childRouter); code != http.StatusForbidden {
+		t.Errorf("invited member booking: got status %d", code)
+	}
+	if rr := serve(strangerRouter, http.MethodPost, "/households/me/accept", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("accepting without an invitation: got status %d", rr.Code)
+	}
+	members := func() int {
+		var household HouseholdResponse
+		if err := json.Unmarshal(serve(parentRouter, http.MethodGet, "/households/me", "").Body.Bytes(), &household); err != nil {
+			t.Fatal(err)
+		}
+		return len(household.Members)
+	}
+	if n := members(); n != 0 {
+		t.Errorf("got %d members before the invitation was accepted, want none", n)
+	}
+	if rr := serve(childRouter, http.MethodPost, "/households/me/accept", ""); rr.Code != http.StatusOK {
+		t.Fatalf("accepting the invitation: got status %d: %s", rr.Code, rr.Body)
+	}
+	if n := members(); n != 1 {
+		t.Errorf("got %d members after the invitation was accepted, want 1", n)
+	}
+
+	if code := book(childRouter); code != http.StatusCreated {
+		t.Fatalf("member booking: got status %d", code)
+	}
+	if code := book(childRouter); code != http.StatusConflict {
+		t.Errorf("member booking past their limit: got status %d", code)
+	}
+	if code := book(strangerRouter); code != http.StatusForbidden {
+		t.Errorf("non-member booking: got status %d", code)
+	}
+	if code := book(parentRouter); code != http.StatusCreated {
+		t.Errorf("owner booking: got status %d", code)
+	}
+
+	path := fmt.Sprintf("/enrollments/%d", enrollment.ID)
+	if code := serve(childRouter, http.MethodGet, path, "").Code; code != http.StatusOK {
+		t.Errorf("member viewing the enrollment: got status %d", code)
+	}
+	if code := serve(strangerRouter, http.MethodGet, path, "").Code; code != http.StatusForbidden {
+		t.Errorf("non-member viewing the enrollment: got status %d", code)
+	}
+
+	rr := serve(parentRouter, http.MethodGet, "/households/me/bookings", "")
+	var bookings []HouseholdBookingEntry
+	if err := json.Unmarshal(rr.Body.Bytes(), &bookings); err != nil {
+		t.Fatal(err)
+	}
+	if len(bookings) != 1 || bookings[0].UserID != child.ID || bookings[0].EnrollmentID != enrollment.ID {
+		t.Errorf("got household bookings %+v, want the member's booking", bookings)
+	}
+}
@@ -0,0 +1,255 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"yoga-guru/internal/invoice"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/money"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errInvoiceIssued is returned when an invoice was issued concurrently.
+var errInvoiceIssued = errors.New("invoice already issued")
+
+// GetInvoicePDF godoc
+// @Summary Download the invoice of an enrollment (Staff/Admin/Enrolled Student only)
+// @Description Render the invoice of an enrollment as PDF, with the studio's details, the enrollment as line item and its discount and tax. The invoice is numbered the first time it is downloaded, sequentially and without gaps, and keeps its number and amounts from then on.
+// @Tags Payments
+// @Security BearerAuth
+// @Produce application/pdf
+// @Param id path int true "Enrollment ID"
+// @Success 200 {file} binary "Invoice PDF"
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Enrollment not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /enrollments/{id}/invoice.pdf [get]
+func (h *PaymentHandler) GetInvoicePDF(c *gin.Context) {
+	enrollment, ok := h.accessibleEnrollment(c)
+	if !ok {
+		return
+	}
+	if err := h.DB.Preload("User.Profile").Preload("Course").Preload("PricePlan").First(enrollment, enrollment.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch enrollment"})
+		return
+	}
+
+	subtotal, discount := enrollment.NetPrice, money.Amount(0)
+	if enrollment.DiscountApplied > 0 && enrollment.DiscountApplied < 1 {
+		subtotal = enrollment.NetPrice.Mul(1 / (1 - enrollment.DiscountApplied))
+		discount = subtotal - enrollment.NetPrice
+	}
+	issued, err := issueInvoice(h.DB, h.Cfg.Studio.InvoicePrefix, &models.Invoice{
+		Kind:         models.InvoiceKindInvoice,
+		EnrollmentID: enrollment.ID,
+		Currency:     enrollment.Currency,
+		Subtotal:     subtotal,
+		Discount:     discount,
+		Tax:          enrollment.Tax,
+		TaxRate:      enrollment.TaxRate,
+		Total:        enrollment.PricePaid,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue invoice"})
+		return
+	}
+
+	plan := string(enrollment.EnrollmentType)
+	if enrollment.PricePlan != nil {
+		plan = enrollment.PricePlan.Name
+	}
+	doc := h.document(issued, &enrollment.User)
+	doc.Title = "Invoice"
+	doc.Lines = []invoice.Line{{
+		Description: fmt.Sprintf("%s, %s (%s to %s)", enrollment.Course.Title, plan,
+			enrollment.StartDate.Format("2006-01-02"), enrollment.ExpirationDate.Format("2006-01-02")),
+		Quantity:  1,
+		UnitPrice: issued.Subtotal,
+		Amount:    issued.Subtotal,
+	}}
+	doc.Notes = []string{fmt.Sprintf("Paid %s, balance due %s.",
+		doc.FormatAmount(enrollment.PricePaid-enrollment.Balance), doc.FormatAmount(enrollment.Balance))}
+
+	h.respondPDF(c, "invoice", doc)
+}
+
+// GetReceiptPDF godoc
+// @Summary Download the receipt of a payment (Staff/Admin/Enrolled Student only)
+// @Description Render the receipt of a succeeded payment as PDF. The receipt is numbered the first time it is downloaded, sequentially and without gaps.
+// @Tags Payments
+// @Security BearerAuth
+// @Produce application/pdf
+// @Param id path int true "Enrollment ID"
+// @Param paymentID path int true "Payment ID"
+// @Success 200 {file} binary "Receipt PDF"
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Payment not found"
+// @Failure 409 {object} map[string]string "error: Payment not succeeded"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /enrollments/{id}/payments/{paymentID}/receipt.pdf [get]
+func (h *PaymentHandler) GetReceiptPDF(c *gin.Context) {
+	enrollment, ok := h.accessibleEnrollment(c)
+	if !ok {
+		return
+	}
+	payment, ok := h.enrollmentPayment(c, enrollment.ID)
+	if !ok {
+		return
+	}
+	if payment.Status != models.PaymentSucceeded {
+		c.JSON(http.StatusConflict, gin.H{"error": "Receipts are only issued for succeeded payments"})
+		return
+	}
+	if err := h.DB.Preload("User.Profile").Preload("Course").First(enrollment, enrollment.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch enrollment"})
+		return
+	}
+
+	issued, err := issueInvoice(h.DB, h.Cfg.Studio.InvoicePrefix, &models.Invoice{
+		Kind:         models.InvoiceKindReceipt,
+		EnrollmentID: enrollment.ID,
+		Currency:     payment.Currency,
+		PaymentID:    &payment.ID,
+		Subtotal:     payment.NetAmount,
+		Tax:          payment.Tax,
+		TaxRate:      enrollment.TaxRate,
+		Total:        payment.Amount,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue receipt"})
+		return
+	}
+
+	doc := h.document(issued, &enrollment.User)
+	doc.Title = "Receipt"
+	doc.Lines = []invoice.Line{{
+		Description: fmt.Sprintf("Payment for enrollment %d in %s", enrollment.ID, enrollment.Course.Title),
+		Quantity:    1,
+		UnitPrice:   issued.Subtotal,
+		Amount:      issued.Subtotal,
+	}}
+	doc.Notes = []string{
+		fmt.Sprintf("Paid by %s on %s.", strings.ReplaceAll(string(payment.Method), "_", " "), payment.PaymentDate.Format("2006-01-02")),
+		"Transaction: " + payment.TransactionID,
+	}
+
+	h.respondPDF(c, "receipt", doc)
+}
+
+// document fills the parts common to invoices and receipts.
+func (h *PaymentHandler) document(issued *models.Invoice, customer *models.User) *invoice.Document {
+	billTo := []string{customer.Phone}
+	if customer.Profile.Name != "" {
+		billTo = append([]string{customer.Profile.Name}, billTo...)
+	}
+	return &invoice.Document{
+		Number:   invoiceNumber(issued),
+		IssuedAt: issued.IssuedAt,
+		Studio:   h.Cfg.Studio,
+		BillTo:   billTo,
+		Subtotal: issued.Subtotal,
+		Discount: issued.Discount,
+		Tax:      issued.Tax,
+		TaxRate:  issued.TaxRate,
+		Total:    issued.Total,
+		FormatAmount: func(amount money.Amount) string {
+			return h.Cfg.FormatMoney(amount, issued.Currency)
+		},
+	}
+}
+
+// respondPDF renders the document as a PDF download.
+func (h *PaymentHandler) respondPDF(c *gin.Context, name string, doc *invoice.Document) {
+	var buf bytes.Buffer
+	if err := invoice.Render(&buf, doc); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render " + name})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.pdf"`, name, doc.Number))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// invoiceNumber formats the number of an invoice or receipt, like
+// "YG-INV-000042".
+func invoiceNumber(issued *models.Invoice) string {
+	kind := "INV"
+	if issued.Kind == models.InvoiceKindReceipt {
+		kind = "RCP"
+	}
+	return fmt.Sprintf("%s-%s-%06d", issued.Series, kind, issued.Number)
+}
+
+// issueInvoice returns the invoice of the draft's enrollment, or receipt of
+// its payment. The first time, the draft is issued with the next number of
+// the series.
+func issueInvoice(db *gorm.DB, series string, draft *models.Invoice) (*models.Invoice, error) {
+	if issued, err := findInvoice(db, draft); err != nil || issued != nil {
+		return issued, err
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Advancing the sequence first takes the write lock, so that issues
+		// are serialized and a rolled back issue leaves no gap
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.InvoiceSequence{Kind: draft.Kind, Series: series, Next: 1}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.InvoiceSequence{}).Where("kind = ? AND series = ?", draft.Kind, series).
+			Update("next", gorm.Expr("next + 1")).Error; err != nil {
+			return err
+		}
+
+		issued, err := findInvoice(tx, draft)
+		if err != nil {
+			return err
+		}
+		if issued != nil {
+			*draft = *issued
+			return errInvoiceIssued
+		}
+
+		var sequence models.InvoiceSequence
+		if err := tx.Where("kind = ? AND series = ?", draft.Kind, series).First(&sequence).Error; err != nil {
+			return err
+		}
+		draft.Series = series
+		draft.Number = sequence.Next - 1
+		draft.IssuedAt = time.Now()
+		return tx.Create(draft).Error
+	})
+	if err != nil && err != errInvoiceIssued {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// findInvoice returns the issued invoice or receipt the draft is for, or nil
+// when there is none yet.
+func findInvoice(tx *gorm.DB, draft *models.Invoice) (*models.Invoice, error) {
+	query := tx.Where("kind = ?", draft.Kind)
+	if draft.PaymentID != nil {
+		query = query.Where("payment_id = ?", *draft.PaymentID)
+	} else {
+		query = query.Where("enrollment_id = ?", draft.EnrollmentID)
+	}
+
+	var issued models.Invoice
+	if err := query.First(&issued).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &issued, nil
+}
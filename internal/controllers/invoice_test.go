@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/money"
+	"yoga-guru/internal/payment"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestInvoicesAreNumberedOnce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	cfg := &config.Config{Currency: money.IRR, DisplayCurrency: money.IRT, Studio: config.StudioDetails{Name: "Yoga Guru", Address: "1 Main St", InvoicePrefix: "YG"}}
+	h := NewPaymentHandler(db, cfg, payment.NewFakeGateway())
+
+	admin := models.User{Phone: "+989120000000", Role: models.Admin}
+	db.Create(&admin)
+	course := models.Course{Title: "Hatha"}
+	db.Create(&course)
+	enrollments := make([]models.Enrollment, 2)
+	for i := range enrollments {
+		student := models.User{Phone: fmt.Sprintf("+98912000001%d", i), Role: models.Student}
+		db.Create(&student)
+		enrollments[i] = models.Enrollment{UserID: student.ID, CourseID: course.ID, EnrollmentType: models.Monthly,
+			StartDate: time.Now(), ExpirationDate: time.Now().AddDate(0, 1, 0), Currency: money.IRR, PricePaid: 981000, NetPrice: 900000, Tax: 81000, TaxRate: 0.09, DiscountApplied: 0.1}
+		db.Create(&enrollments[i])
+	}
+	paid := models.Payment{EnrollmentID: enrollments[0].ID, Amount: 981000, Status: models.PaymentSucceeded,
+		Method: models.Cash, TransactionID: uuid.NewString(), PaymentDate: time.Now()}
+	pending := models.Payment{EnrollmentID: enrollments[0].ID, Amount: 981000, Status: models.PaymentPending,
+		Method: models.OnlinePayment, TransactionID: uuid.NewString(), PaymentDate: time.Now()}
+	db.Create(&paid)
+	db.Create(&pending)
+
+	r := gin.New()
+	r.Use(withUser(admin.ID, models.Admin))
+	r.GET("/enrollments/:id/invoice.pdf", h.GetInvoicePDF)
+	r.GET("/enrollments/:id/payments/:paymentID/receipt.pdf", h.GetReceiptPDF)
+	download := func(path string, want int) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		if rr.Code != want {
+			t.Fatalf("GET %s: got status %d, want %d: %s", path, rr.Code, want, rr.Body)
+		}
+		if want == http.StatusOK && !bytes.HasPrefix(rr.Body.Bytes(), []byte("%PDF")) {
+			t.Errorf("GET %s: got %q, want a PDF", path, rr.Body.Bytes()[:min(rr.Body.Len(), 16)])
+		}
+	}
+
+	for _, i := range []int{1, 0, 1} {
+		download(fmt.Sprintf("/enrollments/%d/invoice.pdf", enrollments[i].ID), http.StatusOK)
+	}
+	download(fmt.Sprintf("/enrollments/%d/payments/%d/receipt.pdf", enrollments[0].ID, pending.ID), http.StatusConflict)
+	download(fmt.Sprintf("/enrollments/%d/payments/%d/receipt.pdf", enrollments[0].ID, paid.ID), http.StatusOK)
+
+	var invoices []models.Invoice
+	db.Order("kind, number").Find(&invoices)
+	want := []struct {
+		kind         models.InvoiceKind
+		number       int
+		enrollmentID uint
+	}{
+		{models.InvoiceKindInvoice, 1, enrollments[1].ID},
+		{models.InvoiceKindInvoice, 2, enrollments[0].ID},
+		{models.InvoiceKindReceipt, 1, enrollments[0].ID},
+	}
+	if len(invoices) != len(want) {
+		t.Fatalf("got %d invoices, want %d", len(invoices), len(want))
+	}
+	for i, w := range want {
+		got := invoices[i]
+		if got.Kind != w.kind || got.Number != w.number || got.EnrollmentID != w.enrollmentID || got.Series != "YG" {
+			t.Errorf("invoice %d: got %s %s-%d of enrollment %d, want %s %d of enrollment %d",
+				i, got.Kind, got.Series, got.Number, got.EnrollmentID, w.kind, w.number, w.enrollmentID)
+		}
+	}
+	if got := invoices[0]; got.Subtotal != 1000000 || got.Discount != 100000 || got.Tax != 81000 || got.Total != 981000 {
+		t.Errorf("got invoice amounts %v - %v + %v = %v, want 1000000 - 100000 + 81000 = 981000", got.Subtotal, got.Discount, got.Tax, got.Total)
+	}
+	if got := invoices[2]; got.Subtotal != 900000 || got.Tax != 81000 || got.Total != 981000 || got.Currency != money.IRR {
+		t.Errorf("got receipt amounts %v + %v = %v %s, want 900000 + 81000 = 981000 IRR", got.Subtotal, got.Tax, got.Total, got.Currency)
+	}
+}
@@ -0,0 +1,258 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// OTPRequest defines the request body for requesting a one-time login code.
+type OTPRequest struct {
+	Phone string `json:"phone" binding:"required,e164"`
+}
+
+// RequestOTP godoc
+// @Summary Text a one-time login code
+// @Description Send a one-time code to the phone number to log in or sign up with. The response is the same whether or not the number belongs to a user. Codes are limited per number per day, and every request counts against the failed login limit of the client IP.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body OTPRequest true "Phone number"
+// @Success 202 {object} map[string]interface{} "message, expiresIn in seconds"
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 429 {object} map[string]string "error: A code was sent recently or too often, or too many failed logins"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /otp/request [post]
+func (h *AuthHandler) RequestOTP(c *gin.Context) {
+	var req OTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	code, ok := h.newCode(c, req.Phone, models.OTPLogin)
+	if !ok || !h.textCode(c, req.Phone, "login", code) {
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Code sent", "expiresIn": int(h.Cfg.OTP.TTL.Seconds())})
+}
+
+// VerifyOTPRequest defines the request body for logging in with a one-time
+// code. Name and gender fill in the profile of users signing up this way.
+type VerifyOTPRequest struct {
+	Phone  string `json:"phone" binding:"required,e164"`
+	Code   string `json:"code" binding:"required,len=6,numeric"`
+	Name   string `json:"name"`
+	Gender string `json:"gender" binding:"omitempty,oneof=male female"`
+}
+
+var (
+	errOTPInvalid  = errors.New("invalid or expired code")
+	errOTPAttempts = errors.New("too many attempts")
+)
+
+// VerifyOTP godoc
+// @Summary Log in with a one-time code
+// @Description Log in with a code texted by /otp/request, returning a JWT token. Phone numbers without an account are signed up as students.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body VerifyOTPRequest true "Phone number and code"
+// @Success 200 {object} map[string]string "token: JWT_TOKEN, refresh: REFRESH_TOKEN"
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Invalid or expired code"
+// @Failure 429 {object} map[string]string "error: Too many attempts or failed logins"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /otp/verify [post]
+func (h *AuthHandler) VerifyOTP(c *gin.Context) {
+	var req VerifyOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if h.loginBlocked(c, req.Phone, c.ClientIP()) {
+		return
+	}
+
+	now := time.Now()
+	var user models.User
+	err := h.verifyOTP(req.Phone, req.Code, models.OTPLogin, now)
+	// Wrong codes count as failed logins of the phone across the codes sent
+	// to it, and from the IP across phones
+	if errors.Is(err, errOTPInvalid) || errors.Is(err, errOTPAttempts) {
+		if err := h.recordLoginFailure(req.Phone, c.ClientIP()); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+			return
+		}
+	}
+	if err == nil {
+		err = h.clearLoginFailures(req.Phone)
+	}
+	if err == nil {
+		err = h.DB.Transaction(func(tx *gorm.DB) error {
+			err := tx.Where("phone = ?", req.Phone).First(&user).Error
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			user = models.User{
+				Phone: req.Phone,
+				Role:  models.Student,
+				Profile: models.Profile{
+					Name:   req.Name,
+					Gender: models.UserGender(req.Gender),
+				},
+			}
+			return tx.Create(&user).Error
+		})
+	}
+	switch {
+	case errors.Is(err, errOTPInvalid):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired code"})
+		return
+	case errors.Is(err, errOTPAttempts):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many attempts, request a new code"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		return
+	}
+
+	token, refresh, err := h.startSession(c, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"token": token, "refresh": refresh, "role": user.Role})
+}
+
+// newCode stores a new one-time code for purpose sent to phone, replacing
+// earlier unused ones, and returns it. Every code requested counts against
+// the login throttle of the client IP, so texts can't be sent to numbers
+// en masse. It responds with an error and returns false when the phone or
+// IP is throttled, a code was sent too recently or too often today, or the
+// code can't be stored.
+func (h *AuthHandler) newCode(c *gin.Context, phone string, purpose models.OTPPurpose) (string, bool) {
+	if h.loginBlocked(c, phone, c.ClientIP()) {
+		return "", false
+	}
+	if err := h.countLoginFailure(h.DB, models.ThrottleIP, c.ClientIP(), h.Cfg.Login.MaxFailuresPerIP); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send code"})
+		return "", false
+	}
+
+	now := time.Now()
+	var recent int64
+	if err := h.DB.Model(&models.OneTimeCode{}).
+		Where("phone = ? AND purpose = ? AND created_at > ?", phone, purpose, now.Add(-h.Cfg.OTP.ResendInterval)).
+		Count(&recent).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send code"})
+		return "", false
+	}
+	if recent > 0 {
+		c.Header("Retry-After", fmt.Sprintf("%.0f", h.Cfg.OTP.ResendInterval.Seconds()))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "A code was sent recently, please wait before requesting another"})
+		return "", false
+	}
+	if h.Cfg.OTP.MaxPerDay > 0 {
+		// Replaced codes are deleted, but still count
+		var today int64
+		if err := h.DB.Model(&models.OneTimeCode{}).Unscoped().
+			Where("phone = ? AND created_at > ?", phone, now.Add(-24*time.Hour)).
+			Count(&today).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send code"})
+			return "", false
+		}
+		if today >= int64(h.Cfg.OTP.MaxPerDay) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many codes were sent to this number today, please try again tomorrow"})
+			return "", false
+		}
+	}
+
+	code := newOTPCode()
+	hash, err := utils.HashPassword(code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send code"})
+		return "", false
+	}
+
+	// Only the latest code sent to a phone can be used
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("phone = ? AND purpose = ? AND used_at IS NULL", phone, purpose).Delete(&models.OneTimeCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.OneTimeCode{Phone: phone, Purpose: purpose, CodeHash: hash, ExpiresAt: now.Add(h.Cfg.OTP.TTL)}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send code"})
+		return "", false
+	}
+	return code, true
+}
+
+// textCode texts a one-time code to phone, saying what it is for. It
+// responds with an error and returns false when the message can't be sent.
+func (h *AuthHandler) textCode(c *gin.Context, phone, what, code string) bool {
+	message := fmt.Sprintf("Your %s %s code is %s. It expires in %d minutes.", h.Cfg.Studio.Name, what, code, int(h.Cfg.OTP.TTL.Minutes()))
+	if err := h.SMS.SendSMS(c.Request.Context(), phone, message); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send code"})
+		return false
+	}
+	return true
+}
+
+// verifyOTP checks code against the latest code for purpose sent to phone
+// and uses it up. Every check counts as an attempt, and the code is no
+// longer accepted once the attempts run out.
+func (h *AuthHandler) verifyOTP(phone, code string, purpose models.OTPPurpose, now time.Time) error {
+	var otp models.OneTimeCode
+	if err := h.DB.Where("phone = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", phone, purpose, now).
+		Order("id DESC").First(&otp).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errOTPInvalid
+		}
+		return err
+	}
+
+	// Count the attempt before checking the code, so concurrent guesses
+	// can't go over the limit
+	result := h.DB.Model(&otp).Where("attempts < ?", h.Cfg.OTP.MaxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errOTPAttempts
+	}
+	if !utils.CheckPasswordHash(code, otp.CodeHash) {
+		return errOTPInvalid
+	}
+
+	result = h.DB.Model(&otp).Where("used_at IS NULL").Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errOTPInvalid
+	}
+	return nil
+}
+
+// newOTPCode generates a random six digit code.
+func newOTPCode() string {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		panic(err) // crypto/rand never fails on supported platforms
+	}
+	return fmt.Sprintf("%06d", n.Int64())
+}
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/notify"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestOTPLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	sms := &notify.FileSMSSender{Path: filepath.Join(t.TempDir(), "sms.log")}
+	h := NewAuthHandler(db, &config.Config{
+		JWTSecret:       "secret",
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+		Studio:          config.StudioDetails{Name: "Yoga Guru"},
+		OTP:             config.OTPPolicy{TTL: 5 * time.Minute, MaxAttempts: 3, ResendInterval: time.Minute},
+	}, sms)
+
+	serve := func(path, body string, want int) *httptest.ResponseRecorder {
+		t.Helper()
+		r := gin.New()
+		r.POST("/otp/request", h.RequestOTP)
+		r.POST("/otp/verify", h.VerifyOTP)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		if rr.Code != want {
+			t.Fatalf("POST %s %s: got status %d, want %d: %s", path, body, rr.Code, want, rr.Body)
+		}
+		return rr
+	}
+	lastCode := func() string {
+		t.Helper()
+		sent, err := os.ReadFile(sms.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		codes := regexp.MustCompile(`code is (\d{6})`).FindAllStringSubmatch(string(sent), -1)
+		if len(codes) == 0 {
+			t.Fatalf("no code in %q", sent)
+		}
+		return codes[len(codes)-1][1]
+	}
+	wrong := func(code string) string {
+		if code == "000000" {
+			return "111111"
+		}
+		return "000000"
+	}
+
+	serve("/otp/request", `{"phone": "+989120000001"}`, http.StatusAccepted)
+	code := lastCode()
+
+	// Wrong codes use up the attempts, after which even the right one fails
+	serve("/otp/verify", `{"phone": "+989120000001", "code": "`+wrong(code)+`"}`, http.StatusUnauthorized)
+	serve("/otp/verify", `{"phone": "+989120000001", "code": "`+wrong(code)+`"}`, http.StatusUnauthorized)
+	serve("/otp/verify", `{"phone": "+989120000001", "code": "`+wrong(code)+`"}`, http.StatusUnauthorized)
+	serve("/otp/verify", `{"phone": "+989120000001", "code": "`+code+`"}`, http.StatusTooManyRequests)
+
+	// A new code replaces the old one and signs the phone up as a student
+	db.Model(&models.OneTimeCode{}).Where("1 = 1").Update("created_at", time.Now().Add(-time.Hour))
+	serve("/otp/request", `{"phone": "+989120000001"}`, http.StatusAccepted)
+	serve("/otp/request", `{"phone": "+989120000001"}`, http.StatusTooManyRequests)
+	code = lastCode()
+	rr := serve("/otp/verify", `{"phone": "+989120000001", "code": "`+code+`", "name": "Sara"}`, http.StatusOK)
+	var tokens struct {
+		Token   string
+		Refresh string
+		Role    models.UserRole
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &tokens); err != nil {
+		t.Fatal(err)
+	}
+	if tokens.Token == "" || tokens.Refresh == "" || tokens.Role != models.Student {
+		t.Errorf("got %+v, want a token pair for a student", tokens)
+	}
+	var user models.User
+	if err := db.Preload("Profile").Where("phone = ?", "+989120000001").First(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	if user.Profile.Name != "Sara" {
+		t.Errorf("got name %q, want Sara", user.Profile.Name)
+	}
+
+	// Codes can only be used once
+	serve("/otp/verify", `{"phone": "+989120000001", "code": "`+code+`"}`, http.StatusUnauthorized)
+}
+
+func TestOTPLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	sms := &notify.FileSMSSender{Path: filepath.Join(t.TempDir(), "sms.log")}
+	h := NewAuthHandler(db, &config.Config{
+		Studio: config.StudioDetails{Name: "Yoga Guru"},
+		OTP:    config.OTPPolicy{TTL: 5 * time.Minute, MaxAttempts: 3, ResendInterval: time.Minute, MaxPerDay: 2},
+		Login:  config.LoginPolicy{FreeAttempts: 10, MaxFailures: 4, MaxFailuresPerIP: 4, Lockout: time.Hour},
+	}, sms)
+
+	r := gin.New()
+	r.POST("/otp/request", h.RequestOTP)
+	r.POST("/otp/verify", h.VerifyOTP)
+	serve := func(ip, path, body string, want int) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.RemoteAddr = ip + ":1234"
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != want {
+			t.Fatalf("POST %s %s from %s: got status %d, want %d: %s", path, body, ip, rr.Code, want, rr.Body)
+		}
+	}
+	resendable := func() {
+		db.Unscoped().Model(&models.OneTimeCode{}).Where("1 = 1").Update("created_at", time.Now().Add(-time.Hour))
+	}
+
+	// Two codes a day are texted to a number
+	serve("192.0.2.1", "/otp/request", `{"phone": "+989120000001"}`, http.StatusAccepted)
+	resendable()
+	serve("192.0.2.1", "/otp/request", `{"phone": "+989120000001"}`, http.StatusAccepted)
+	resendable()
+	serve("192.0.2.1", "/otp/request", `{"phone": "+989120000001"}`, http.StatusTooManyRequests)
+
+	// Every request counts against the IP, which texts no other number once locked out
+	serve("192.0.2.1", "/otp/request", `{"phone": "+989120000002"}`, http.StatusAccepted)
+	serve("192.0.2.1", "/otp/request", `{"phone": "+989120000003"}`, http.StatusTooManyRequests)
+
+	// Wrong codes lock out the number, so a new code doesn't bring new guesses
+	for range 3 {
+		serve("192.0.2.2", "/otp/verify", `{"phone": "+989120000002", "code": "000000"}`, http.StatusUnauthorized)
+	}
+	serve("192.0.2.2", "/otp/verify", `{"phone": "+989120000002", "code": "111111"}`, http.StatusTooManyRequests)
+	resendable()
+	serve("192.0.2.3", "/otp/request", `{"phone": "+989120000002"}`, http.StatusTooManyRequests)
+	serve("192.0.2.3", "/otp/request", `{"phone": "+989120000004"}`, http.StatusAccepted)
+}
@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ChangePasswordRequest defines the request body for changing the password.
+// Users who signed up with a one-time code have no password yet and set
+// their first one without the current password.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword" binding:"required,min=6"`
+}
+
+// ChangePassword godoc
+// @Summary Change my password
+// @Description Change the password of the current user. Their other device sessions are logged out.
+// @Tags Users
+// @Security BearerAuth
+// @Accept json
+// @Param request body ChangePasswordRequest true "Current and new password"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Current password is incorrect"
+// @Failure 404 {object} map[string]string "error: User not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /users/me/password [put]
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	userIDAny, _ := c.Get("userID")
+	userID := uuid.MustParse(userIDAny.(string))
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+	if user.PasswordHash != "" && !utils.CheckPasswordHash(req.CurrentPassword, user.PasswordHash) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	// Keep the device the password was changed on logged in
+	if err := h.setPassword(user, req.NewPassword, c.GetString("sessionID")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ForgotPasswordRequest defines the request body for requesting a password
+// reset code.
+type ForgotPasswordRequest struct {
+	Phone string `json:"phone" binding:"required,e164"`
+}
+
+// ForgotPassword godoc
+// @Summary Text a password reset code
+// @Description Send a code to reset the password with to the phone number of a user. The response is the same whether or not the number belongs to a user.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "Phone number"
+// @Success 202 {object} map[string]interface{} "message, expiresIn in seconds"
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 429 {object} map[string]string "error: A code was sent recently or too often, or too many failed logins"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /password/forgot [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// A code is stored for unknown numbers too, so they are rate limited the
+	// same way, but it's only texted to users
+	code, ok := h.newCode(c, req.Phone, models.OTPPasswordReset)
+	if !ok {
+		return
+	}
+	err := h.DB.Where("phone = ?", req.Phone).First(&models.User{}).Error
+	switch {
+	case err == nil:
+		if !h.textCode(c, req.Phone, "password reset", code) {
+			return
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send code"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Code sent", "expiresIn": int(h.Cfg.OTP.TTL.Seconds())})
+}
+
+// ResetPasswordRequest defines the request body for resetting the password
+// with a code texted by /password/forgot.
+type ResetPasswordRequest struct {
+	Phone       string `json:"phone" binding:"required,e164"`
+	Code        string `json:"code" binding:"required,len=6,numeric"`
+	NewPassword string `json:"newPassword" binding:"required,min=6"`
+}
+
+// ResetPassword godoc
+// @Summary Reset a forgotten password
+// @Description Set a new password with a code texted by /password/forgot. All device sessions of the user are logged out.
+// @Tags Auth
+// @Accept json
+// @Param request body ResetPasswordRequest true "Phone number, code and new password"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Invalid or expired code"
+// @Failure 429 {object} map[string]string "error: Too many attempts"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /password/reset [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	err := h.verifyOTP(req.Phone, req.Code, models.OTPPasswordReset, time.Now())
+	if err == nil {
+		err = h.DB.Where("phone = ?", req.Phone).First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = errOTPInvalid
+		}
+	}
+	if err == nil {
+		err = h.setPassword(user, req.NewPassword, "")
+	}
+	switch {
+	case errors.Is(err, errOTPInvalid):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired code"})
+		return
+	case errors.Is(err, errOTPAttempts):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many attempts, request a new code"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// setPassword sets the password of user and revokes their device sessions
+// other than keepSession, so stolen refresh tokens stop working.
+func (h *AuthHandler) setPassword(user models.User, password, keepSession string) error {
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("password_hash", hash).Error; err != nil {
+			return err
+		}
+		_, err := revokeSessions(tx.Where("user_id = ? AND id <> ?", user.ID, keepSession))
+		return err
+	})
+}
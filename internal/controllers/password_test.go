@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/middleware"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/notify"
+	"yoga-guru/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPasswordChangeAndReset(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	sms := &notify.FileSMSSender{Path: filepath.Join(t.TempDir(), "sms.log")}
+	cfg := &config.Config{
+		JWTSecret:       "secret",
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+		Studio:          config.StudioDetails{Name: "Yoga Guru"},
+		OTP:             config.OTPPolicy{TTL: 5 * time.Minute, MaxAttempts: 3, ResendInterval: time.Minute},
+	}
+	h := NewAuthHandler(db, cfg, sms)
+
+	hash, _ := utils.HashPassword("secret1")
+	user := models.User{Phone: "+989120000001", PasswordHash: hash, Role: models.Student}
+	db.Create(&user)
+
+	r := gin.New()
+	r.POST("/login", h.Login)
+	r.POST("/refresh", h.RefreshToken)
+	r.POST("/password/forgot", h.ForgotPassword)
+	r.POST("/password/reset", h.ResetPassword)
+	r.PUT("/users/me/password", middleware.AuthMiddleware(cfg), h.ChangePassword)
+	serve := func(method, path, bearer, body string, want int) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != want {
+			t.Fatalf("%s %s: got status %d, want %d: %s", method, path, rr.Code, want, rr.Body)
+		}
+		return rr
+	}
+	type tokens struct {
+		Token   string
+		Refresh string
+	}
+	login := func(password string) tokens {
+		t.Helper()
+		rr := serve(http.MethodPost, "/login", "", `{"phone": "+989120000001", "password": "`+password+`"}`, http.StatusOK)
+		var got tokens
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	refresh := func(token string, want int) {
+		t.Helper()
+		serve(http.MethodPost, "/refresh", "", `{"refreshToken": "`+token+`"}`, want)
+	}
+
+	// Changing the password needs the current one and logs out other devices
+	phone, tablet := login("secret1"), login("secret1")
+	serve(http.MethodPut, "/users/me/password", phone.Token, `{"currentPassword": "wrong1", "newPassword": "secret2"}`, http.StatusForbidden)
+	serve(http.MethodPut, "/users/me/password", phone.Token, `{"currentPassword": "secret1", "newPassword": "secret2"}`, http.StatusNoContent)
+	refresh(tablet.Refresh, http.StatusUnauthorized)
+	refresh(phone.Refresh, http.StatusOK)
+	serve(http.MethodPost, "/login", "", `{"phone": "+989120000001", "password": "secret1"}`, http.StatusUnauthorized)
+	laptop := login("secret2")
+
+	// Reset codes are only texted to users, but unknown numbers get the same response
+	serve(http.MethodPost, "/password/forgot", "", `{"phone": "+989120000009"}`, http.StatusAccepted)
+	if _, err := os.Stat(sms.Path); !os.IsNotExist(err) {
+		t.Fatalf("got a text for an unknown number, want none")
+	}
+	serve(http.MethodPost, "/password/forgot", "", `{"phone": "+989120000001"}`, http.StatusAccepted)
+	sent, err := os.ReadFile(sms.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	match := regexp.MustCompile(`password reset code is (\d{6})`).FindStringSubmatch(string(sent))
+	if match == nil {
+		t.Fatalf("no reset code in %q", sent)
+	}
+	code := match[1]
+
+	// Invalid requests leave the code unused
+	serve(http.MethodPost, "/password/reset", "", `{"phone": "+989120000001", "code": "`+code+`", "newPassword": ""}`, http.StatusBadRequest)
+	serve(http.MethodPost, "/password/reset", "", `{"phone": "+989120000001", "code": "`+code+`", "newPassword": "secret3"}`, http.StatusNoContent)
+	serve(http.MethodPost, "/password/reset", "", `{"phone": "+989120000001", "code": "`+code+`", "newPassword": "secret4"}`, http.StatusUnauthorized)
+
+	// Resetting logs out every device
+	refresh(laptop.Refresh, http.StatusUnauthorized)
+	serve(http.MethodPost, "/login", "", `{"phone": "+989120000001", "password": "secret2"}`, http.StatusUnauthorized)
+	login("secret3")
+}
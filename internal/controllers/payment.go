@@ -0,0 +1,698 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/money"
+	"yoga-guru/internal/payment"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// errPaymentNotRefundable is returned when a payment is no longer succeeded.
+	errPaymentNotRefundable = errors.New("payment not refundable")
+	errExceedsBalance       = errors.New("amount exceeds the outstanding balance")
+	errDuplicateTransaction = errors.New("duplicate transaction ID")
+)
+
+// PaymentHandler provides methods for the payments ledger of enrollments.
+type PaymentHandler struct {
+	DB      *gorm.DB
+	Cfg     *config.Config
+	Gateway payment.Gateway
+}
+
+// NewPaymentHandler creates a new PaymentHandler instance.
+func NewPaymentHandler(db *gorm.DB, cfg *config.Config, gateway payment.Gateway) *PaymentHandler {
+	return &PaymentHandler{DB: db, Cfg: cfg, Gateway: gateway}
+}
+
+// EnrollmentPaymentsResponse lists the payments of an enrollment with its balance.
+type EnrollmentPaymentsResponse struct {
+	EnrollmentID uint             `json:"enrollmentId"`
+	PricePaid    money.Amount     `json:"pricePaid"`
+	Balance      money.Amount     `json:"balance"`
+	Payments     []models.Payment `json:"payments"`
+}
+
+// GetPayments godoc
+// @Summary Get the payments of an enrollment
+// @Description List the payments recorded against an enrollment and its outstanding balance. (Staff/Admin/Enrolled Student only)
+// @Tags Payments
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Enrollment ID"
+// @Success 200 {object} EnrollmentPaymentsResponse
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Enrollment not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /enrollments/{id}/payments [get]
+func (h *PaymentHandler) GetPayments(c *gin.Context) {
+	enrollment, ok := h.accessibleEnrollment(c)
+	if !ok {
+		return
+	}
+
+	var payments []models.Payment
+	if err := h.DB.Where("enrollment_id = ?", enrollment.ID).Order("payment_date").Find(&payments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch payments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, EnrollmentPaymentsResponse{
+		EnrollmentID: enrollment.ID,
+		PricePaid:    enrollment.PricePaid,
+		Balance:      enrollment.Balance,
+		Payments:     payments,
+	})
+}
+
+// GetPaymentByID godoc
+// @Summary Get a payment of an enrollment
+// @Description Retrieve a single payment recorded against an enrollment. (Staff/Admin/Enrolled Student only)
+// @Tags Payments
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Enrollment ID"
+// @Param paymentID path int true "Payment ID"
+// @Success 200 {object} models.Payment
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Payment not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /enrollments/{id}/payments/{paymentID} [get]
+func (h *PaymentHandler) GetPaymentByID(c *gin.Context) {
+	enrollment, ok := h.accessibleEnrollment(c)
+	if !ok {
+		return
+	}
+
+	payment, ok := h.enrollmentPayment(c, enrollment.ID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, payment)
+}
+
+// RecordPaymentRequest defines the request body for recording a payment.
+type RecordPaymentRequest struct {
+	Amount        money.Amount         `json:"amount" binding:"required,gt=0"`
+	Method        models.PaymentMethod `json:"method" binding:"required,oneof=cash card bank_transfer"`
+	TransactionID string               `json:"transactionId"` // Card terminal or bank reference, if any
+	PaymentDate   *time.Time           `json:"paymentDate"`   // Defaults to now
+}
+
+// RecordPayment godoc
+// @Summary Record a payment (Staff/Admin only)
+// @Description Record a cash, card or bank transfer payment received at the front desk against an enrollment. A pending enrollment paid in full becomes active.
+// @Tags Payments
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Enrollment ID"
+// @Param payment body RecordPaymentRequest true "Payment details"
+// @Success 201 {object} models.Payment
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Enrollment not found"
+// @Failure 409 {object} map[string]string "error: Amount exceeds balance or duplicate transaction"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /enrollments/{id}/payments [post]
+func (h *PaymentHandler) RecordPayment(c *gin.Context) {
+	enrollment, ok := h.accessibleEnrollment(c)
+	if !ok {
+		return
+	}
+
+	var req RecordPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transactionID := req.TransactionID
+	if transactionID == "" {
+		// Transaction IDs are unique, give manual payments one of their own
+		transactionID = uuid.NewString()
+	}
+	paymentDate := time.Now()
+	if req.PaymentDate != nil {
+		paymentDate = *req.PaymentDate
+	}
+
+	payment := models.Payment{
+		EnrollmentID:  enrollment.ID,
+		Amount:        req.Amount,
+		Status:        models.PaymentSucceeded,
+		Method:        req.Method,
+		TransactionID: transactionID,
+		PaymentDate:   paymentDate,
+	}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// The balance is checked where the payment is written, so concurrent
+		// payments can't together pay more than is owed
+		if err := loadBalances(tx, enrollment); err != nil {
+			return err
+		}
+		if req.Amount > enrollment.Balance {
+			return errExceedsBalance
+		}
+		if err := tx.Create(&payment).Error; err != nil {
+			if isDuplicate(tx, err) {
+				return errDuplicateTransaction
+			}
+			return err
+		}
+		return settlePaidUp(tx, enrollment)
+	})
+	switch err {
+	case nil:
+	case errExceedsBalance:
+		c.JSON(http.StatusConflict, gin.H{"error": "Amount exceeds the outstanding balance"})
+		return
+	case errDuplicateTransaction:
+		c.JSON(http.StatusConflict, gin.H{"error": "A payment with this transaction ID already exists"})
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record payment"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, payment)
+}
+
+// RefundPaymentRequest defines the optional request body for refunding a payment.
+type RefundPaymentRequest struct {
+	// AsCredit refunds into the student's wallet instead of paying back.
+	AsCredit bool `json:"asCredit"`
+}
+
+// RefundPayment godoc
+// @Summary Refund a payment (Staff/Admin only)
+// @Description Refund what is left unrefunded of a succeeded payment, recorded as a refund payment of its own. The refunded amount is owed again on the enrollment balance. Online payments are paid back through the payment gateway, payments from credit and refunds as credit go to the student's wallet.
+// @Tags Payments
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Enrollment ID"
+// @Param paymentID path int true "Payment ID"
+// @Param refund body RefundPaymentRequest false "Refund options"
+// @Success 201 {object} models.Payment "The refund"
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Payment not found"
+// @Failure 409 {object} map[string]string "error: Payment has not succeeded or was refunded"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Failure 502 {object} map[string]string "error: Payment gateway error"
+// @Router /enrollments/{id}/payments/{paymentID}/refund [post]
+func (h *PaymentHandler) RefundPayment(c *gin.Context) {
+	enrollment, ok := h.accessibleEnrollment(c)
+	if !ok {
+		return
+	}
+
+	payment, ok := h.enrollmentPayment(c, enrollment.ID)
+	if !ok {
+		return
+	}
+
+	var req RefundPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF { // The body is optional
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if payment.Status != models.PaymentSucceeded || payment.Refunded >= payment.Amount {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only succeeded payments not refunded yet can be refunded"})
+		return
+	}
+
+	method := payment.Method
+	if req.AsCredit {
+		method = models.Credit
+	}
+	var refund *models.Payment
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		refund, err = refundPayment(tx, enrollment, payment, payment.Amount-payment.Refunded, method)
+		return err
+	})
+	if err != nil {
+		if err == errPaymentNotRefundable {
+			c.JSON(http.StatusConflict, gin.H{"error": "Only succeeded payments not refunded yet can be refunded"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refund payment"})
+		return
+	}
+
+	// The refund is recorded before the gateway pays it back, so a refund
+	// that went through is never lost
+	if refund.Status == models.PaymentRefundPending {
+		if err := settleRefund(c.Request.Context(), h.DB, h.Gateway, refund); err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to refund payment with the payment gateway"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusCreated, refund)
+}
+
+// PayWithCreditRequest defines the optional request body for paying from credit.
+type PayWithCreditRequest struct {
+	// Amount defaults to as much of the balance as the wallet covers.
+	Amount money.Amount `json:"amount" binding:"omitempty,gt=0"`
+}
+
+// PayWithCredit godoc
+// @Summary Pay an enrollment from credit
+// @Description Pay an enrollment partially or fully from the enrolled student's wallet. A pending enrollment paid in full becomes active. (Staff/Admin/Enrolled Student only)
+// @Tags Payments
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Enrollment ID"
+// @Param payment body PayWithCreditRequest false "Amount to pay"
+// @Success 201 {object} models.Payment
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Enrollment not found"
+// @Failure 409 {object} map[string]string "error: Amount exceeds balance or credit"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /enrollments/{id}/payments/credit [post]
+func (h *PaymentHandler) PayWithCredit(c *gin.Context) {
+	enrollment, ok := h.accessibleEnrollment(c)
+	if !ok {
+		return
+	}
+
+	var req PayWithCreditRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF { // The body is optional
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var p models.Payment
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// The balance is checked where the payment is written, and the wallet
+		// only charged while it holds the amount, so concurrent payments can't
+		// overpay the enrollment or overdraw the wallet
+		if err := loadBalances(tx, enrollment); err != nil {
+			return err
+		}
+		if enrollment.Balance <= 0 || req.Amount > enrollment.Balance {
+			return errExceedsBalance
+		}
+
+		amount := req.Amount
+		if amount == 0 {
+			var wallet models.Wallet
+			if err := tx.Where("user_id = ?", enrollment.UserID).Limit(1).Find(&wallet).Error; err != nil {
+				return err
+			}
+			amount = min(enrollment.Balance, wallet.Balance)
+			if amount <= 0 {
+				return errInsufficientCredit
+			}
+		}
+
+		p = models.Payment{
+			EnrollmentID:  enrollment.ID,
+			Amount:        amount,
+			Status:        models.PaymentSucceeded,
+			Method:        models.Credit,
+			TransactionID: uuid.NewString(),
+			PaymentDate:   time.Now(),
+		}
+		if err := tx.Create(&p).Error; err != nil {
+			return err
+		}
+		if err := addCredit(tx, &models.CreditTransaction{
+			UserID:      enrollment.UserID,
+			Kind:        models.CreditPayment,
+			Amount:      -amount,
+			Description: fmt.Sprintf("Payment for enrollment %d", enrollment.ID),
+			PaymentID:   &p.ID,
+		}); err != nil {
+			return err
+		}
+		return settlePaidUp(tx, enrollment)
+	})
+	switch err {
+	case nil:
+	case errExceedsBalance:
+		c.JSON(http.StatusConflict, gin.H{"error": "Amount exceeds the outstanding balance"})
+		return
+	case errInsufficientCredit:
+		c.JSON(http.StatusConflict, gin.H{"error": "Not enough credit in the wallet"})
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to pay from credit"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, p)
+}
+
+// DeletePayment godoc
+// @Summary Delete a payment (Admin only)
+// @Description Remove a cash, card or bank transfer payment recorded by mistake from the ledger. Payments that were refunded, refunds and other payments can't be deleted, refund them instead.
+// @Tags Payments
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Enrollment ID"
+// @Param paymentID path int true "Payment ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Payment not found"
+// @Failure 409 {object} map[string]string "error: Payment can't be deleted"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /enrollments/{id}/payments/{paymentID} [delete]
+func (h *PaymentHandler) DeletePayment(c *gin.Context) {
+	enrollment, ok := h.accessibleEnrollment(c)
+	if !ok {
+		return
+	}
+
+	payment, ok := h.enrollmentPayment(c, enrollment.ID)
+	if !ok {
+		return
+	}
+
+	// Other payments moved money elsewhere too, and are undone by refunding them
+	manual := payment.Method == models.Cash || payment.Method == models.Card || payment.Method == models.BankTransfer
+	if !manual || payment.RefundOfID != nil || payment.Refunded > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only cash, card or bank transfer payments without refunds can be deleted"})
+		return
+	}
+
+	// Guard against the payment being refunded concurrently
+	deletion := h.DB.Where("refunded = 0").Delete(payment)
+	if deletion.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete payment"})
+		return
+	}
+	if deletion.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only cash, card or bank transfer payments without refunds can be deleted"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CheckoutResponse points the student to the gateway to complete a payment.
+type CheckoutResponse struct {
+	PaymentID   uint   `json:"paymentId"`
+	RedirectURL string `json:"redirectUrl"`
+}
+
+// Checkout godoc
+// @Summary Pay an enrollment online
+// @Description Start an online payment for the outstanding balance of an enrollment. The student completes it at the returned gateway URL. (Staff/Admin/Enrolled Student only)
+// @Tags Payments
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Enrollment ID"
+// @Success 201 {object} CheckoutResponse
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Enrollment not found"
+// @Failure 409 {object} map[string]string "error: Nothing to pay"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Failure 502 {object} map[string]string "error: Payment gateway error"
+// @Router /enrollments/{id}/payments/checkout [post]
+func (h *PaymentHandler) Checkout(c *gin.Context) {
+	enrollment, ok := h.accessibleEnrollment(c)
+	if !ok {
+		return
+	}
+
+	if enrollment.Balance <= 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "This enrollment has nothing left to pay"})
+		return
+	}
+
+	p, checkout, err := payment.StartOnline(c.Request.Context(), h.DB, h.Gateway, h.Cfg.PaymentCallbackURL(), enrollment)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to start online payment"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, CheckoutResponse{PaymentID: p.ID, RedirectURL: checkout.RedirectURL})
+}
+
+// PaymentCallbackResponse reports the outcome of an online payment.
+type PaymentCallbackResponse struct {
+	PaymentID    uint                 `json:"paymentId"`
+	EnrollmentID uint                 `json:"enrollmentId"`
+	Status       models.PaymentStatus `json:"status"`
+}
+
+// PaymentCallback godoc
+// @Summary Payment gateway callback
+// @Description The payment gateway sends the payer back here. The result is verified with the gateway, the payment settled and a pending enrollment activated. What the payment paid over the outstanding balance, when the enrollment was paid otherwise in the meantime, is refunded. Repeated callbacks for a settled payment have no effect.
+// @Tags Payments
+// @Produce json
+// @Success 200 {object} PaymentCallbackResponse
+// @Failure 400 {object} map[string]string "error: Invalid callback"
+// @Failure 404 {object} map[string]string "error: Payment not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /payments/callback [get]
+// @Router /payments/callback [post]
+func (h *PaymentHandler) PaymentCallback(c *gin.Context) {
+	if err := c.Request.ParseForm(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid callback"})
+		return
+	}
+
+	result, err := h.Gateway.VerifyCallback(c.Request.Context(), c.Request.Form)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid callback"})
+		return
+	}
+
+	var p models.Payment
+	var refund *models.Payment
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("transaction_id = ?", result.TransactionID).First(&p).Error; err != nil {
+			return err
+		}
+		// Payments failed while open, for a newer checkout or once the
+		// enrollment was paid otherwise, still count if the payer completed them
+		if p.Status != models.PaymentPending && (p.Status != models.PaymentFailed || !result.Succeeded) {
+			// Already settled by an earlier callback
+			return nil
+		}
+		if !result.Succeeded {
+			return tx.Model(&p).Where("status = ?", models.PaymentPending).
+				Updates(models.Payment{Status: models.PaymentFailed, PaymentDate: time.Now()}).Error
+		}
+
+		// The balance is checked where the payment is settled, so concurrent
+		// checkouts can't together pay more than is owed
+		var enrollment models.Enrollment
+		if err := tx.First(&enrollment, p.EnrollmentID).Error; err != nil {
+			return err
+		}
+		if err := loadBalances(tx, &enrollment); err != nil {
+			return err
+		}
+		update := tx.Model(&p).Where("status = ?", p.Status).
+			Updates(models.Payment{Status: models.PaymentSucceeded, PaymentDate: time.Now()})
+		if update.Error != nil || update.RowsAffected == 0 {
+			return update.Error
+		}
+
+		// What was paid over the balance goes back to the payer
+		if excess := p.Amount - max(enrollment.Balance, 0); excess > 0 {
+			var err error
+			if refund, err = refundPayment(tx, &enrollment, &p, excess, models.OnlinePayment); err != nil {
+				return err
+			}
+		}
+		return settlePaidUp(tx, &enrollment)
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to settle payment"})
+		return
+	}
+	if refund != nil {
+		if err := settleRefund(c.Request.Context(), h.DB, h.Gateway, refund); err != nil {
+			log.Printf("failed to refund the overpayment of payment %d: %v", p.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, PaymentCallbackResponse{PaymentID: p.ID, EnrollmentID: p.EnrollmentID, Status: p.Status})
+}
+
+// settlePaidUp fails the online payments still open of the enrollment and
+// activates it if pending, once its balance is paid in full.
+func settlePaidUp(tx *gorm.DB, enrollment *models.Enrollment) error {
+	if err := loadBalances(tx, enrollment); err != nil {
+		return err
+	}
+	if enrollment.Balance > 0 {
+		return nil
+	}
+	if err := tx.Model(&models.Payment{}).
+		Where("enrollment_id = ? AND status = ?", enrollment.ID, models.PaymentPending).
+		Update("status", models.PaymentFailed).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Enrollment{}).
+		Where("id = ? AND status = ?", enrollment.ID, models.EnrollmentPending).
+		Update("status", models.EnrollmentActive).Error
+}
+
+// accessibleEnrollment fetches the enrollment from the path, with its
+// balance, and checks that the current user is staff, an admin or the
+// enrolled student. It writes the error response and returns false otherwise.
+func (h *PaymentHandler) accessibleEnrollment(c *gin.Context) (*models.Enrollment, bool) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return nil, false
+	}
+	currentUserID := uuid.MustParse(userIDAny.(string))
+	userRoleAny := c.MustGet("userRole")
+	currentUserRole := userRoleAny.(models.UserRole)
+
+	enrollmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid enrollment ID"})
+		return nil, false
+	}
+
+	var enrollment models.Enrollment
+	if err := h.DB.First(&enrollment, uint(enrollmentID)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Enrollment not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch enrollment"})
+		return nil, false
+	}
+
+	if currentUserRole != models.Admin && currentUserRole != models.Staff && enrollment.UserID != currentUserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to view this enrollment's payments"})
+		return nil, false
+	}
+
+	if err := loadBalances(h.DB, &enrollment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute enrollment balance"})
+		return nil, false
+	}
+	return &enrollment, true
+}
+
+// enrollmentPayment fetches the payment from the path, writing the error
+// response and returning false if it does not belong to the enrollment.
+func (h *PaymentHandler) enrollmentPayment(c *gin.Context, enrollmentID uint) (*models.Payment, bool) {
+	paymentID, err := strconv.ParseUint(c.Param("paymentID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment ID"})
+		return nil, false
+	}
+
+	var payment models.Payment
+	if err := h.DB.Where("enrollment_id = ?", enrollmentID).First(&payment, uint(paymentID)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch payment"})
+		return nil, false
+	}
+	return &payment, true
+}
+
+// isDuplicate reports whether err is the violation of a unique constraint.
+func isDuplicate(db *gorm.DB, err error) bool {
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		err = translator.Translate(err)
+	}
+	return errors.Is(err, gorm.ErrDuplicatedKey)
+}
+
+// loadBalances computes the Balance of the given enrollments from their
+// succeeded payments less what was refunded of them, and the SessionsLeft of
+// session packages from their used sessions and active bookings.
+func loadBalances(db *gorm.DB, enrollments ...*models.Enrollment) error {
+	if len(enrollments) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(enrollments))
+	for i, enrollment := range enrollments {
+		ids[i] = enrollment.ID
+	}
+
+	var totals []struct {
+		EnrollmentID uint
+		Total        money.Amount
+	}
+	if err := db.Model(&models.Payment{}).
+		Select("enrollment_id, SUM(amount - refunded) AS total").
+		Where("enrollment_id IN ? AND status = ?", ids, models.PaymentSucceeded).
+		Group("enrollment_id").Scan(&totals).Error; err != nil {
+		return err
+	}
+
+	paid := make(map[uint]money.Amount, len(totals))
+	for _, total := range totals {
+		paid[total.EnrollmentID] = total.Total
+	}
+	var bookings []struct {
+		EnrollmentID uint
+		Booked       int
+	}
+	if err := db.Model(&models.Booking{}).
+		Select("enrollment_id, COUNT(*) AS booked").
+		Where("enrollment_id IN ? AND status = ?", ids, models.BookingBooked).
+		Group("enrollment_id").Scan(&bookings).Error; err != nil {
+		return err
+	}
+
+	booked := make(map[uint]int, len(bookings))
+	for _, count := range bookings {
+		booked[count.EnrollmentID] = count.Booked
+	}
+	for _, enrollment := range enrollments {
+		enrollment.SessionsLeft = nil
+		if enrollment.TotalSessions > 0 {
+			left := max(enrollment.TotalSessions-enrollment.SessionsUsed-booked[enrollment.ID], 0)
+			enrollment.SessionsLeft = &left
+		}
+
+		if enrollment.Status == models.EnrollmentCancelled || enrollment.Status == models.EnrollmentTransferred {
+			enrollment.Balance = 0 // Nothing is owed on a cancelled or transferred enrollment
+			continue
+		}
+		enrollment.Balance = enrollment.PricePaid - paid[enrollment.ID]
+	}
+	return nil
+}
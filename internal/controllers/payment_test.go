@@ -0,0 +1,279 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/notify"
+	"yoga-guru/internal/payment"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestOnlinePaymentActivatesEnrollment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	cfg := &config.Config{JWTSecret: "secret", PublicURL: "http://studio.test"}
+	gateway := payment.NewFakeGateway()
+	enrollments := NewEnrollmentHandler(db, cfg, notify.LogNotifier{}, gateway)
+	paymentHandler := NewPaymentHandler(db, cfg, gateway)
+
+	student := models.User{Phone: "+989120000300", Role: models.Student}
+	db.Create(&student)
+	course := models.Course{Title: "Kundalini", Capacity: 10, Price: 100}
+	db.Create(&course)
+	plans := models.DefaultPricePlans()
+	db.Create(&plans)
+
+	r := gin.New()
+	r.GET("/payments/callback", paymentHandler.PaymentCallback)
+	authorized := r.Group("/", withUser(student.ID, models.Student))
+	authorized.POST("/enrollments", enrollments.EnrollInCourse)
+
+	rr := httptest.NewRecorder()
+	body := fmt.Sprintf(`{"CourseID":%d,"EnrollmentType":"monthly","PayOnline":true}`, course.ID)
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/enrollments", strings.NewReader(body)))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("enrolling: got status %d: %s", rr.Code, rr.Body)
+	}
+	var enrolled EnrollResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &enrolled); err != nil {
+		t.Fatal(err)
+	}
+	if enrolled.Status != models.EnrollmentPending || enrolled.PaymentURL == "" {
+		t.Fatalf("got status %q and payment URL %q, want a pending enrollment with a payment URL", enrolled.Status, enrolled.PaymentURL)
+	}
+
+	// Follow the gateway redirect back to the callback, twice
+	redirect, err := url.Parse(enrolled.PaymentURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/payments/callback?"+redirect.RawQuery, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("callback: got status %d: %s", rr.Code, rr.Body)
+		}
+	}
+
+	var payments []models.Payment
+	db.Where("enrollment_id = ?", enrolled.ID).Find(&payments)
+	if len(payments) != 1 || payments[0].Status != models.PaymentSucceeded || payments[0].Amount != enrolled.PricePaid {
+		t.Errorf("got payments %+v, want one succeeded payment of %v", payments, enrolled.PricePaid)
+	}
+	var enrollment models.Enrollment
+	db.First(&enrollment, enrolled.ID)
+	if enrollment.Status != models.EnrollmentActive {
+		t.Errorf("got enrollment status %q, want %q", enrollment.Status, models.EnrollmentActive)
+	}
+
+	// A tampered callback is rejected
+	params := redirect.Query()
+	params.Set("status", "NOK")
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/payments/callback?"+params.Encode(), nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("tampered callback: got status %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestCompletingTwoCheckoutsRefundsOverpayment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	gateway := payment.NewFakeGateway()
+	h := NewPaymentHandler(db, &config.Config{PublicURL: "http://studio.test"}, gateway)
+
+	student := models.User{Phone: "+989120000330", Role: models.Student}
+	db.Create(&student)
+	course := models.Course{Title: "Hatha"}
+	db.Create(&course)
+	enrollment := models.Enrollment{UserID: student.ID, CourseID: course.ID, Status: models.EnrollmentPending, PricePaid: 100}
+	db.Create(&enrollment)
+
+	r := gin.New()
+	r.GET("/payments/callback", h.PaymentCallback)
+	r.POST("/enrollments/:id/payments/checkout", withUser(student.ID, models.Student), h.Checkout)
+	checkout := func() CheckoutResponse {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/enrollments/%d/payments/checkout", enrollment.ID), nil))
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("checkout: got status %d: %s", rr.Code, rr.Body)
+		}
+		var response CheckoutResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		return response
+	}
+	complete := func(response CheckoutResponse) {
+		t.Helper()
+		redirect, err := url.Parse(response.RedirectURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/payments/callback?"+redirect.RawQuery, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("callback: got status %d: %s", rr.Code, rr.Body)
+		}
+	}
+
+	// The second checkout replaces the first, which the student completes anyway
+	first, second := checkout(), checkout()
+	var replaced models.Payment
+	db.First(&replaced, first.PaymentID)
+	if replaced.Status != models.PaymentFailed {
+		t.Errorf("got replaced checkout status %q, want %q", replaced.Status, models.PaymentFailed)
+	}
+	complete(second)
+	complete(first)
+
+	var refunds []models.Payment
+	db.Where("refund_of_id = ?", first.PaymentID).Find(&refunds)
+	if len(refunds) != 1 || refunds[0].Status != models.PaymentRefunded || refunds[0].Amount != 100 {
+		t.Errorf("got refunds %+v, want the first checkout refunded in full", refunds)
+	}
+	db.First(&enrollment, enrollment.ID)
+	if err := loadBalances(db, &enrollment); err != nil {
+		t.Fatal(err)
+	}
+	if enrollment.Status != models.EnrollmentActive || enrollment.Balance != 0 {
+		t.Errorf("got enrollment status %q with balance %v, want %q and paid up",
+			enrollment.Status, enrollment.Balance, models.EnrollmentActive)
+	}
+}
+
+func TestRefundPaymentRecordsRefund(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	h := NewPaymentHandler(db, &config.Config{}, payment.NewFakeGateway())
+
+	staff := models.User{Phone: "+989120000310", Role: models.Staff}
+	student := models.User{Phone: "+989120000311", Role: models.Student}
+	db.Create(&staff)
+	db.Create(&student)
+	course := models.Course{Title: "Yin"}
+	db.Create(&course)
+	enrollment := models.Enrollment{UserID: student.ID, CourseID: course.ID, Status: models.EnrollmentActive, PricePaid: 100}
+	db.Create(&enrollment)
+	cash := models.Payment{EnrollmentID: enrollment.ID, Amount: 60, Status: models.PaymentSucceeded, Method: models.Cash, TransactionID: "cash-1"}
+	online := models.Payment{EnrollmentID: enrollment.ID, Amount: 40, Status: models.PaymentSucceeded, Method: models.OnlinePayment, TransactionID: "unknown-to-gateway"}
+	db.Create(&cash)
+	db.Create(&online)
+
+	r := gin.New()
+	r.Use(withUser(staff.ID, models.Staff))
+	r.GET("/enrollments/:id/payments", h.GetPayments)
+	r.POST("/enrollments/:id/payments/:paymentID/refund", h.RefundPayment)
+	refund := func(p models.Payment, want int) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/enrollments/%d/payments/%d/refund", enrollment.ID, p.ID), nil))
+		if rr.Code != want {
+			t.Fatalf("refunding payment %d: got status %d, want %d: %s", p.ID, rr.Code, want, rr.Body)
+		}
+	}
+
+	// The refund is a payment of its own, the refunded amount is owed again
+	refund(cash, http.StatusCreated)
+	refund(cash, http.StatusConflict)
+	db.First(&cash, cash.ID)
+	if cash.Status != models.PaymentSucceeded || cash.Refunded != 60 {
+		t.Errorf("got payment %+v, want it succeeded with 60 refunded", cash)
+	}
+	var refunds []models.Payment
+	db.Where("refund_of_id = ?", cash.ID).Find(&refunds)
+	if len(refunds) != 1 || refunds[0].Status != models.PaymentRefunded || refunds[0].Amount != 60 {
+		t.Errorf("got refunds %+v, want one refund of 60", refunds)
+	}
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/enrollments/%d/payments", enrollment.ID), nil))
+	var payments EnrollmentPaymentsResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &payments); err != nil {
+		t.Fatal(err)
+	}
+	if payments.Balance != 60 {
+		t.Errorf("got balance %v, want 60", payments.Balance)
+	}
+
+	// A refund the gateway turns down is recorded as failed and can be retried
+	refund(online, http.StatusBadGateway)
+	db.First(&online, online.ID)
+	refunds = nil
+	db.Where("refund_of_id = ?", online.ID).Find(&refunds)
+	if online.Refunded != 0 || len(refunds) != 1 || refunds[0].Status != models.PaymentRefundFailed {
+		t.Errorf("got payment %+v and refunds %+v, want nothing refunded and a failed refund", online, refunds)
+	}
+	refund(online, http.StatusBadGateway)
+
+	// Only manual payments without refunds can be deleted
+	typo := models.Payment{EnrollmentID: enrollment.ID, Amount: 5, Status: models.PaymentSucceeded, Method: models.Card, TransactionID: "typo"}
+	db.Create(&typo)
+	r.DELETE("/enrollments/:id/payments/:paymentID", h.DeletePayment)
+	for _, tc := range []struct {
+		payment models.Payment
+		want    int
+	}{{cash, http.StatusConflict}, {refunds[0], http.StatusConflict}, {online, http.StatusConflict}, {typo, http.StatusNoContent}} {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/enrollments/%d/payments/%d", enrollment.ID, tc.payment.ID), nil))
+		if rr.Code != tc.want {
+			t.Errorf("deleting payment %d: got status %d, want %d", tc.payment.ID, rr.Code, tc.want)
+		}
+	}
+}
+
+func TestRecordPaymentChecksBalanceAndTransaction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	h := NewPaymentHandler(db, &config.Config{}, payment.NewFakeGateway())
+
+	staff := models.User{Phone: "+989120000320", Role: models.Staff}
+	student := models.User{Phone: "+989120000321", Role: models.Student}
+	db.Create(&staff)
+	db.Create(&student)
+	course := models.Course{Title: "Hatha"}
+	db.Create(&course)
+	enrollment := models.Enrollment{UserID: student.ID, CourseID: course.ID, Status: models.EnrollmentPending, PricePaid: 100}
+	db.Create(&enrollment)
+	checkout := models.Payment{EnrollmentID: enrollment.ID, Amount: 100, Status: models.PaymentPending, Method: models.OnlinePayment, TransactionID: "checkout-1"}
+	db.Create(&checkout)
+
+	r := gin.New()
+	r.Use(withUser(staff.ID, models.Staff))
+	r.POST("/enrollments/:id/payments", h.RecordPayment)
+	record := func(body string, want int) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/enrollments/%d/payments", enrollment.ID), strings.NewReader(body)))
+		if rr.Code != want {
+			t.Fatalf("recording %s: got status %d, want %d: %s", body, rr.Code, want, rr.Body)
+		}
+	}
+
+	record(`{"amount": 60, "method": "card", "transactionId": "POS-1"}`, http.StatusCreated)
+	record(`{"amount": 10, "method": "card", "transactionId": "POS-1"}`, http.StatusConflict)
+	record(`{"amount": 50, "method": "cash"}`, http.StatusConflict)
+	record(`{"amount": 40, "method": "cash"}`, http.StatusCreated)
+
+	var payments int64
+	db.Model(&models.Payment{}).Where("status = ?", models.PaymentSucceeded).Count(&payments)
+	if payments != 2 {
+		t.Errorf("got %d succeeded payments, want 2", payments)
+	}
+
+	// Paid in full at the desk, the enrollment is active and the checkout no longer needed
+	db.First(&enrollment, enrollment.ID)
+	db.First(&checkout, checkout.ID)
+	if enrollment.Status != models.EnrollmentActive || checkout.Status != models.PaymentFailed {
+		t.Errorf("got enrollment status %q and checkout status %q, want %q and %q",
+			enrollment.Status, checkout.Status, models.EnrollmentActive, models.PaymentFailed)
+	}
+}
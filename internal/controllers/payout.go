@@ -0,0 +1,530 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/money"
+	"yoga-guru/internal/notify"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errStatementNotFound  = errors.New("payout statement not found")
+	errStatementPaid      = errors.New("payout statement already paid")
+	errStatementMonthOpen = errors.New("month of the payout statement has not ended")
+)
+
+// payoutMonthFormat is the format of statement months in requests.
+const payoutMonthFormat = "2006-01"
+
+// PayoutHandler provides methods for instructor payouts.
+type PayoutHandler struct {
+	DB       *gorm.DB
+	Cfg      *config.Config
+	Notifier notify.Notifier
+}
+
+// NewPayoutHandler creates a new PayoutHandler instance.
+func NewPayoutHandler(db *gorm.DB, cfg *config.Config, notifier notify.Notifier) *PayoutHandler {
+	return &PayoutHandler{DB: db, Cfg: cfg, Notifier: notifier}
+}
+
+// PayoutRuleRequest defines a payout rule of an instructor.
+type PayoutRuleRequest struct {
+	Kind models.PayoutRuleKind `json:"kind" binding:"required,oneof=per_session per_attendee revenue_share"`
+	// Amount is paid per session or attendee, in minor units of the studio
+	// currency. Share is the fraction of revenue paid for revenue shares.
+	Amount   money.Amount `json:"amount" binding:"min=0"`
+	Share    float64      `json:"share" binding:"min=0,max=1"`
+	CourseID *uint        `json:"courseId"` // Limits the rule to a course of the instructor
+}
+
+// SetPayoutRulesRequest defines the request body for setting the payout
+// rules of an instructor.
+type SetPayoutRulesRequest struct {
+	Rules []PayoutRuleRequest `json:"rules" binding:"dive"`
+}
+
+// MarkPayoutPaidRequest defines the request body for marking a payout
+// statement paid.
+type MarkPayoutPaidRequest struct {
+	Reference string `json:"reference" binding:"max=200"` // e.g. the bank transfer the instructor was paid with
+}
+
+// GetMyPayout godoc
+// @Summary Get my payout statement (Instructor only)
+// @Description Retrieve what the current instructor earned in a month under their payout rules, from the sessions taught, their attendance and the net revenue of their courses. Statements are recomputed until they are paid.
+// @Tags Payouts
+// @Security BearerAuth
+// @Produce json
+// @Param month query string false "Month, e.g. 2025-10, defaults to the current month"
+// @Success 200 {object} models.PayoutStatement
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /instructors/me/payouts [get]
+func (h *PayoutHandler) GetMyPayout(c *gin.Context) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	h.respondStatement(c, uuid.MustParse(userIDAny.(string)))
+}
+
+// GetInstructorPayout godoc
+// @Summary Get the payout statement of an instructor (Admin only)
+// @Description Retrieve what an instructor earned in a month under their payout rules. Statements are recomputed until they are paid.
+// @Tags Payouts
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Instructor ID"
+// @Param month query string false "Month, e.g. 2025-10, defaults to the current month"
+// @Success 200 {object} models.PayoutStatement
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Instructor not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /instructors/{id}/payouts [get]
+func (h *PayoutHandler) GetInstructorPayout(c *gin.Context) {
+	instructor, ok := h.instructor(c)
+	if !ok {
+		return
+	}
+	h.respondStatement(c, instructor.ID)
+}
+
+// GetPayouts godoc
+// @Summary Get payout statements (Admin only)
+// @Description List the payout statements of all instructors, optionally of a month or status. Only statements fetched before are listed, and open ones as last computed.
+// @Tags Payouts
+// @Security BearerAuth
+// @Produce json
+// @Param month query string false "Month, e.g. 2025-10"
+// @Param status query string false "Statement status" Enums(open, paid)
+// @Success 200 {array} models.PayoutStatement
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /payouts [get]
+func (h *PayoutHandler) GetPayouts(c *gin.Context) {
+	query := h.DB.Preload("Lines")
+	if c.Query("month") != "" {
+		month, ok := payoutMonth(c)
+		if !ok {
+			return
+		}
+		query = query.Where("month = ?", month)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var statements []models.PayoutStatement
+	if err := query.Order("month DESC, instructor_id").Find(&statements).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch payout statements"})
+		return
+	}
+	c.JSON(http.StatusOK, statements)
+}
+
+// MarkPayoutPaid godoc
+// @Summary Mark a payout statement paid (Admin only)
+// @Description Recompute a statement of a past month one last time and mark it paid, after which it no longer changes. The instructor is notified.
+// @Tags Payouts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Statement ID"
+// @Param payment body MarkPayoutPaidRequest false "Payment reference"
+// @Success 200 {object} models.PayoutStatement
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Statement not found"
+// @Failure 409 {object} map[string]string "error: Statement already paid or month not over"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /payouts/{id}/pay [post]
+func (h *PayoutHandler) MarkPayoutPaid(c *gin.Context) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	adminID := uuid.MustParse(userIDAny.(string))
+
+	statementID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid statement ID"})
+		return
+	}
+	var req MarkPayoutPaidRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	var statement *models.PayoutStatement
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		var existing models.PayoutStatement
+		if err := tx.First(&existing, uint(statementID)).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errStatementNotFound
+			}
+			return err
+		}
+		if existing.Status == models.PayoutPaid {
+			return errStatementPaid
+		}
+		if existing.Month.AddDate(0, 1, 0).After(now) {
+			return errStatementMonthOpen
+		}
+
+		statement, err = refreshStatement(tx, existing.InstructorID, existing.Month, h.Cfg.Currency, now)
+		if err != nil {
+			return err
+		}
+		update := tx.Model(statement).Where("status = ?", models.PayoutOpen).Updates(models.PayoutStatement{
+			Status:    models.PayoutPaid,
+			PaidAt:    &now,
+			PaidByID:  &adminID,
+			Reference: req.Reference,
+		})
+		if update.Error != nil {
+			return update.Error
+		}
+		if update.RowsAffected == 0 {
+			return errStatementPaid
+		}
+		return nil
+	})
+	if err != nil {
+		switch err {
+		case errStatementNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Payout statement not found"})
+		case errStatementPaid:
+			c.JSON(http.StatusConflict, gin.H{"error": "This payout statement has already been paid"})
+		case errStatementMonthOpen:
+			c.JSON(http.StatusConflict, gin.H{"error": "Only statements of past months can be paid"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark payout statement paid"})
+		}
+		return
+	}
+
+	var instructor models.User
+	if err := h.DB.First(&instructor, "id = ?", statement.InstructorID).Error; err == nil {
+		message := fmt.Sprintf("Your payout of %s for %s was paid.",
+			h.Cfg.FormatMoney(statement.Amount, statement.Currency), statement.Month.Format("January 2006"))
+		if err := h.Notifier.Notify(instructor, message); err != nil {
+			log.Printf("failed to notify user %s of payout %d: %v", instructor.ID, statement.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
+
+// GetPayoutRules godoc
+// @Summary Get the payout rules of an instructor (Admin only)
+// @Description Retrieve how an instructor is paid. The instructor is paid the sum of their rules.
+// @Tags Payouts
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Instructor ID"
+// @Success 200 {array} models.PayoutRule
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Instructor not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /instructors/{id}/payout-rules [get]
+func (h *PayoutHandler) GetPayoutRules(c *gin.Context) {
+	instructor, ok := h.instructor(c)
+	if !ok {
+		return
+	}
+
+	var rules []models.PayoutRule
+	if err := h.DB.Preload("Course").Where("instructor_id = ?", instructor.ID).Order("id").Find(&rules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch payout rules"})
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// SetPayoutRules godoc
+// @Summary Set the payout rules of an instructor (Admin only)
+// @Description Replace how an instructor is paid: a flat amount per session taught, an amount per attending student, a share of the net revenue of their courses, or a sum of those. Rules can be limited to a course of the instructor. Open statements pick up the new rules, paid ones are kept.
+// @Tags Payouts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Instructor ID"
+// @Param rules body SetPayoutRulesRequest true "Payout rules"
+// @Success 200 {array} models.PayoutRule
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Instructor not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /instructors/{id}/payout-rules [put]
+func (h *PayoutHandler) SetPayoutRules(c *gin.Context) {
+	instructor, ok := h.instructor(c)
+	if !ok {
+		return
+	}
+	var req SetPayoutRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rules := make([]models.PayoutRule, len(req.Rules))
+	for i, r := range req.Rules {
+		if r.Kind == models.PayoutRevenueShare && r.Share == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A revenue share takes a share between 0 and 1"})
+			return
+		}
+		if r.Kind != models.PayoutRevenueShare && r.Amount == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A per session or per attendee rule takes an amount greater than 0"})
+			return
+		}
+		if r.CourseID != nil && h.DB.Where("id = ? AND instructor_id = ?", *r.CourseID, instructor.ID).First(&models.Course{}).Error != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Course not found among the instructor's courses"})
+			return
+		}
+
+		rules[i] = models.PayoutRule{InstructorID: instructor.ID, CourseID: r.CourseID, Kind: r.Kind}
+		if r.Kind == models.PayoutRevenueShare {
+			rules[i].Share = r.Share
+		} else {
+			rules[i].Amount = r.Amount
+		}
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("instructor_id = ?", instructor.ID).Delete(&models.PayoutRule{}).Error; err != nil {
+			return err
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+		return tx.Create(&rules).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set payout rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// respondStatement writes the refreshed statement of the instructor for the
+// month in the query.
+func (h *PayoutHandler) respondStatement(c *gin.Context, instructorID uuid.UUID) {
+	month, ok := payoutMonth(c)
+	if !ok {
+		return
+	}
+
+	var statement *models.PayoutStatement
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		statement, err = refreshStatement(tx, instructorID, month, h.Cfg.Currency, time.Now())
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute payout statement"})
+		return
+	}
+	c.JSON(http.StatusOK, statement)
+}
+
+// instructor fetches the instructor from the path, writing an error response
+// when there is none.
+func (h *PayoutHandler) instructor(c *gin.Context) (*models.User, bool) {
+	instructorID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid instructor ID"})
+		return nil, false
+	}
+
+	var instructor models.User
+	if err := h.DB.Where("id = ? AND role = ?", instructorID, models.Instructor).First(&instructor).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Instructor not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch instructor"})
+		return nil, false
+	}
+	return &instructor, true
+}
+
+// payoutMonth parses the month of the query, the current month by default,
+// writing an error response when it is invalid.
+func payoutMonth(c *gin.Context) (time.Time, bool) {
+	now := time.Now()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	if q := c.Query("month"); q != "" {
+		var err error
+		month, err = time.ParseInLocation(payoutMonthFormat, q, now.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month, use the format 2025-10"})
+			return time.Time{}, false
+		}
+	}
+	return month, true
+}
+
+// refreshStatement returns the statement of the instructor for the month
+// starting at month. Unless it is paid, the statement is recomputed from
+// the sessions taught and the payments received up to now under the
+// instructor's payout rules.
+func refreshStatement(tx *gorm.DB, instructorID uuid.UUID, month time.Time, currency money.Currency, now time.Time) (*models.PayoutStatement, error) {
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.PayoutStatement{
+		InstructorID: instructorID,
+		Month:        month,
+		Status:       models.PayoutOpen,
+		Currency:     currency,
+	}).Error; err != nil {
+		return nil, err
+	}
+	var statement models.PayoutStatement
+	if err := tx.Preload("Lines").Where("instructor_id = ? AND month = ?", instructorID, month).
+		First(&statement).Error; err != nil {
+		return nil, err
+	}
+	if statement.Status == models.PayoutPaid {
+		return &statement, nil
+	}
+
+	var rules []models.PayoutRule
+	if err := tx.Preload("Course").Where("instructor_id = ?", instructorID).Order("id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	// Sessions of the month that took place, with their attendees
+	end := month.AddDate(0, 1, 0)
+	var sessions []struct {
+		CourseID  uint
+		Attendees int
+	}
+	if err := tx.Model(&models.CourseSession{}).
+		Select("course_sessions.course_id, (SELECT COUNT(*) FROM attendances WHERE attendances.course_session_id = course_sessions.id AND attendances.attended AND attendances.deleted_at IS NULL) AS attendees").
+		Joins("JOIN courses ON courses.id = course_sessions.course_id").
+		Where("courses.instructor_id = ? AND NOT course_sessions.is_canceled", instructorID).
+		Where("course_sessions.scheduled_at >= ? AND course_sessions.scheduled_at < ? AND course_sessions.ends_at <= ?", month, end, now).
+		Scan(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	// Net revenue of the month by course, refunds taken off
+	var payments []struct {
+		CourseID uint
+		Status   models.PaymentStatus
+		Total    money.Amount
+	}
+	if err := tx.Model(&models.Payment{}).
+		Select("enrollments.course_id, payments.status, SUM(payments.net_amount) AS total").
+		Joins("JOIN enrollments ON enrollments.id = payments.enrollment_id").
+		Joins("JOIN courses ON courses.id = enrollments.course_id").
+		Where("courses.instructor_id = ? AND payments.status IN ?", instructorID, []models.PaymentStatus{models.PaymentSucceeded, models.PaymentRefunded}).
+		Where("payments.payment_date >= ? AND payments.payment_date < ?", month, end).
+		Group("enrollments.course_id, payments.status").Scan(&payments).Error; err != nil {
+		return nil, err
+	}
+	revenue := make(map[uint]money.Amount)
+	for _, p := range payments {
+		if p.Status == models.PaymentRefunded {
+			p.Total = -p.Total
+		}
+		revenue[p.CourseID] += p.Total
+	}
+
+	statement.Currency = currency
+	statement.Sessions, statement.Attendees, statement.Revenue, statement.Amount = 0, 0, 0, 0
+	for _, session := range sessions {
+		statement.Sessions++
+		statement.Attendees += session.Attendees
+	}
+	for _, amount := range revenue {
+		statement.Revenue += amount
+	}
+
+	lines := make([]models.PayoutLine, 0, len(rules))
+	for _, rule := range rules {
+		applies := func(courseID uint) bool { return rule.CourseID == nil || *rule.CourseID == courseID }
+		line := models.PayoutLine{StatementID: statement.ID, RuleID: rule.ID}
+		switch rule.Kind {
+		case models.PayoutPerSession, models.PayoutPerAttendee:
+			for _, session := range sessions {
+				if !applies(session.CourseID) {
+					continue
+				}
+				if rule.Kind == models.PayoutPerSession {
+					line.Quantity++
+				} else {
+					line.Quantity += session.Attendees
+				}
+			}
+			line.Basis = rule.Amount
+			line.Amount = rule.Amount.Times(line.Quantity)
+		case models.PayoutRevenueShare:
+			for courseID, amount := range revenue {
+				if applies(courseID) {
+					line.Basis += amount
+				}
+			}
+			line.Quantity = 1
+			line.Amount = max(line.Basis, 0).Mul(rule.Share)
+		}
+		line.Description = payoutRuleDescription(&rule)
+		statement.Amount += line.Amount
+		lines = append(lines, line)
+	}
+
+	if err := tx.Unscoped().Where("statement_id = ?", statement.ID).Delete(&models.PayoutLine{}).Error; err != nil {
+		return nil, err
+	}
+	if len(lines) > 0 {
+		if err := tx.Create(&lines).Error; err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Model(&statement).Select("currency", "sessions", "attendees", "revenue", "amount").
+		Updates(&statement).Error; err != nil {
+		return nil, err
+	}
+	statement.Lines = lines
+	return &statement, nil
+}
+
+// payoutRuleDescription describes the rule on statement lines, e.g. "Per
+// attendee in Hatha".
+func payoutRuleDescription(rule *models.PayoutRule) string {
+	description := map[models.PayoutRuleKind]string{
+		models.PayoutPerSession:   "Per session",
+		models.PayoutPerAttendee:  "Per attendee",
+		models.PayoutRevenueShare: fmt.Sprintf("%s%% revenue share", strconv.FormatFloat(rule.Share*100, 'f', -1, 64)),
+	}[rule.Kind]
+	if rule.Course != nil {
+		description += " in " + rule.Course.Title
+	}
+	return description
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/money"
+	"yoga-guru/internal/notify"
+	"yoga-guru/internal/payment"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestPayoutStatement(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	h := NewPayoutHandler(db, &config.Config{Currency: money.IRR}, notify.LogNotifier{})
+
+	admin := models.User{Phone: "+989120000000", Role: models.Admin}
+	instructor := models.User{Phone: "+989120000001", Role: models.Instructor}
+	db.Create(&admin)
+	db.Create(&instructor)
+	course := models.Course{Title: "Hatha", InstructorID: instructor.ID, Price: 100000, Capacity: 10}
+	other := models.Course{Title: "Yin", InstructorID: instructor.ID, Price: 100000, Capacity: 10}
+	db.Create(&course)
+	db.Create(&other)
+
+	// Last month: two sessions of Hatha with three attendees, one of Yin
+	// with one, one cancelled session, and 400,000 paid less 100,000 refunded
+	now := time.Now()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -1, 0)
+	session := func(course models.Course, day int, canceled bool, attendees ...bool) {
+		s := models.CourseSession{CourseID: course.ID, ScheduledAt: month.AddDate(0, 0, day).Add(10 * time.Hour),
+			EndsAt: month.AddDate(0, 0, day).Add(11 * time.Hour), IsCanceled: canceled}
+		db.Create(&s)
+		for i, attended := range attendees {
+			student := models.User{Phone: fmt.Sprintf("+98912%07d", day*10+i), Role: models.Student}
+			db.Create(&student)
+			db.Create(&models.Attendance{UserID: student.ID, CourseSessionID: s.ID, Attended: attended, RecordedAt: s.EndsAt})
+		}
+	}
+	session(course, 2, false, true, true, false)
+	session(course, 9, false, true)
+	session(other, 10, false, true)
+	session(course, 16, true)
+	enrollment := models.Enrollment{UserID: admin.ID, CourseID: course.ID, Currency: money.IRR, PricePaid: 400000,
+		StartDate: month, ExpirationDate: month.AddDate(0, 1, 0)}
+	db.Create(&enrollment)
+	for _, p := range []models.Payment{
+		{Amount: 400000, Status: models.PaymentSucceeded, PaymentDate: month.AddDate(0, 0, 1)},
+		{Amount: 100000, Status: models.PaymentRefunded, PaymentDate: month.AddDate(0, 0, 20)},
+		{Amount: 999999, Status: models.PaymentSucceeded, PaymentDate: month.AddDate(0, 1, 1)}, // This month
+	} {
+		p.EnrollmentID, p.Method, p.TransactionID = enrollment.ID, models.Cash, uuid.NewString()
+		db.Create(&p)
+	}
+
+	serve := func(userID uuid.UUID, role models.UserRole, method, path, body string, want int) *httptest.ResponseRecorder {
+		t.Helper()
+		r := gin.New()
+		r.Use(withUser(userID, role))
+		r.PUT("/instructors/:id/payout-rules", h.SetPayoutRules)
+		r.GET("/instructors/me/payouts", h.GetMyPayout)
+		r.POST("/payouts/:id/pay", h.MarkPayoutPaid)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		if rr.Code != want {
+			t.Fatalf("%s %s: got status %d, want %d: %s", method, path, rr.Code, want, rr.Body)
+		}
+		return rr
+	}
+	statement := func(month time.Time) models.PayoutStatement {
+		t.Helper()
+		rr := serve(instructor.ID, models.Instructor, http.MethodGet, "/instructors/me/payouts?month="+month.Format("2006-01"), "", http.StatusOK)
+		var statement models.PayoutStatement
+		if err := json.Unmarshal(rr.Body.Bytes(), &statement); err != nil {
+			t.Fatal(err)
+		}
+		return statement
+	}
+	rules := func(body string) {
+		t.Helper()
+		serve(admin.ID, models.Admin, http.MethodPut, fmt.Sprintf("/instructors/%s/payout-rules", instructor.ID), body, http.StatusOK)
+	}
+
+	rules(fmt.Sprintf(`{"rules": [{"kind": "per_session", "amount": 50000},
+		{"kind": "per_attendee", "amount": 10000, "courseId": %d},
+		{"kind": "revenue_share", "share": 0.4}]}`, course.ID))
+	got := statement(month)
+	// 3 sessions at 50,000, 3 Hatha attendees at 10,000 and 40% of 300,000
+	if got.Sessions != 3 || got.Attendees != 4 || got.Revenue != 300000 || got.Amount != 300000 || len(got.Lines) != 3 {
+		t.Fatalf("got statement %+v, want 3 sessions, 4 attendees, 300000 revenue and 300000 paid in 3 lines", got)
+	}
+	if got.Lines[1].Description != "Per attendee in Hatha" || got.Lines[1].Quantity != 3 || got.Lines[1].Amount != 30000 {
+		t.Errorf("got per attendee line %+v, want 3 Hatha attendees at 10000", got.Lines[1])
+	}
+
+	current := statement(month.AddDate(0, 1, 0))
+	serve(admin.ID, models.Admin, http.MethodPost, fmt.Sprintf("/payouts/%d/pay", current.ID), "", http.StatusConflict)
+	serve(admin.ID, models.Admin, http.MethodPost, fmt.Sprintf("/payouts/%d/pay", got.ID), `{"reference": "TRX-1"}`, http.StatusOK)
+	serve(admin.ID, models.Admin, http.MethodPost, fmt.Sprintf("/payouts/%d/pay", got.ID), "", http.StatusConflict)
+
+	// Paid statements no longer change with the rules
+	rules(`{"rules": [{"kind": "per_session", "amount": 1}]}`)
+	if paid := statement(month); paid.Status != models.PayoutPaid || paid.Amount != 300000 || paid.Reference != "TRX-1" {
+		t.Errorf("got paid statement %+v, want it kept at 300000", paid)
+	}
+	if current := statement(month.AddDate(0, 1, 0)); current.Status != models.PayoutOpen || current.Revenue != 999999 {
+		t.Errorf("got current statement %+v, want open with 999999 revenue", current)
+	}
+}
+
+func TestPayoutRevenueLessRefunds(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	cfg := &config.Config{Currency: money.IRR}
+	h := NewPayoutHandler(db, cfg, notify.LogNotifier{})
+	payments := NewPaymentHandler(db, cfg, payment.NewFakeGateway())
+
+	staff := models.User{Phone: "+989120000020", Role: models.Staff}
+	instructor := models.User{Phone: "+989120000021", Role: models.Instructor}
+	student := models.User{Phone: "+989120000022", Role: models.Student}
+	db.Create(&staff)
+	db.Create(&instructor)
+	db.Create(&student)
+	course := models.Course{Title: "Hatha", InstructorID: instructor.ID, Price: 500000, Capacity: 10}
+	db.Create(&course)
+	now := time.Now()
+	enrollment := models.Enrollment{UserID: student.ID, CourseID: course.ID, Currency: money.IRR, PricePaid: 500000,
+		Status: models.EnrollmentActive, StartDate: now, ExpirationDate: now.AddDate(0, 1, 0)}
+	db.Create(&enrollment)
+	refunded := models.Payment{EnrollmentID: enrollment.ID, Amount: 400000, Status: models.PaymentSucceeded,
+		Method: models.Cash, TransactionID: uuid.NewString(), PaymentDate: now}
+	kept := models.Payment{EnrollmentID: enrollment.ID, Amount: 100000, Status: models.PaymentSucceeded,
+		Method: models.Cash, TransactionID: uuid.NewString(), PaymentDate: now}
+	db.Create(&refunded)
+	db.Create(&kept)
+	db.Create(&models.PayoutRule{InstructorID: instructor.ID, Kind: models.PayoutRevenueShare, Share: 1})
+
+	r := gin.New()
+	r.Use(withUser(staff.ID, models.Staff))
+	r.POST("/enrollments/:id/payments/:paymentID/refund", payments.RefundPayment)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/enrollments/%d/payments/%d/refund", enrollment.ID, refunded.ID), nil))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("refunding payment: got status %d, want %d: %s", rr.Code, http.StatusCreated, rr.Body)
+	}
+
+	r = gin.New()
+	r.Use(withUser(instructor.ID, models.Instructor))
+	r.GET("/instructors/me/payouts", h.GetMyPayout)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/instructors/me/payouts", nil))
+	var statement models.PayoutStatement
+	if err := json.Unmarshal(rr.Body.Bytes(), &statement); err != nil {
+		t.Fatal(err)
+	}
+	// The refund is taken off the revenue once, not twice
+	if statement.Revenue != 100000 || statement.Amount != 100000 {
+		t.Errorf("got statement %+v, want 100000 revenue and paid", statement)
+	}
+}
@@ -0,0 +1,307 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/money"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PricePlanHandler provides methods for price plan management.
+type PricePlanHandler struct {
+	DB  *gorm.DB
+	Cfg *config.Config
+}
+
+// NewPricePlanHandler creates a new PricePlanHandler instance.
+func NewPricePlanHandler(db *gorm.DB, cfg *config.Config) *PricePlanHandler {
+	return &PricePlanHandler{DB: db, Cfg: cfg}
+}
+
+// PricePlanRequest defines the request body for creating or updating a price plan.
+type PricePlanRequest struct {
+	Name           string                `json:"name" binding:"required"`
+	EnrollmentType models.EnrollmentType `json:"enrollmentType" binding:"required,oneof=pre_session monthly six_month yearly pack"`
+	// CourseID limits the plan to a single course, CourseType to the courses
+	// of a type. With neither the plan applies to every course.
+	CourseID       *uint         `json:"courseId"`
+	CourseType     string        `json:"courseType"`
+	Sessions       int           `json:"sessions" binding:"min=0"`
+	SessionLimit   int           `json:"sessionLimit" binding:"min=0"`
+	DurationMonths int           `json:"durationMonths" binding:"required,min=1"`
+	Discount       float64       `json:"discount" binding:"min=0,max=1"`
+	FixedPrice     *money.Amount `json:"fixedPrice" binding:"omitempty,min=0"`
+	Active         *bool         `json:"active"` // Defaults to true
+	// PackCourseType, PackLevel and PackInstructorID restrict the courses a
+	// pack can be booked in. They are ignored for other enrollment types.
+	PackCourseType   string             `json:"packCourseType"`
+	PackLevel        models.CourseLevel `json:"packLevel" binding:"omitempty,oneof=beginner intermediate advanced"`
+	PackInstructorID *uuid.UUID         `json:"packInstructorId"`
+}
+
+// PlanQuote is a price plan with its price for a course. Price is the gross
+// price charged, NetPrice plus Tax, in minor units of Currency.
+type PlanQuote struct {
+	models.PricePlan
+	Currency        money.Currency `json:"currency"`
+	Price           money.Amount   `json:"price"`
+	NetPrice        money.Amount   `json:"netPrice"`
+	Tax             money.Amount   `json:"tax"`
+	TaxRate         float64        `json:"taxRate"`
+	DiscountApplied float64        `json:"discountApplied"`
+	// PriceDisplay is Price formatted in the studio's display currency,
+	// e.g. "125,000 Toman".
+	PriceDisplay string `json:"priceDisplay"`
+}
+
+// GetCoursePlans godoc
+// @Summary Get the price plans of a course
+// @Description Retrieve the active price plans that can be bought for a course, with a quote for each. The quoted price includes tax, which is added on top of or included in the course price as the studio is configured.
+// @Tags Price Plans
+// @Produce json
+// @Param id path int true "Course ID"
+// @Success 200 {array} PlanQuote
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 404 {object} map[string]string "error: Course not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /courses/{id}/plans [get]
+func (h *PricePlanHandler) GetCoursePlans(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	var course models.Course
+	if err := h.DB.First(&course, uint(courseID)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch course"})
+		return
+	}
+
+	plans, err := applicablePlans(h.DB, &course)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch price plans"})
+		return
+	}
+
+	quotes := make([]PlanQuote, len(plans))
+	for i := range plans {
+		price, discount := plans[i].Quote(&course)
+		net, tax, gross := h.Cfg.Tax.Apply(price)
+		quotes[i] = PlanQuote{PricePlan: plans[i], Currency: h.Cfg.Currency, Price: gross, NetPrice: net, Tax: tax,
+			TaxRate: h.Cfg.Tax.Rate, DiscountApplied: discount, PriceDisplay: h.Cfg.FormatMoney(gross, h.Cfg.Currency)}
+	}
+
+	c.JSON(http.StatusOK, quotes)
+}
+
+// GetPlans godoc
+// @Summary Get all price plans (Admin only)
+// @Description Retrieve all price plans, including inactive ones.
+// @Tags Price Plans
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.PricePlan
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /plans [get]
+func (h *PricePlanHandler) GetPlans(c *gin.Context) {
+	var plans []models.PricePlan
+	if err := h.DB.Order("id").Find(&plans).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch price plans"})
+		return
+	}
+	c.JSON(http.StatusOK, plans)
+}
+
+// CreatePlan godoc
+// @Summary Create a price plan (Admin only)
+// @Description Create a price plan for a course, a course type or all courses. The price is the course's per session price times the plan's sessions less the discount, unless a fixed price is set. A pack plan sells a number of sessions bookable in any course matching its pack filter.
+// @Tags Price Plans
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param plan body PricePlanRequest true "Price plan details"
+// @Success 201 {object} models.PricePlan
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /plans [post]
+func (h *PricePlanHandler) CreatePlan(c *gin.Context) {
+	var plan models.PricePlan
+	if !h.bindPlan(c, &plan) {
+		return
+	}
+
+	if err := h.DB.Create(&plan).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create price plan"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, plan)
+}
+
+// UpdatePlan godoc
+// @Summary Update a price plan (Admin only)
+// @Description Update a price plan. Existing enrollments keep the price they were bought at.
+// @Tags Price Plans
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Price plan ID"
+// @Param plan body PricePlanRequest true "Price plan details"
+// @Success 200 {object} models.PricePlan
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Price plan not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /plans/{id} [put]
+func (h *PricePlanHandler) UpdatePlan(c *gin.Context) {
+	plan, ok := h.findPlan(c)
+	if !ok {
+		return
+	}
+	if !h.bindPlan(c, plan) {
+		return
+	}
+
+	// Save writes all fields, so clearing the fixed price or course works
+	if err := h.DB.Save(plan).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update price plan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// DeletePlan godoc
+// @Summary Delete a price plan (Admin only)
+// @Description Delete a price plan. Enrollments bought with it are kept.
+// @Tags Price Plans
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Price plan ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Price plan not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /plans/{id} [delete]
+func (h *PricePlanHandler) DeletePlan(c *gin.Context) {
+	plan, ok := h.findPlan(c)
+	if !ok {
+		return
+	}
+
+	if err := h.DB.Delete(plan).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete price plan"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// findPlan fetches the price plan from the path, writing an error response
+// when it cannot be found.
+func (h *PricePlanHandler) findPlan(c *gin.Context) (*models.PricePlan, bool) {
+	planID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price plan ID"})
+		return nil, false
+	}
+
+	var plan models.PricePlan
+	if err := h.DB.First(&plan, uint(planID)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Price plan not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch price plan"})
+		return nil, false
+	}
+	return &plan, true
+}
+
+// bindPlan validates the request body and copies it into plan, writing an
+// error response when it is invalid.
+func (h *PricePlanHandler) bindPlan(c *gin.Context, plan *models.PricePlan) bool {
+	var req PricePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	if req.CourseID != nil && req.CourseType != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A plan applies to either a course or a course type, not both"})
+		return false
+	}
+	if req.FixedPrice == nil && req.Sessions == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A plan without a fixed price must price at least one session"})
+		return false
+	}
+	if req.CourseID != nil {
+		if err := h.DB.First(&models.Course{}, *req.CourseID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Course not found"})
+			return false
+		}
+	}
+	if req.EnrollmentType == models.Pack && req.SessionLimit == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A pack must limit the number of sessions"})
+		return false
+	}
+
+	plan.Name = req.Name
+	plan.EnrollmentType = req.EnrollmentType
+	plan.CourseID = req.CourseID
+	plan.CourseType = req.CourseType
+	plan.Sessions = req.Sessions
+	plan.SessionLimit = req.SessionLimit
+	plan.DurationMonths = req.DurationMonths
+	plan.Discount = req.Discount
+	plan.FixedPrice = req.FixedPrice
+	plan.Active = req.Active == nil || *req.Active
+	plan.PackFilter = models.CourseFilter{}
+	if req.EnrollmentType == models.Pack {
+		plan.PackFilter = models.CourseFilter{
+			CourseType:   req.PackCourseType,
+			Level:        req.PackLevel,
+			InstructorID: req.PackInstructorID,
+		}
+	}
+	return true
+}
+
+// applicablePlans returns the active price plans that can be bought for the
+// course, the most specific ones first.
+func applicablePlans(db *gorm.DB, course *models.Course) ([]models.PricePlan, error) {
+	var plans []models.PricePlan
+	err := db.Where("active = ? AND (course_id = ? OR (course_id IS NULL AND (course_type = '' OR course_type = ?)))",
+		true, course.ID, course.CourseType).
+		Order("course_id IS NULL, course_type = '', id").
+		Find(&plans).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Packs also filter the courses they can be bought for
+	applicable := plans[:0]
+	for _, plan := range plans {
+		if plan.Applies(course) {
+			applicable = append(applicable, plan)
+		}
+	}
+	return applicable, nil
+}
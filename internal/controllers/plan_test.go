@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/money"
+	"yoga-guru/internal/notify"
+	"yoga-guru/internal/payment"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetCoursePlansQuotesApplicablePlans(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	h := NewPricePlanHandler(db, &config.Config{})
+
+	course := models.Course{Title: "Vinyasa", CourseType: "Vinyasa", Price: 10}
+	other := models.Course{Title: "Hatha", CourseType: "Hatha", Price: 10}
+	db.Create(&course)
+	db.Create(&other)
+
+	fixed := money.Amount(25)
+	db.Create(&[]models.PricePlan{
+		{Name: "Monthly", EnrollmentType: models.Monthly, Sessions: 4, DurationMonths: 1, Discount: 0.10, Active: true},
+		{Name: "Vinyasa monthly", EnrollmentType: models.Monthly, CourseType: "Vinyasa", Sessions: 8, DurationMonths: 1, Discount: 0.5, Active: true},
+		{Name: "Trial", EnrollmentType: models.PreSession, CourseID: &course.ID, FixedPrice: &fixed, SessionLimit: 3, DurationMonths: 1, Active: true},
+		{Name: "Hatha yearly", EnrollmentType: models.Yearly, CourseType: "Hatha", Sessions: 48, DurationMonths: 12, Active: true},
+		{Name: "Retired", EnrollmentType: models.SixMonth, Sessions: 24, DurationMonths: 6, Active: false},
+	})
+
+	r := gin.New()
+	r.GET("/courses/:id/plans", h.GetCoursePlans)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/courses/%d/plans", course.ID), nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rr.Code, rr.Body)
+	}
+
+	var quotes []PlanQuote
+	if err := json.Unmarshal(rr.Body.Bytes(), &quotes); err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name  string
+		price money.Amount
+	}{{"Trial", 25}, {"Vinyasa monthly", 40}, {"Monthly", 36}}
+	if len(quotes) != len(want) {
+		t.Fatalf("got %d plans %+v, want %d", len(quotes), quotes, len(want))
+	}
+	for i, w := range want {
+		if quotes[i].Name != w.name || quotes[i].Price != w.price {
+			t.Errorf("plan %d: got %q at %v, want %q at %v", i, quotes[i].Name, quotes[i].Price, w.name, w.price)
+		}
+	}
+}
+
+func TestPackIsBookableAcrossMatchingCourses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	enrollments := NewEnrollmentHandler(db, &config.Config{}, notify.LogNotifier{}, payment.NewFakeGateway())
+	sessions := NewSessionHandler(db, &config.Config{JWTSecret: "secret"}, notify.LogNotifier{})
+
+	morning := models.Course{Title: "Morning Vinyasa", CourseType: "Vinyasa", Price: 10, Capacity: 5}
+	evening := models.Course{Title: "Evening Vinyasa", CourseType: "Vinyasa", Price: 10, Capacity: 5}
+	hatha := models.Course{Title: "Hatha", CourseType: "Hatha", Price: 10, Capacity: 5}
+	for _, course := range []*models.Course{&morning, &evening, &hatha} {
+		db.Create(course)
+	}
+	fixed := money.Amount(80)
+	pack := models.PricePlan{Name: "Vinyasa pack", EnrollmentType: models.Pack, FixedPrice: &fixed,
+		SessionLimit: 2, DurationMonths: 3, Active: true, PackFilter: models.CourseFilter{CourseType: "Vinyasa"}}
+	db.Create(&pack)
+
+	student := models.User{Phone: "+989120000001", Role: models.Student}
+	db.Create(&student)
+	r := gin.New()
+	r.Use(withUser(student.ID, models.Student))
+	r.POST("/enrollments", enrollments.EnrollInCourse)
+	r.POST("/sessions/:id/bookings", sessions.BookSession)
+
+	enroll := func(courseID uint) int {
+		rr := httptest.NewRecorder()
+		body := fmt.Sprintf(`{"CourseID": %d, "PlanID": %d}`, courseID, pack.ID)
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/enrollments", strings.NewReader(body)))
+		return rr.Code
+	}
+	if code := enroll(hatha.ID); code != http.StatusBadRequest {
+		t.Fatalf("buying the pack for a course it does not cover: got status %d", code)
+	}
+	if code := enroll(morning.ID); code != http.StatusCreated {
+		t.Fatalf("buying the pack: got status %d", code)
+	}
+
+	book := func(course *models.Course) (int, models.Booking) {
+		session := models.CourseSession{CourseID: course.ID, ScheduledAt: time.Now().Add(24 * time.Hour)}
+		db.Create(&session)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/sessions/%d/bookings", session.ID), nil))
+		var booking models.Booking
+		json.Unmarshal(rr.Body.Bytes(), &booking)
+		return rr.Code, booking
+	}
+	if code, _ := book(&hatha); code != http.StatusForbidden {
+		t.Errorf("booking a course outside the pack: got status %d", code)
+	}
+	for i, course := range []*models.Course{&evening, &morning} {
+		code, booking := book(course)
+		if code != http.StatusCreated {
+			t.Fatalf("booking %s: got status %d", course.Title, code)
+		}
+		if left := booking.Enrollment.SessionsLeft; left == nil || *left != 1-i {
+			t.Errorf("booking %s: got %v sessions left, want %d", course.Title, left, 1-i)
+		}
+	}
+	if code, _ := book(&evening); code != http.StatusConflict {
+		t.Errorf("booking past the pack's sessions: got status %d", code)
+	}
+}
@@ -0,0 +1,804 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/notify"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errSessionFull        = errors.New("session is full")
+	errAlreadyBooked      = errors.New("already booked")
+	errNoEnrollment       = errors.New("no enrollment covers this session")
+	errEnrollmentNotFound = errors.New("enrollment not found")
+	errNoSessionsLeft     = errors.New("no sessions left on enrollment")
+	errSessionNotFull     = errors.New("session is not full")
+	errBookingNotFound    = errors.New("booking not found")
+)
+
+// SessionHandler provides methods for course sessions and their bookings.
+type SessionHandler struct {
+	DB       *gorm.DB
+	Cfg      *config.Config
+	Notifier notify.Notifier
+}
+
+// NewSessionHandler creates a new SessionHandler instance.
+func NewSessionHandler(db *gorm.DB, cfg *config.Config, notifier notify.Notifier) *SessionHandler {
+	return &SessionHandler{DB: db, Cfg: cfg, Notifier: notifier}
+}
+
+// GetCourseSessions godoc
+// @Summary Get upcoming sessions of a course
+// @Description Retrieve the upcoming class instances of a course, including how many places are booked.
+// @Tags Sessions
+// @Produce json
+// @Param id path int true "Course ID"
+// @Success 200 {array} models.CourseSession
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /courses/{id}/sessions [get]
+func (h *SessionHandler) GetCourseSessions(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	var sessions []models.CourseSession
+	if err := h.DB.Where("course_id = ? AND scheduled_at >= ?", courseID, time.Now()).
+		Order("scheduled_at").Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// BookSessionRequest defines the request body for booking a session.
+type BookSessionRequest struct {
+	// EnrollmentID selects the enrollment to book with. When omitted the
+	// first enrollment covering the session is used.
+	EnrollmentID uint `json:"enrollmentId"`
+}
+
+// BookSession godoc
+// @Summary Book a session (Student/Admin only)
+// @Description Book the current user into a specific course session using one of their enrollments. Capacity is enforced per session. The booking's enrollment shows the sessions left on it.
+// @Tags Sessions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Session ID"
+// @Param booking body BookSessionRequest false "Enrollment to book with"
+// @Success 201 {object} models.Booking
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Session not found"
+// @Failure 409 {object} map[string]string "error: Session full or already booked"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /sessions/{id}/bookings [post]
+func (h *SessionHandler) BookSession(c *gin.Context) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	studentID := uuid.MustParse(userIDAny.(string))
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var req BookSessionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	session, ok := h.bookableSession(c, uint(sessionID))
+	if !ok {
+		return
+	}
+
+	var booking models.Booking
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		// Take the place first: the guarded update is atomic, and as a write
+		// it also serializes concurrent bookings for the rest of the transaction.
+		result := tx.Model(&models.CourseSession{}).
+			Where("id = ? AND booked_count < (SELECT capacity FROM courses WHERE courses.id = course_sessions.course_id)", session.ID).
+			Update("booked_count", gorm.Expr("booked_count + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errSessionFull
+		}
+
+		if err := checkNotBooked(tx, session.ID, studentID); err != nil {
+			return err
+		}
+
+		enrollment, err := findBookableEnrollment(tx, studentID, session, req.EnrollmentID)
+		if err != nil {
+			return err
+		}
+
+		booking = models.Booking{
+			CourseSessionID: session.ID,
+			EnrollmentID:    enrollment.ID,
+			UserID:          studentID,
+			Status:          models.BookingBooked,
+		}
+		if err := tx.Create(&booking).Error; err != nil {
+			return err
+		}
+		booking.Enrollment = *enrollment
+		return nil
+	})
+	if err != nil {
+		respondBookingError(c, err, "Failed to book session")
+		return
+	}
+	if err := loadBalances(h.DB, &booking.Enrollment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute enrollment balance"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, booking)
+}
+
+// JoinWaitlist godoc
+// @Summary Join the waitlist of a full session (Student/Admin only)
+// @Description Queue the current user for a full session. When a booking is cancelled the first eligible student on the waitlist is booked automatically and notified.
+// @Tags Sessions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Session ID"
+// @Param booking body BookSessionRequest false "Enrollment to book with once promoted"
+// @Success 201 {object} models.Booking
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Session not found"
+// @Failure 409 {object} map[string]string "error: Session not full or already booked"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /sessions/{id}/waitlist [post]
+func (h *SessionHandler) JoinWaitlist(c *gin.Context) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	studentID := uuid.MustParse(userIDAny.(string))
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var req BookSessionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	session, ok := h.bookableSession(c, uint(sessionID))
+	if !ok {
+		return
+	}
+
+	var booking models.Booking
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		var full int64
+		if err := tx.Model(&models.CourseSession{}).
+			Where("id = ? AND booked_count >= (SELECT capacity FROM courses WHERE courses.id = course_sessions.course_id)", session.ID).
+			Count(&full).Error; err != nil {
+			return err
+		}
+		if full == 0 {
+			return errSessionNotFull
+		}
+
+		if err := checkNotBooked(tx, session.ID, studentID); err != nil {
+			return err
+		}
+
+		// The enrollment must be able to take the place once promoted
+		enrollment, err := findBookableEnrollment(tx, studentID, session, req.EnrollmentID)
+		if err != nil {
+			return err
+		}
+
+		var last int
+		if err := tx.Model(&models.Booking{}).
+			Where("course_session_id = ? AND status = ?", session.ID, models.BookingWaitlisted).
+			Select("COALESCE(MAX(position), 0)").Scan(&last).Error; err != nil {
+			return err
+		}
+
+		booking = models.Booking{
+			CourseSessionID: session.ID,
+			EnrollmentID:    enrollment.ID,
+			UserID:          studentID,
+			Status:          models.BookingWaitlisted,
+			Position:        last + 1,
+		}
+		return tx.Create(&booking).Error
+	})
+	if err != nil {
+		respondBookingError(c, err, "Failed to join waitlist")
+		return
+	}
+
+	c.JSON(http.StatusCreated, booking)
+}
+
+// bookableSession fetches a session that can still be booked, writing the
+// error response and returning false otherwise.
+func (h *SessionHandler) bookableSession(c *gin.Context, sessionID uint) (*models.CourseSession, bool) {
+	var session models.CourseSession
+	if err := h.DB.Preload("Course").First(&session, sessionID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch session"})
+		return nil, false
+	}
+
+	if session.IsCanceled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Session is canceled"})
+		return nil, false
+	}
+	if !session.ScheduledAt.After(time.Now()) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Session has already started"})
+		return nil, false
+	}
+	return &session, true
+}
+
+// respondBookingError maps booking errors to their HTTP responses.
+func respondBookingError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case errSessionFull:
+		c.JSON(http.StatusConflict, gin.H{"error": "Session is full, join the waitlist instead"})
+	case errSessionNotFull:
+		c.JSON(http.StatusConflict, gin.H{"error": "Session has free places, book it instead"})
+	case errAlreadyBooked:
+		c.JSON(http.StatusConflict, gin.H{"error": "You have already booked this session"})
+	case errEnrollmentNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Enrollment not found"})
+	case errNoEnrollment:
+		c.JSON(http.StatusForbidden, gin.H{"error": "You have no enrollment covering this session"})
+	case errMemberLimitReached:
+		c.JSON(http.StatusConflict, gin.H{"error": "You have booked all the sessions your household allows you on this enrollment"})
+	case errNoSessionsLeft:
+		c.JSON(http.StatusConflict, gin.H{"error": "No sessions left on this enrollment"})
+	case errEnrollmentFrozen:
+		c.JSON(http.StatusConflict, gin.H{"error": "Your enrollment is frozen on the date of this session"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// checkNotBooked returns errAlreadyBooked if the user holds a booking or a
+// waitlist place for the session.
+func checkNotBooked(tx *gorm.DB, sessionID uint, userID uuid.UUID) error {
+	var existing int64
+	if err := tx.Model(&models.Booking{}).
+		Where("course_session_id = ? AND user_id = ? AND status IN ?", sessionID, userID,
+			[]models.BookingStatus{models.BookingBooked, models.BookingWaitlisted}).
+		Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		return errAlreadyBooked
+	}
+	return nil
+}
+
+// CancelBooking godoc
+// @Summary Cancel a session booking (Student/Admin only)
+// @Description Cancel a booking or leave the waitlist. A released place goes to the first eligible student on the waitlist.
+// @Tags Sessions
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Session ID"
+// @Param bookingID path int true "Booking ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Booking not found"
+// @Failure 409 {object} map[string]string "error: Session has already started"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /sessions/{id}/bookings/{bookingID} [delete]
+func (h *SessionHandler) CancelBooking(c *gin.Context) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	currentUserID := uuid.MustParse(userIDAny.(string))
+	userRoleAny := c.MustGet("userRole")
+	currentUserRole := userRoleAny.(models.UserRole)
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+	bookingID, err := strconv.ParseUint(c.Param("bookingID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return
+	}
+
+	var booking models.Booking
+	if err := h.DB.Preload("CourseSession").
+		Where("course_session_id = ? AND status IN ?", sessionID,
+			[]models.BookingStatus{models.BookingBooked, models.BookingWaitlisted}).
+		First(&booking, uint(bookingID)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch booking"})
+		return
+	}
+
+	// Only admin or the booked student can cancel this booking
+	if currentUserRole != models.Admin && booking.UserID != currentUserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to cancel this booking"})
+		return
+	}
+
+	if !booking.CourseSession.ScheduledAt.After(time.Now()) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Session has already started"})
+		return
+	}
+
+	var promoted *models.Booking
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		promoted, err = cancelBooking(tx, &booking)
+		return err
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel booking"})
+		return
+	}
+	notifyPromoted(h.Notifier, promoted)
+
+	c.Status(http.StatusNoContent)
+}
+
+// cancelBooking marks a booking as cancelled. A released place is given to
+// the waitlist, the promoted booking is returned so its student can be
+// notified once the transaction commits.
+func cancelBooking(tx *gorm.DB, booking *models.Booking) (*models.Booking, error) {
+	wasBooked := booking.Status == models.BookingBooked
+
+	now := time.Now()
+	result := tx.Model(booking).
+		Where("status = ?", booking.Status).
+		Updates(models.Booking{Status: models.BookingCancelled, CancelledAt: &now})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 || !wasBooked {
+		// Already cancelled by a concurrent request, or only left the waitlist
+		return nil, nil
+	}
+
+	if err := tx.Model(&models.CourseSession{}).
+		Where("id = ? AND booked_count > 0", booking.CourseSessionID).
+		Update("booked_count", gorm.Expr("booked_count - 1")).Error; err != nil {
+		return nil, err
+	}
+	return promoteWaitlist(tx, booking.CourseSessionID)
+}
+
+// promoteWaitlist books the first eligible waitlisted student into a free
+// place of the session. Students whose enrollment can no longer be used keep
+// their waitlist position.
+func promoteWaitlist(tx *gorm.DB, sessionID uint) (*models.Booking, error) {
+	var waiting []models.Booking
+	if err := tx.Preload("CourseSession.Course").Preload("Enrollment").Preload("User").
+		Where("course_session_id = ? AND status = ?", sessionID, models.BookingWaitlisted).
+		Order("position, id").Find(&waiting).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range waiting {
+		candidate := &waiting[i]
+		if !enrollmentCovers(&candidate.Enrollment, &candidate.CourseSession) {
+			continue
+		}
+		frozen, err := isFrozen(tx, candidate.EnrollmentID, candidate.CourseSession.ScheduledAt)
+		if err != nil {
+			return nil, err
+		}
+		if frozen {
+			continue
+		}
+		ok, err := hasSessionsLeft(tx, &candidate.Enrollment)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		ok, err = withinMemberLimit(tx, candidate.UserID, &candidate.Enrollment)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		result := tx.Model(&models.CourseSession{}).
+			Where("id = ? AND booked_count < (SELECT capacity FROM courses WHERE courses.id = course_sessions.course_id)", sessionID).
+			Update("booked_count", gorm.Expr("booked_count + 1"))
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil, nil
+		}
+
+		candidate.Status = models.BookingBooked
+		candidate.Position = 0
+		if err := tx.Model(candidate).Select("Status", "Position").Updates(candidate).Error; err != nil {
+			return nil, err
+		}
+		return candidate, nil
+	}
+	return nil, nil
+}
+
+// notifyPromoted tells a student their waitlist place turned into a booking.
+func notifyPromoted(notifier notify.Notifier, booking *models.Booking) {
+	if booking == nil {
+		return
+	}
+	message := fmt.Sprintf("A place opened up: you are now booked for %s on %s.",
+		booking.CourseSession.Course.Title, booking.CourseSession.ScheduledAt.Format("Mon Jan 2 15:04"))
+	if err := notifier.Notify(booking.User, message); err != nil {
+		log.Printf("failed to notify user %s of booking %d: %v", booking.UserID, booking.ID, err)
+	}
+}
+
+// findBookableEnrollment returns the enrollment the user books the session
+// with, one of their own or one of their household owner's. When
+// enrollmentID is zero the first enrollment covering the session is picked,
+// the user's own before the household's and enrollments in the session's
+// course before packs. The session's course must be loaded.
+func findBookableEnrollment(tx *gorm.DB, userID uuid.UUID, session *models.CourseSession, enrollmentID uint) (*models.Enrollment, error) {
+	holders, err := enrollmentHolders(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if enrollmentID != 0 {
+		var enrollment models.Enrollment
+		if err := tx.Where("user_id IN ?", holders).First(&enrollment, enrollmentID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, errEnrollmentNotFound
+			}
+			return nil, err
+		}
+		if !enrollmentCovers(&enrollment, session) {
+			return nil, errNoEnrollment
+		}
+		if frozen, err := isFrozen(tx, enrollment.ID, session.ScheduledAt); err != nil {
+			return nil, err
+		} else if frozen {
+			return nil, errEnrollmentFrozen
+		}
+		if ok, err := hasSessionsLeft(tx, &enrollment); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, errNoSessionsLeft
+		}
+		if ok, err := withinMemberLimit(tx, userID, &enrollment); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, errMemberLimitReached
+		}
+		return &enrollment, nil
+	}
+
+	var enrollments []models.Enrollment
+	if err := tx.Where("user_id IN ? AND (course_id = ? OR enrollment_type = ?)", holders, session.CourseID, models.Pack).
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "user_id <> ?", Vars: []any{userID}}}).
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "enrollment_type = ?", Vars: []any{models.Pack}}}).
+		Order("expiration_date").Find(&enrollments).Error; err != nil {
+		return nil, err
+	}
+
+	found, foundFrozen, limited := false, false, false
+	for i := range enrollments {
+		if !enrollmentCovers(&enrollments[i], session) {
+			continue
+		}
+		frozen, err := isFrozen(tx, enrollments[i].ID, session.ScheduledAt)
+		if err != nil {
+			return nil, err
+		}
+		if frozen {
+			foundFrozen = true
+			continue
+		}
+		found = true
+		ok, err := hasSessionsLeft(tx, &enrollments[i])
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		ok, err = withinMemberLimit(tx, userID, &enrollments[i])
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &enrollments[i], nil
+		}
+		limited = true
+	}
+	if limited {
+		return nil, errMemberLimitReached
+	}
+	if found {
+		return nil, errNoSessionsLeft
+	}
+	if foundFrozen {
+		return nil, errEnrollmentFrozen
+	}
+	return nil, errNoEnrollment
+}
+
+// enrollmentCovers reports whether the enrollment can be used for the session.
+// A pack covers the sessions of every course matching its filter, so the
+// session's course must be loaded.
+func enrollmentCovers(enrollment *models.Enrollment, session *models.CourseSession) bool {
+	return enrollment.Status == models.EnrollmentActive &&
+		(enrollment.CourseID == session.CourseID ||
+			(enrollment.EnrollmentType == models.Pack && enrollment.PackFilter.Matches(&session.Course))) &&
+		!session.ScheduledAt.Before(enrollment.StartDate) &&
+		!session.ScheduledAt.After(enrollment.ExpirationDate)
+}
+
+// hasSessionsLeft reports whether a session package still has a session to
+// spend. Active bookings hold a session until they are cancelled.
+func hasSessionsLeft(tx *gorm.DB, enrollment *models.Enrollment) (bool, error) {
+	if enrollment.TotalSessions == 0 {
+		return true, nil
+	}
+
+	var booked int64
+	if err := tx.Model(&models.Booking{}).
+		Where("enrollment_id = ? AND status = ?", enrollment.ID, models.BookingBooked).
+		Count(&booked).Error; err != nil {
+		return false, err
+	}
+	return enrollment.SessionsUsed+int(booked) < enrollment.TotalSessions, nil
+}
+
+// RosterEntry describes a booked student in a session roster.
+type RosterEntry struct {
+	BookingID    uint                 `json:"bookingId"`
+	EnrollmentID uint                 `json:"enrollmentId"`
+	UserID       uuid.UUID            `json:"userId"`
+	Name         string               `json:"name"`
+	Phone        string               `json:"phone"`
+	Status       models.BookingStatus `json:"status"`
+}
+
+// GetRoster godoc
+// @Summary Get the roster of a session (Instructor/Admin only)
+// @Description List the students booked into a session along with their attendance status. Only the course instructor or an admin can view it.
+// @Tags Sessions
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Session ID"
+// @Success 200 {array} RosterEntry
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Session not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /sessions/{id}/roster [get]
+func (h *SessionHandler) GetRoster(c *gin.Context) {
+	session, ok := h.managedSession(c)
+	if !ok {
+		return
+	}
+
+	var bookings []models.Booking
+	if err := h.DB.Preload("User.Profile").
+		Where("course_session_id = ? AND status IN ?", session.ID,
+			[]models.BookingStatus{models.BookingBooked, models.BookingAttended, models.BookingNoShow}).
+		Order("id").Find(&bookings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch roster"})
+		return
+	}
+
+	roster := make([]RosterEntry, len(bookings))
+	for i, booking := range bookings {
+		roster[i] = RosterEntry{
+			BookingID:    booking.ID,
+			EnrollmentID: booking.EnrollmentID,
+			UserID:       booking.UserID,
+			Name:         booking.User.Profile.Name,
+			Phone:        booking.User.Phone,
+			Status:       booking.Status,
+		}
+	}
+
+	c.JSON(http.StatusOK, roster)
+}
+
+// AttendanceRecord marks a single booking as attended or not.
+type AttendanceRecord struct {
+	BookingID uint `json:"bookingId" binding:"required"`
+	Attended  bool `json:"attended"`
+}
+
+// RecordAttendanceRequest defines the request body for recording attendance.
+type RecordAttendanceRequest struct {
+	Records []AttendanceRecord `json:"records" binding:"required,min=1,dive"`
+}
+
+// RecordAttendance godoc
+// @Summary Record attendance for a session (Instructor/Admin only)
+// @Description Mark booked students as attended or no-show in bulk. The first mark of a booking spends a session of its enrollment, later marks only correct the attendance. Only the course instructor or an admin can record attendance.
+// @Tags Sessions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Session ID"
+// @Param attendance body RecordAttendanceRequest true "Attendance records"
+// @Success 200 {array} models.Attendance
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Session or booking not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /sessions/{id}/attendance [post]
+func (h *SessionHandler) RecordAttendance(c *gin.Context) {
+	session, ok := h.managedSession(c)
+	if !ok {
+		return
+	}
+
+	var req RecordAttendanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	attendances := make([]models.Attendance, 0, len(req.Records))
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		for _, record := range req.Records {
+			var booking models.Booking
+			if err := tx.Where("course_session_id = ? AND status IN ?", session.ID,
+				[]models.BookingStatus{models.BookingBooked, models.BookingAttended, models.BookingNoShow}).
+				First(&booking, record.BookingID).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return errBookingNotFound
+				}
+				return err
+			}
+
+			attendance, err := recordAttendance(tx, &booking, record.Attended)
+			if err != nil {
+				return err
+			}
+			attendances = append(attendances, *attendance)
+		}
+		return nil
+	})
+	if err != nil {
+		if err == errBookingNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found in this session"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record attendance"})
+		return
+	}
+
+	c.JSON(http.StatusOK, attendances)
+}
+
+// managedSession fetches the session from the path and checks that the
+// current user may manage its course, writing the error response and
+// returning false otherwise.
+func (h *SessionHandler) managedSession(c *gin.Context) (*models.CourseSession, bool) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return nil, false
+	}
+	currentUserID := uuid.MustParse(userIDAny.(string))
+	userRoleAny := c.MustGet("userRole")
+	currentUserRole := userRoleAny.(models.UserRole)
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return nil, false
+	}
+
+	var session models.CourseSession
+	if err := h.DB.Preload("Course").First(&session, uint(sessionID)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch session"})
+		return nil, false
+	}
+
+	if !canManageCourse(&session.Course, currentUserID, currentUserRole) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to manage this session"})
+		return nil, false
+	}
+	return &session, true
+}
+
+// recordAttendance writes the attendance of a booking. The first time a
+// booking is marked, attended or not, it spends a session of its enrollment.
+func recordAttendance(tx *gorm.DB, booking *models.Booking, attended bool) (*models.Attendance, error) {
+	status := models.BookingNoShow
+	if attended {
+		status = models.BookingAttended
+	}
+
+	if booking.Status == models.BookingBooked {
+		if err := tx.Model(&models.Enrollment{}).Where("id = ?", booking.EnrollmentID).
+			Update("sessions_used", gorm.Expr("sessions_used + 1")).Error; err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Model(booking).Update("status", status).Error; err != nil {
+		return nil, err
+	}
+
+	attendance := models.Attendance{
+		UserID:          booking.UserID,
+		CourseSessionID: booking.CourseSessionID,
+		BookingID:       booking.ID,
+		EnrollmentID:    booking.EnrollmentID,
+		Attended:        attended,
+		RecordedAt:      time.Now(),
+	}
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "course_session_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"attended", "recorded_at", "updated_at"}),
+	}).Create(&attendance).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attendance, nil
+}
@@ -0,0 +1,356 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/middleware"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/notify"
+	"yoga-guru/internal/payment"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB opens a migrated SQLite database in a temporary directory.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.AutoMigrate(
+		&models.User{},
+		&models.Profile{},
+		&models.Course{},
+		&models.Schedule{},
+		&models.CourseSession{},
+		&models.Enrollment{},
+		&models.Booking{},
+		&models.Attendance{},
+		&models.Payment{},
+		&models.PricePlan{},
+		&models.Coupon{},
+		&models.CouponRedemption{},
+		&models.Wallet{},
+		&models.CreditTransaction{},
+		&models.GiftCard{},
+		&models.EnrollmentFreeze{},
+		&models.Household{},
+		&models.HouseholdMember{},
+		&models.EnrollmentTransfer{},
+		&models.Invoice{},
+		&models.InvoiceSequence{},
+		&models.PayoutRule{},
+		&models.PayoutStatement{},
+		&models.PayoutLine{},
+		&models.OneTimeCode{},
+		&models.DeviceSession{},
+		&models.RefreshToken{},
+		&models.LoginThrottle{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// withUser authenticates requests the way AuthMiddleware does.
+func withUser(userID uuid.UUID, role models.UserRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("userID", userID.String())
+		c.Set("userRole", role)
+		c.Next()
+	}
+}
+
+func TestBookSessionEnforcesCapacity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	h := NewSessionHandler(db, &config.Config{JWTSecret: "secret"}, notify.LogNotifier{})
+
+	course := models.Course{Title: "Vinyasa", Capacity: 2}
+	db.Create(&course)
+	session := models.CourseSession{CourseID: course.ID, ScheduledAt: time.Now().Add(24 * time.Hour)}
+	db.Create(&session)
+
+	const students = 6
+	routers := make([]*gin.Engine, students)
+	for i := range routers {
+		user := models.User{Phone: fmt.Sprintf("+98912000000%d", i), Role: models.Student}
+		db.Create(&user)
+		db.Create(&models.Enrollment{
+			UserID:         user.ID,
+			CourseID:       course.ID,
+			EnrollmentType: models.Monthly,
+			StartDate:      time.Now(),
+			ExpirationDate: time.Now().AddDate(0, 1, 0),
+		})
+		routers[i] = gin.New()
+		routers[i].Use(withUser(user.ID, models.Student))
+		routers[i].POST("/sessions/:id/bookings", h.BookSession)
+	}
+
+	var wg sync.WaitGroup
+	codes := make(chan int, students)
+	for _, r := range routers {
+		wg.Go(func() {
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/sessions/%d/bookings", session.ID), nil)
+			r.ServeHTTP(rr, req)
+			codes <- rr.Code
+		})
+	}
+	wg.Wait()
+	close(codes)
+
+	created := 0
+	for code := range codes {
+		switch code {
+		case http.StatusCreated:
+			created++
+		case http.StatusConflict:
+		default:
+			t.Errorf("unexpected status %d", code)
+		}
+	}
+	if created != course.Capacity {
+		t.Errorf("got %d bookings, want %d", created, course.Capacity)
+	}
+
+	db.First(&session, session.ID)
+	if session.BookedCount != course.Capacity {
+		t.Errorf("got booked count %d, want %d", session.BookedCount, course.Capacity)
+	}
+}
+
+// recordingNotifier remembers who was notified.
+type recordingNotifier struct {
+	mu    sync.Mutex
+	users []uuid.UUID
+}
+
+func (n *recordingNotifier) Notify(user models.User, message string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.users = append(n.users, user.ID)
+	return nil
+}
+
+func TestCancelEnrollmentPromotesWaitlist(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	notifier := &recordingNotifier{}
+	sessions := NewSessionHandler(db, &config.Config{JWTSecret: "secret"}, notifier)
+	enrollments := NewEnrollmentHandler(db, &config.Config{}, notifier, payment.NewFakeGateway())
+
+	course := models.Course{Title: "Hatha", Capacity: 1}
+	db.Create(&course)
+	session := models.CourseSession{CourseID: course.ID, ScheduledAt: time.Now().Add(24 * time.Hour)}
+	db.Create(&session)
+
+	var users [2]models.User
+	var userEnrollments [2]models.Enrollment
+	for i := range users {
+		users[i] = models.User{Phone: fmt.Sprintf("+98912100000%d", i), Role: models.Student}
+		db.Create(&users[i])
+		userEnrollments[i] = models.Enrollment{
+			UserID:         users[i].ID,
+			CourseID:       course.ID,
+			EnrollmentType: models.Monthly,
+			StartDate:      time.Now(),
+			ExpirationDate: time.Now().AddDate(0, 1, 0),
+		}
+		db.Create(&userEnrollments[i])
+	}
+
+	serve := func(user models.User, method, path string, handler gin.HandlerFunc, route string) int {
+		r := gin.New()
+		r.Use(withUser(user.ID, models.Student))
+		r.Handle(method, route, handler)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(method, path, nil))
+		return rr.Code
+	}
+
+	bookPath := fmt.Sprintf("/sessions/%d/bookings", session.ID)
+	waitlistPath := fmt.Sprintf("/sessions/%d/waitlist", session.ID)
+	if code := serve(users[0], http.MethodPost, bookPath, sessions.BookSession, "/sessions/:id/bookings"); code != http.StatusCreated {
+		t.Fatalf("first booking: got status %d", code)
+	}
+	if code := serve(users[1], http.MethodPost, bookPath, sessions.BookSession, "/sessions/:id/bookings"); code != http.StatusConflict {
+		t.Fatalf("booking a full session: got status %d", code)
+	}
+	if code := serve(users[1], http.MethodPost, waitlistPath, sessions.JoinWaitlist, "/sessions/:id/waitlist"); code != http.StatusCreated {
+		t.Fatalf("joining the waitlist: got status %d", code)
+	}
+
+	cancelPath := fmt.Sprintf("/enrollments/%d", userEnrollments[0].ID)
+	if code := serve(users[0], http.MethodDelete, cancelPath, enrollments.CancelEnrollment, "/enrollments/:id"); code != http.StatusOK {
+		t.Fatalf("cancelling the enrollment: got status %d", code)
+	}
+
+	var promoted models.Booking
+	if err := db.Where("user_id = ? AND course_session_id = ?", users[1].ID, session.ID).First(&promoted).Error; err != nil {
+		t.Fatal(err)
+	}
+	if promoted.Status != models.BookingBooked {
+		t.Errorf("got waitlisted booking status %q, want %q", promoted.Status, models.BookingBooked)
+	}
+	if len(notifier.users) != 1 || notifier.users[0] != users[1].ID {
+		t.Errorf("got notifications for %v, want only %s", notifier.users, users[1].ID)
+	}
+
+	db.First(&session, session.ID)
+	if session.BookedCount != 1 {
+		t.Errorf("got booked count %d, want 1", session.BookedCount)
+	}
+
+	var cancelled models.Enrollment
+	if err := db.First(&cancelled, userEnrollments[0].ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if cancelled.Status != models.EnrollmentCancelled {
+		t.Errorf("got enrollment status %q, want %q", cancelled.Status, models.EnrollmentCancelled)
+	}
+}
+
+func TestDeleteCourseCancelsBookings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	notifier := &recordingNotifier{}
+	h := NewCourseHandler(db, &config.Config{}, notifier)
+
+	admin := models.User{Phone: "+989121000010", Role: models.Admin}
+	booked := models.User{Phone: "+989121000011", Role: models.Student}
+	waitlisted := models.User{Phone: "+989121000012", Role: models.Student}
+	for _, user := range []*models.User{&admin, &booked, &waitlisted} {
+		db.Create(user)
+	}
+	course := models.Course{Title: "Ashtanga", Capacity: 1}
+	db.Create(&course)
+	session := models.CourseSession{CourseID: course.ID, ScheduledAt: time.Now().Add(24 * time.Hour), BookedCount: 1}
+	db.Create(&session)
+	for _, booking := range []models.Booking{
+		{UserID: booked.ID, CourseSessionID: session.ID, Status: models.BookingBooked},
+		{UserID: waitlisted.ID, CourseSessionID: session.ID, Status: models.BookingWaitlisted},
+	} {
+		db.Create(&booking)
+	}
+
+	r := gin.New()
+	r.Use(withUser(admin.ID, models.Admin))
+	r.DELETE("/courses/:id", h.DeleteCourse)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/courses/%d", course.ID), nil))
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("deleting the course: got status %d: %s", rr.Code, rr.Body)
+	}
+
+	var open int64
+	db.Model(&models.Booking{}).Where("status <> ?", models.BookingCancelled).Count(&open)
+	if open != 0 {
+		t.Errorf("got %d bookings left open, want none", open)
+	}
+	if len(notifier.users) != 2 {
+		t.Errorf("got notifications for %v, want both students", notifier.users)
+	}
+}
+
+func TestRecordAttendanceSpendsSessionOnce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	h := NewSessionHandler(db, &config.Config{JWTSecret: "secret"}, notify.LogNotifier{})
+
+	instructor := models.User{Phone: "+989120000100", Role: models.Instructor}
+	student := models.User{Phone: "+989120000101", Role: models.Student}
+	db.Create(&instructor)
+	db.Create(&student)
+	course := models.Course{Title: "Ashtanga", Capacity: 10, InstructorID: instructor.ID}
+	db.Create(&course)
+	session := models.CourseSession{CourseID: course.ID, ScheduledAt: time.Now(), BookedCount: 1}
+	db.Create(&session)
+	enrollment := models.Enrollment{UserID: student.ID, CourseID: course.ID, TotalSessions: 10}
+	db.Create(&enrollment)
+	booking := models.Booking{CourseSessionID: session.ID, EnrollmentID: enrollment.ID, UserID: student.ID, Status: models.BookingBooked}
+	db.Create(&booking)
+
+	r := gin.New()
+	r.Use(withUser(instructor.ID, models.Instructor))
+	r.POST("/sessions/:id/attendance", h.RecordAttendance)
+
+	for _, attended := range []bool{false, true} {
+		body := fmt.Sprintf(`{"records":[{"bookingId":%d,"attended":%t}]}`, booking.ID, attended)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/sessions/%d/attendance", session.ID), strings.NewReader(body)))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("got status %d: %s", rr.Code, rr.Body)
+		}
+	}
+
+	var attendances []models.Attendance
+	db.Find(&attendances)
+	if len(attendances) != 1 || !attendances[0].Attended {
+		t.Errorf("got attendances %+v, want a single attended record", attendances)
+	}
+	db.First(&enrollment, enrollment.ID)
+	if enrollment.SessionsUsed != 1 {
+		t.Errorf("got %d sessions used, want 1", enrollment.SessionsUsed)
+	}
+}
+
+func TestCheckInWithSessionToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	cfg := &config.Config{JWTSecret: "secret"}
+	h := NewSessionHandler(db, cfg, notify.LogNotifier{})
+
+	student := models.User{Phone: "+989120000200", Role: models.Student}
+	db.Create(&student)
+	course := models.Course{Title: "Yin", Capacity: 10}
+	db.Create(&course)
+	session := models.CourseSession{CourseID: course.ID, ScheduledAt: time.Now().Add(10 * time.Minute), EndsAt: time.Now().Add(70 * time.Minute)}
+	db.Create(&session)
+	enrollment := models.Enrollment{UserID: student.ID, CourseID: course.ID}
+	db.Create(&enrollment)
+	db.Create(&models.Booking{CourseSessionID: session.ID, EnrollmentID: enrollment.ID, UserID: student.ID, Status: models.BookingBooked})
+
+	token, err := middleware.GenerateCheckInToken(session.ID, time.Now().Add(time.Minute), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A check-in token must not authenticate API requests
+	r := gin.New()
+	r.GET("/users/me", middleware.AuthMiddleware(cfg), func(c *gin.Context) { c.Status(http.StatusOK) })
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("check-in token as bearer token: got status %d, want %d", rr.Code, http.StatusUnauthorized)
+	}
+
+	r = gin.New()
+	r.Use(withUser(student.ID, models.Student))
+	r.POST("/checkin", h.CheckIn)
+	for _, want := range []int{http.StatusOK, http.StatusConflict} {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/checkin", strings.NewReader(`{"token":"`+token+`"}`)))
+		if rr.Code != want {
+			t.Fatalf("got status %d, want %d: %s", rr.Code, want, rr.Body)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+	"yoga-guru/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UnlockUser godoc
+// @Summary Unlock a user's login (Admin only)
+// @Description Forget the failed logins of a user's phone number, lifting any wait or lockout on it. Lockouts of IP addresses are not lifted.
+// @Tags Users
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "error: Invalid user ID"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: User not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /users/{id}/unlock [post]
+func (h *AuthHandler) UnlockUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+
+	if err := h.clearLoginFailures(user.Phone); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// loginBlocked responds with 429 and returns true when logins of phone or
+// from ip have to wait after too many failures.
+func (h *AuthHandler) loginBlocked(c *gin.Context, phone, ip string) bool {
+	now := time.Now()
+	var throttles []models.LoginThrottle
+	if err := h.DB.Where("((kind = ? AND key = ?) OR (kind = ? AND key = ?)) AND blocked_until > ?",
+		models.ThrottleAccount, phone, models.ThrottleIP, ip, now).Find(&throttles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return true
+	}
+	if len(throttles) == 0 {
+		return false
+	}
+
+	var until time.Time
+	for _, t := range throttles {
+		if t.BlockedUntil.After(until) {
+			until = t.BlockedUntil
+		}
+	}
+	retryAfter := int(math.Ceil(until.Sub(now).Seconds()))
+	c.Header("Retry-After", fmt.Sprint(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":      fmt.Sprintf("Too many failed login attempts, try again in %d seconds", retryAfter),
+		"retryAfter": retryAfter,
+	})
+	return true
+}
+
+// recordLoginFailure counts a failed login of phone from ip, blocking further
+// logins of either as the policy says.
+func (h *AuthHandler) recordLoginFailure(phone, ip string) error {
+	return h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := h.countLoginFailure(tx, models.ThrottleAccount, phone, h.Cfg.Login.MaxFailures); err != nil {
+			return err
+		}
+		return h.countLoginFailure(tx, models.ThrottleIP, ip, h.Cfg.Login.MaxFailuresPerIP)
+	})
+}
+
+// countLoginFailure counts a failed login in the throttle of kind and key,
+// starting over when the last failure is older than the lockout.
+func (h *AuthHandler) countLoginFailure(tx *gorm.DB, kind models.LoginThrottleKind, key string, maxFailures int) error {
+	now := time.Now()
+	throttle := models.LoginThrottle{Kind: kind, Key: key, Failures: 1, LastFailureAt: now}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "kind"}, {Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"failures":        gorm.Expr("CASE WHEN last_failure_at > ? THEN failures + 1 ELSE 1 END", now.Add(-h.Cfg.Login.Lockout)),
+			"last_failure_at": now,
+		}),
+	}).Create(&throttle).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("kind = ? AND key = ?", kind, key).First(&throttle).Error; err != nil {
+		return err
+	}
+	wait := h.Cfg.Login.Wait(throttle.Failures, maxFailures)
+	if wait == 0 {
+		return nil
+	}
+	return tx.Model(&throttle).Update("blocked_until", now.Add(wait)).Error
+}
+
+// clearLoginFailures forgets the failed logins of phone. Those from its IP
+// addresses are kept, so logging in to one account doesn't reset guessing
+// others.
+func (h *AuthHandler) clearLoginFailures(phone string) error {
+	return h.DB.Model(&models.LoginThrottle{}).
+		Where("kind = ? AND key = ?", models.ThrottleAccount, phone).
+		Updates(map[string]any{"failures": 0, "blocked_until": time.Time{}}).Error
+}
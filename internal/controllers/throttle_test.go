@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestLoginThrottle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	h := NewAuthHandler(db, &config.Config{
+		JWTSecret:       "secret",
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+		Login:           config.LoginPolicy{FreeAttempts: 2, Backoff: time.Minute, MaxFailures: 4, MaxFailuresPerIP: 10, Lockout: time.Hour},
+	}, nil)
+
+	hash, _ := utils.HashPassword("secret1")
+	user := models.User{Phone: "+989120000001", PasswordHash: hash, Role: models.Student}
+	admin := models.User{Phone: "+989120000002", Role: models.Admin}
+	db.Create(&user)
+	db.Create(&admin)
+
+	login := func(ip, password string, want int) *httptest.ResponseRecorder {
+		t.Helper()
+		r := gin.New()
+		r.POST("/login", h.Login)
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"phone": "+989120000001", "password": "`+password+`"}`))
+		req.RemoteAddr = ip + ":1234"
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != want {
+			t.Fatalf("login from %s with %q: got status %d, want %d: %s", ip, password, rr.Code, want, rr.Body)
+		}
+		return rr
+	}
+	retryAfter := func(rr *httptest.ResponseRecorder) time.Duration {
+		t.Helper()
+		seconds, err := strconv.Atoi(rr.Header().Get("Retry-After"))
+		if err != nil {
+			t.Fatalf("got Retry-After %q: %v", rr.Header().Get("Retry-After"), err)
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	waitOut := func() {
+		db.Model(&models.LoginThrottle{}).Where("1 = 1").Update("blocked_until", time.Now().Add(-time.Second))
+	}
+
+	// Two free failures, then a minute's wait even for the right password
+	login("192.0.2.1", "wrong", http.StatusUnauthorized)
+	login("192.0.2.1", "wrong", http.StatusUnauthorized)
+	login("192.0.2.1", "wrong", http.StatusUnauthorized)
+	if got := retryAfter(login("192.0.2.1", "secret1", http.StatusTooManyRequests)); got != time.Minute {
+		t.Errorf("got Retry-After %v, want 1m", got)
+	}
+
+	// The account is locked for an hour after the fourth failure, from any address
+	waitOut()
+	login("192.0.2.1", "wrong", http.StatusUnauthorized)
+	if got := retryAfter(login("198.51.100.1", "secret1", http.StatusTooManyRequests)); got != time.Hour {
+		t.Errorf("got Retry-After %v, want 1h", got)
+	}
+
+	// Unlocking lifts the account lockout but not the wait of the address
+	r := gin.New()
+	r.Use(withUser(admin.ID, models.Admin))
+	r.POST("/users/:id/unlock", h.UnlockUser)
+	for id, want := range map[uuid.UUID]int{uuid.New(): http.StatusNotFound, user.ID: http.StatusNoContent} {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/users/%s/unlock", id), nil))
+		if rr.Code != want {
+			t.Fatalf("unlock %s: got status %d, want %d", id, rr.Code, want)
+		}
+	}
+	login("192.0.2.1", "secret1", http.StatusTooManyRequests)
+	login("198.51.100.1", "secret1", http.StatusOK)
+
+	// Logging in starts the account's count over
+	login("198.51.100.1", "wrong", http.StatusUnauthorized)
+	login("198.51.100.1", "wrong", http.StatusUnauthorized)
+	login("198.51.100.1", "secret1", http.StatusOK)
+}
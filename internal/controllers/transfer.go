@@ -0,0 +1,375 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/money"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// errEnrollmentNotTransferable is returned when the enrollment stopped being
+// active while it was transferred.
+var errEnrollmentNotTransferable = errors.New("enrollment cannot be transferred")
+
+// TransferEnrollmentRequest defines the request body for transferring an
+// enrollment. Exactly one of CourseID and Phone is set.
+type TransferEnrollmentRequest struct {
+	// CourseID moves the enrollment to another course, bought with PlanID
+	// or else the course's plan of the same enrollment type.
+	CourseID *uint `json:"courseId"`
+	PlanID   uint  `json:"planId"`
+	// Phone moves the enrollment to the student with this phone number.
+	Phone  string `json:"phone"`
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// TransferResponse is the transfer made and the new enrollment.
+type TransferResponse struct {
+	Transfer   models.EnrollmentTransfer `json:"transfer"`
+	Enrollment models.Enrollment         `json:"enrollment"`
+}
+
+// TransferEnrollment godoc
+// @Summary Transfer an enrollment to another course or student (Student/Admin only)
+// @Description Move the remaining value of a paid up, active enrollment to a new enrollment, valid until the same expiration date. The remaining value is pro-rated by unused sessions or remaining time like a cancellation refund. Moving to another course prices the rest of the term with the new course's plan: the difference is owed on the new enrollment, or credited to the student's wallet when the new course costs less. Moving to another student hands over the remaining sessions and term as is. Upcoming bookings, pending freezes and freezes yet to start of the original enrollment are cancelled and it is kept as transferred.
+// @Tags Enrollments
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Enrollment ID"
+// @Param transfer body TransferEnrollmentRequest true "Transfer target"
+// @Success 201 {object} TransferResponse
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Enrollment, course or student not found"
+// @Failure 409 {object} map[string]string "error: Enrollment not transferable, used up or already enrolled"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /enrollments/{id}/transfer [post]
+func (h *EnrollmentHandler) TransferEnrollment(c *gin.Context) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	currentUserID := uuid.MustParse(userIDAny.(string))
+	currentUserRole := c.MustGet("userRole").(models.UserRole)
+
+	enrollmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid enrollment ID"})
+		return
+	}
+
+	var req TransferEnrollmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if (req.CourseID == nil) == (req.Phone == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Transfer to either another course or another student"})
+		return
+	}
+
+	var original models.Enrollment
+	if err := h.DB.First(&original, uint(enrollmentID)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Enrollment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch enrollment"})
+		return
+	}
+
+	// Only admin or the enrolled student can transfer this enrollment
+	if currentUserRole != models.Admin && original.UserID != currentUserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to transfer this enrollment"})
+		return
+	}
+
+	if original.Status != models.EnrollmentActive {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only active enrollments can be transferred"})
+		return
+	}
+	if err := loadBalances(h.DB, &original); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute enrollment balance"})
+		return
+	}
+	if original.Balance > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Pay the balance of the enrollment before transferring it"})
+		return
+	}
+	if original.TotalSessions > 0 && original.SessionsUsed >= original.TotalSessions {
+		c.JSON(http.StatusConflict, gin.H{"error": "No sessions are left on the enrollment to transfer"})
+		return
+	}
+
+	now := time.Now()
+	remaining := 1 - usedFraction(h.Cfg.Cancellation, &original, now)
+	value := original.PricePaid.Mul(remaining)
+
+	enrollment := models.Enrollment{
+		UserID:          original.UserID,
+		CourseID:        original.CourseID,
+		EnrollmentType:  original.EnrollmentType,
+		PricePlanID:     original.PricePlanID,
+		Status:          models.EnrollmentActive,
+		Currency:        original.Currency,
+		StartDate:       now,
+		ExpirationDate:  original.ExpirationDate,
+		PricePaid:       value,
+		DiscountApplied: original.DiscountApplied,
+		PackFilter:      original.PackFilter,
+	}
+	enrollment.NetPrice, enrollment.Tax = models.SplitTax(value, original.TaxRate)
+	enrollment.TaxRate = original.TaxRate
+	if original.TotalSessions > 0 {
+		enrollment.TotalSessions = original.TotalSessions - original.SessionsUsed
+	}
+
+	var recipient *models.User
+	if req.CourseID != nil {
+		if !h.transferToCourse(c, &original, &enrollment, &req, remaining) {
+			return
+		}
+	} else {
+		recipient = &models.User{}
+		if err := h.DB.Where("phone = ?", req.Phone).First(recipient).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "No student with this phone number"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch student"})
+			return
+		}
+		if recipient.ID == original.UserID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "The enrollment already belongs to this student"})
+			return
+		}
+		enrollment.UserID = recipient.ID
+	}
+
+	if enrollment.EnrollmentType != models.Pack &&
+		h.DB.Where("user_id = ? AND course_id = ? AND enrollment_type <> ? AND status NOT IN ? AND id <> ?",
+			enrollment.UserID, enrollment.CourseID, models.Pack,
+			[]models.EnrollmentStatus{models.EnrollmentCancelled, models.EnrollmentExpired, models.EnrollmentTransferred}, original.ID).
+			First(&models.Enrollment{}).Error == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "The student is already enrolled in this course"})
+		return
+	}
+
+	transfer := models.EnrollmentTransfer{
+		FromEnrollmentID: original.ID,
+		FromUserID:       original.UserID,
+		ToUserID:         enrollment.UserID,
+		FromCourseID:     original.CourseID,
+		ToCourseID:       enrollment.CourseID,
+		Value:            value,
+		PriceDifference:  enrollment.PricePaid - value,
+		TransferredByID:  currentUserID,
+		Reason:           req.Reason,
+	}
+
+	var promoted []*models.Booking
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		// Guard against the enrollment being transferred or cancelled concurrently
+		update := tx.Model(&original).Where("status = ?", models.EnrollmentActive).
+			Update("status", models.EnrollmentTransferred)
+		if update.Error != nil {
+			return update.Error
+		}
+		if update.RowsAffected == 0 {
+			return errEnrollmentNotTransferable
+		}
+
+		var err error
+		promoted, err = cancelUpcomingBookings(tx, original.ID)
+		if err != nil {
+			return err
+		}
+		// Freezes yet to start stay behind, and so do the days they added
+		if err := cancelFreezes(tx, &original); err != nil {
+			return err
+		}
+		enrollment.ExpirationDate = original.ExpirationDate
+
+		if err := tx.Create(&enrollment).Error; err != nil {
+			return err
+		}
+		transfer.ToEnrollmentID = enrollment.ID
+		if err := tx.Create(&transfer).Error; err != nil {
+			return err
+		}
+		return moveValue(tx, &original, &enrollment, value, now)
+	})
+	if err != nil {
+		if err == errEnrollmentNotTransferable {
+			c.JSON(http.StatusConflict, gin.H{"error": "Only active enrollments can be transferred"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer enrollment"})
+		return
+	}
+	for _, booking := range promoted {
+		notifyPromoted(h.Notifier, booking)
+	}
+	if recipient != nil {
+		var course models.Course
+		h.DB.First(&course, enrollment.CourseID)
+		message := fmt.Sprintf("An enrollment in %s valid until %s was transferred to you.",
+			course.Title, enrollment.ExpirationDate.Format("Monday, January 2"))
+		if err := h.Notifier.Notify(*recipient, message); err != nil {
+			log.Printf("failed to notify user %s of transfer %d: %v", recipient.ID, transfer.ID, err)
+		}
+	}
+
+	if err := loadBalances(h.DB, &enrollment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute enrollment balance"})
+		return
+	}
+	c.JSON(http.StatusCreated, TransferResponse{Transfer: transfer, Enrollment: enrollment})
+}
+
+// transferToCourse prices the remaining fraction of the term in the
+// requested course into enrollment, writing an error response and returning
+// false when the course or its plan cannot be found.
+func (h *EnrollmentHandler) transferToCourse(c *gin.Context, original, enrollment *models.Enrollment, req *TransferEnrollmentRequest, remaining float64) bool {
+	if *req.CourseID == original.CourseID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The enrollment is already in this course"})
+		return false
+	}
+
+	var course models.Course
+	if err := h.DB.First(&course, *req.CourseID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch course details"})
+		return false
+	}
+
+	plan, err := h.enrollmentPlan(&course, &EnrollRequest{CourseID: course.ID, PlanID: req.PlanID, EnrollmentType: original.EnrollmentType})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No such price plan for this course"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch price plan"})
+		return false
+	}
+
+	price, discount := plan.Quote(&course)
+	enrollment.CourseID = course.ID
+	enrollment.EnrollmentType = plan.EnrollmentType
+	enrollment.PricePlanID = &plan.ID
+	enrollment.NetPrice, enrollment.Tax, enrollment.PricePaid = h.Cfg.Tax.Apply(price.Mul(remaining))
+	enrollment.TaxRate = h.Cfg.Tax.Rate
+	enrollment.DiscountApplied = discount
+	enrollment.PackFilter = plan.PackFilter
+	enrollment.TotalSessions = 0
+	if plan.SessionLimit > 0 {
+		enrollment.TotalSessions = max(int(math.Round(float64(plan.SessionLimit)*remaining)), 1)
+	}
+	return true
+}
+
+// moveValue records value as refunded out of the payments of the original
+// enrollment by transfer and paid into the new one. What the new enrollment
+// does not need is credited to the wallet of the original enrollment's
+// student.
+func moveValue(tx *gorm.DB, original, enrollment *models.Enrollment, value money.Amount, now time.Time) error {
+	if value <= 0 {
+		return nil
+	}
+	if _, err := refundEnrollment(tx, original, value, models.Transfer); err != nil {
+		return err
+	}
+
+	moved := min(value, enrollment.PricePaid)
+	if moved > 0 {
+		if err := tx.Create(&models.Payment{
+			EnrollmentID:  enrollment.ID,
+			Amount:        moved,
+			Status:        models.PaymentSucceeded,
+			Method:        models.Transfer,
+			TransactionID: uuid.NewString(),
+			PaymentDate:   now,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	if excess := value - moved; excess > 0 {
+		return addCredit(tx, &models.CreditTransaction{
+			UserID:      original.UserID,
+			Kind:        models.CreditRefund,
+			Amount:      excess,
+			Description: fmt.Sprintf("Transfer of enrollment %d", original.ID),
+		})
+	}
+	return nil
+}
+
+// GetEnrollmentTransfers godoc
+// @Summary Get the transfers of an enrollment (Student/Admin only)
+// @Description Retrieve the transfers the enrollment was created by or transferred with, oldest first.
+// @Tags Enrollments
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Enrollment ID"
+// @Success 200 {array} models.EnrollmentTransfer
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: Enrollment not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /enrollments/{id}/transfers [get]
+func (h *EnrollmentHandler) GetEnrollmentTransfers(c *gin.Context) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	currentUserID := uuid.MustParse(userIDAny.(string))
+	currentUserRole := c.MustGet("userRole").(models.UserRole)
+
+	enrollmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid enrollment ID"})
+		return
+	}
+
+	var enrollment models.Enrollment
+	if err := h.DB.First(&enrollment, uint(enrollmentID)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Enrollment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch enrollment"})
+		return
+	}
+
+	if currentUserRole != models.Admin && enrollment.UserID != currentUserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to view this enrollment"})
+		return
+	}
+
+	var transfers []models.EnrollmentTransfer
+	if err := h.DB.Where("from_enrollment_id = ? OR to_enrollment_id = ?", enrollment.ID, enrollment.ID).
+		Order("id").Find(&transfers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transfers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
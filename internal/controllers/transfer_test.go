@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/notify"
+	"yoga-guru/internal/payment"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestTransferEnrollment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	cfg := &config.Config{Cancellation: config.CancellationPolicy{ProRateBy: config.ProRateBySessions}}
+	h := NewEnrollmentHandler(db, cfg, notify.LogNotifier{}, payment.NewFakeGateway())
+
+	hatha := models.Course{Title: "Hatha", Price: 10}
+	vinyasa := models.Course{Title: "Vinyasa", Price: 20}
+	db.Create(&hatha)
+	db.Create(&vinyasa)
+	plan := models.PricePlan{Name: "Monthly", EnrollmentType: models.Monthly, Sessions: 4, DurationMonths: 1, Active: true}
+	db.Create(&plan)
+	student := models.User{Phone: "+989120000001", Role: models.Student}
+	friend := models.User{Phone: "+989120000002", Role: models.Student}
+	db.Create(&student)
+	db.Create(&friend)
+
+	enroll := func(enrollment models.Enrollment) models.Enrollment {
+		enrollment.UserID = student.ID
+		enrollment.CourseID = hatha.ID
+		enrollment.Status = models.EnrollmentActive
+		db.Create(&enrollment)
+		db.Create(&models.Payment{EnrollmentID: enrollment.ID, Amount: enrollment.PricePaid, Status: models.PaymentSucceeded,
+			Method: models.Cash, TransactionID: uuid.NewString(), PaymentDate: enrollment.StartDate})
+		return enrollment
+	}
+	r := gin.New()
+	r.Use(withUser(student.ID, models.Student))
+	r.POST("/enrollments/:id/transfer", h.TransferEnrollment)
+	transfer := func(enrollment models.Enrollment, body string) TransferResponse {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/enrollments/%d/transfer", enrollment.ID), strings.NewReader(body)))
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("transferring enrollment %d: got status %d: %s", enrollment.ID, rr.Code, rr.Body)
+		}
+		var response TransferResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		db.First(&enrollment, enrollment.ID)
+		if enrollment.Status != models.EnrollmentTransferred {
+			t.Errorf("got original status %q, want %q", enrollment.Status, models.EnrollmentTransferred)
+		}
+		return response
+	}
+
+	// Halfway through a month of Hatha, move to the pricier Vinyasa course
+	now := time.Now()
+	monthly := enroll(models.Enrollment{EnrollmentType: models.Monthly, PricePlanID: &plan.ID,
+		StartDate: now.Add(-15 * 24 * time.Hour), ExpirationDate: now.Add(15 * 24 * time.Hour), PricePaid: 40})
+	moved := transfer(monthly, fmt.Sprintf(`{"courseId": %d}`, vinyasa.ID))
+	if moved.Transfer.Value != 20 || moved.Enrollment.PricePaid != 40 ||
+		moved.Enrollment.Balance != 20 || moved.Enrollment.CourseID != vinyasa.ID {
+		t.Errorf("unexpected course transfer %+v", moved)
+	}
+
+	// Sell the rest of a session pack to a friend
+	pack := enroll(models.Enrollment{EnrollmentType: models.Pack, StartDate: now, ExpirationDate: now.AddDate(0, 3, 0),
+		PricePaid: 100, TotalSessions: 10, SessionsUsed: 4})
+	sold := transfer(pack, fmt.Sprintf(`{"phone": %q}`, friend.Phone))
+	if sold.Enrollment.UserID != friend.ID || sold.Enrollment.TotalSessions != 6 ||
+		sold.Enrollment.PricePaid != 60 || sold.Enrollment.Balance != 0 || sold.Transfer.FromEnrollmentID != pack.ID {
+		t.Errorf("unexpected student transfer %+v", sold)
+	}
+
+	// The value is refunded out of the pack's payment by transfer
+	var paid models.Payment
+	db.Where("enrollment_id = ? AND refund_of_id IS NULL", pack.ID).First(&paid)
+	var refunds []models.Payment
+	db.Where("refund_of_id = ?", paid.ID).Find(&refunds)
+	if paid.Refunded != 60 || len(refunds) != 1 || refunds[0].Amount != 60 || refunds[0].Method != models.Transfer {
+		t.Errorf("got payment %+v and refunds %+v, want 60 refunded by transfer", paid, refunds)
+	}
+
+	// A used up pack has nothing left to transfer
+	usedUp := enroll(models.Enrollment{EnrollmentType: models.Pack, StartDate: now, ExpirationDate: now.AddDate(0, 3, 0),
+		PricePaid: 100, TotalSessions: 10, SessionsUsed: 10})
+	rr := httptest.NewRecorder()
+	body := fmt.Sprintf(`{"phone": %q}`, friend.Phone)
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/enrollments/%d/transfer", usedUp.ID), strings.NewReader(body)))
+	if rr.Code != http.StatusConflict {
+		t.Errorf("transferring a used up pack: got status %d, want %d", rr.Code, http.StatusConflict)
+	}
+}
@@ -2,7 +2,6 @@ package controllers
 
 import (
 	"net/http"
-	"strconv"
 	"yoga-guru/internal/models"
 
 	"github.com/gin-gonic/gin"
@@ -76,7 +75,7 @@ type UpdateUserRoleRequest struct {
 // @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Param id path int true "User ID"
+// @Param id path string true "User ID"
 // @Param role body UpdateUserRoleRequest true "New role for the user"
 // @Success 200 {object} models.User
 // @Failure 400 {object} map[string]string "error: Bad request"
@@ -86,12 +85,11 @@ type UpdateUserRoleRequest struct {
 // @Failure 500 {object} map[string]string "error: Internal server error"
 // @Router /users/{id}/role [put]
 func (h *UserHandler) UpdateUserRole(c *gin.Context) {
-	userIDParam, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	targetUserID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
 		return
 	}
-	targetUserID := uint(userIDParam)
 
 	var req UpdateUserRoleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -100,7 +98,7 @@ func (h *UserHandler) UpdateUserRole(c *gin.Context) {
 	}
 
 	var user models.User
-	if err := h.DB.First(&user, targetUserID).Error; err != nil {
+	if err := h.DB.First(&user, "id = ?", targetUserID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 			return
@@ -111,7 +109,7 @@ func (h *UserHandler) UpdateUserRole(c *gin.Context) {
 
 	newRole := models.UserRole(req.Role)
 	switch newRole {
-	case models.Admin, models.Instructor, models.Student:
+	case models.Admin, models.Instructor, models.Student, models.Staff:
 		// Valid role
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role specified"})
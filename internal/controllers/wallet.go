@@ -0,0 +1,369 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/money"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errInsufficientCredit = errors.New("insufficient credit")
+	errGiftCardInvalid    = errors.New("gift card is not valid")
+)
+
+// giftCardAlphabet leaves out characters that are easily confused when a
+// code is typed in.
+const giftCardAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// WalletHandler provides methods for store credit and gift cards.
+type WalletHandler struct {
+	DB *gorm.DB
+}
+
+// NewWalletHandler creates a new WalletHandler instance.
+func NewWalletHandler(db *gorm.DB) *WalletHandler {
+	return &WalletHandler{DB: db}
+}
+
+// WalletResponse is the credit of a user with its history, newest first.
+type WalletResponse struct {
+	UserID       uuid.UUID                  `json:"userId"`
+	Balance      money.Amount               `json:"balance"`
+	Transactions []models.CreditTransaction `json:"transactions"`
+}
+
+// GetMyWallet godoc
+// @Summary Get the current user's wallet
+// @Description Retrieve the store credit of the authenticated user and its transaction history.
+// @Tags Wallet
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} WalletResponse
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /wallet/me [get]
+func (h *WalletHandler) GetMyWallet(c *gin.Context) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	h.respondWallet(c, uuid.MustParse(userIDAny.(string)))
+}
+
+// GetUserWallet godoc
+// @Summary Get a user's wallet (Staff/Admin only)
+// @Description Retrieve the store credit of a user and its transaction history.
+// @Tags Wallet
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} WalletResponse
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /users/{id}/wallet [get]
+func (h *WalletHandler) GetUserWallet(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	h.respondWallet(c, userID)
+}
+
+// RedeemGiftCardRequest defines the request body for redeeming a gift card.
+type RedeemGiftCardRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// RedeemGiftCard godoc
+// @Summary Redeem a gift card
+// @Description Load the credit of a gift card into the authenticated user's wallet. A gift card can be redeemed once.
+// @Tags Wallet
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param giftCard body RedeemGiftCardRequest true "Gift card code"
+// @Success 200 {object} WalletResponse
+// @Failure 400 {object} map[string]string "error: Invalid gift card"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /wallet/redeem [post]
+func (h *WalletHandler) RedeemGiftCard(c *gin.Context) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	userID := uuid.MustParse(userIDAny.(string))
+
+	var req RedeemGiftCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var card models.GiftCard
+		if err := tx.Where("code = ?", normalizeGiftCardCode(req.Code)).First(&card).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errGiftCardInvalid
+			}
+			return err
+		}
+		if card.ExpiresAt != nil && !now.Before(*card.ExpiresAt) {
+			return errGiftCardInvalid
+		}
+
+		// Guard against the card being redeemed twice concurrently
+		update := tx.Model(&card).Where("redeemed_at IS NULL").
+			Updates(models.GiftCard{RedeemedByID: &userID, RedeemedAt: &now})
+		if update.Error != nil {
+			return update.Error
+		}
+		if update.RowsAffected == 0 {
+			return errGiftCardInvalid
+		}
+
+		return addCredit(tx, &models.CreditTransaction{
+			UserID:      userID,
+			Kind:        models.CreditGiftCard,
+			Amount:      card.Amount,
+			Description: "Gift card",
+			GiftCardID:  &card.ID,
+		})
+	})
+	if err != nil {
+		if err == errGiftCardInvalid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "This gift card is not valid or was already redeemed"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to redeem gift card"})
+		return
+	}
+
+	h.respondWallet(c, userID)
+}
+
+// CreateGiftCardRequest defines the request body for issuing a gift card.
+type CreateGiftCardRequest struct {
+	Amount    money.Amount `json:"amount" binding:"required,gt=0"`
+	Code      string       `json:"code" binding:"max=64"` // Generated when empty
+	ExpiresAt *time.Time   `json:"expiresAt"`
+}
+
+// CreateGiftCard godoc
+// @Summary Issue a gift card (Staff/Admin only)
+// @Description Issue a gift card sold at the front desk. Whoever redeems its code gets its amount as credit.
+// @Tags Wallet
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param giftCard body CreateGiftCardRequest true "Gift card details"
+// @Success 201 {object} models.GiftCard
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 409 {object} map[string]string "error: Code already in use"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /gift-cards [post]
+func (h *WalletHandler) CreateGiftCard(c *gin.Context) {
+	userIDAny, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	var req CreateGiftCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	code := normalizeGiftCardCode(req.Code)
+	if code == "" {
+		code = newGiftCardCode()
+	} else if h.DB.Unscoped().Where("code = ?", code).First(&models.GiftCard{}).Error == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "This gift card code is already in use"})
+		return
+	}
+
+	card := models.GiftCard{
+		Code:       code,
+		Amount:     req.Amount,
+		IssuedByID: uuid.MustParse(userIDAny.(string)),
+		ExpiresAt:  req.ExpiresAt,
+	}
+	if err := h.DB.Create(&card).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue gift card"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, card)
+}
+
+// GetGiftCards godoc
+// @Summary Get all gift cards (Staff/Admin only)
+// @Description Retrieve the issued gift cards, newest first, and who redeemed them.
+// @Tags Wallet
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.GiftCard
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /gift-cards [get]
+func (h *WalletHandler) GetGiftCards(c *gin.Context) {
+	var cards []models.GiftCard
+	if err := h.DB.Order("id DESC").Find(&cards).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch gift cards"})
+		return
+	}
+	c.JSON(http.StatusOK, cards)
+}
+
+// AdjustCreditRequest defines the request body for adjusting the credit of
+// a wallet. Negative amounts take credit off.
+type AdjustCreditRequest struct {
+	Amount      money.Amount `json:"amount" binding:"required"`
+	Description string       `json:"description" binding:"required,max=255"`
+}
+
+// AdjustCredit godoc
+// @Summary Adjust a user's credit (Admin only)
+// @Description Add credit to or take credit off a user's wallet, e.g. as a goodwill gesture or to correct a mistake. The reason is kept in the wallet history.
+// @Tags Wallet
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param adjustment body AdjustCreditRequest true "Amount and reason"
+// @Success 200 {object} WalletResponse
+// @Failure 400 {object} map[string]string "error: Bad request"
+// @Failure 401 {object} map[string]string "error: Unauthorized"
+// @Failure 403 {object} map[string]string "error: Forbidden"
+// @Failure 404 {object} map[string]string "error: User not found"
+// @Failure 409 {object} map[string]string "error: Not enough credit"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /users/{id}/wallet/adjustments [post]
+func (h *WalletHandler) AdjustCredit(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req AdjustCreditRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.DB.First(&models.User{}, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		return addCredit(tx, &models.CreditTransaction{
+			UserID:      userID,
+			Kind:        models.CreditAdjustment,
+			Amount:      req.Amount,
+			Description: req.Description,
+		})
+	})
+	if err != nil {
+		if err == errInsufficientCredit {
+			c.JSON(http.StatusConflict, gin.H{"error": "Not enough credit in the wallet"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to adjust credit"})
+		return
+	}
+
+	h.respondWallet(c, userID)
+}
+
+// respondWallet writes the wallet of the user with its history.
+func (h *WalletHandler) respondWallet(c *gin.Context, userID uuid.UUID) {
+	var wallet models.Wallet
+	if err := h.DB.Where("user_id = ?", userID).Limit(1).Find(&wallet).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch wallet"})
+		return
+	}
+
+	var transactions []models.CreditTransaction
+	if err := h.DB.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&transactions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch wallet transactions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, WalletResponse{UserID: userID, Balance: wallet.Balance, Transactions: transactions})
+}
+
+// addCredit changes the wallet of the entry's user by the entry's amount and
+// records the entry in its history. Spending more credit than the wallet
+// holds fails with errInsufficientCredit.
+func addCredit(tx *gorm.DB, entry *models.CreditTransaction) error {
+	if entry.Amount < 0 {
+		update := tx.Model(&models.Wallet{}).
+			Where("user_id = ? AND balance >= ?", entry.UserID, -entry.Amount).
+			Update("balance", gorm.Expr("balance + ?", entry.Amount))
+		if update.Error != nil {
+			return update.Error
+		}
+		if update.RowsAffected == 0 {
+			return errInsufficientCredit
+		}
+	} else {
+		wallet := models.Wallet{UserID: entry.UserID, Balance: entry.Amount}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"balance":    gorm.Expr("balance + ?", entry.Amount),
+				"updated_at": time.Now(),
+			}),
+		}).Create(&wallet).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Create(entry).Error
+}
+
+// normalizeGiftCardCode makes gift card codes case insensitive.
+func normalizeGiftCardCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// newGiftCardCode generates a random code like "ABCD-EFGH-JKLM".
+func newGiftCardCode() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		panic(err) // crypto/rand never fails on supported platforms
+	}
+
+	var code strings.Builder
+	for i, v := range b {
+		if i > 0 && i%4 == 0 {
+			code.WriteByte('-')
+		}
+		code.WriteByte(giftCardAlphabet[int(v)%len(giftCardAlphabet)])
+	}
+	return code.String()
+}
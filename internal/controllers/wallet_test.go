@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/money"
+	"yoga-guru/internal/notify"
+	"yoga-guru/internal/payment"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGiftCardCreditPaysAndRefundsEnrollment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	gateway := payment.NewFakeGateway()
+	cfg := &config.Config{Cancellation: config.CancellationPolicy{FullRefundDays: 7}}
+	wallets := NewWalletHandler(db)
+	payments := NewPaymentHandler(db, cfg, gateway)
+	enrollments := NewEnrollmentHandler(db, cfg, notify.LogNotifier{}, gateway)
+
+	student := models.User{Phone: "+989120000500", Role: models.Student}
+	db.Create(&student)
+	course := models.Course{Title: "Restorative"}
+	db.Create(&course)
+	enrollment := models.Enrollment{
+		UserID:         student.ID,
+		CourseID:       course.ID,
+		Status:         models.EnrollmentPending,
+		StartDate:      time.Now(),
+		ExpirationDate: time.Now().AddDate(0, 1, 0),
+		PricePaid:      80,
+	}
+	db.Create(&enrollment)
+	db.Create(&models.GiftCard{Code: "GIFT-0001", Amount: 100})
+
+	r := gin.New()
+	r.Use(withUser(student.ID, models.Student))
+	r.GET("/wallet/me", wallets.GetMyWallet)
+	r.POST("/wallet/redeem", wallets.RedeemGiftCard)
+	r.POST("/enrollments/:id/payments/credit", payments.PayWithCredit)
+	r.DELETE("/enrollments/:id", enrollments.CancelEnrollment)
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rr
+	}
+	balance := func() money.Amount {
+		var wallet WalletResponse
+		if err := json.Unmarshal(serve(http.MethodGet, "/wallet/me", "").Body.Bytes(), &wallet); err != nil {
+			t.Fatal(err)
+		}
+		return wallet.Balance
+	}
+
+	for _, want := range []int{http.StatusOK, http.StatusBadRequest} {
+		if rr := serve(http.MethodPost, "/wallet/redeem", `{"code":"gift-0001"}`); rr.Code != want {
+			t.Fatalf("redeeming: got status %d, want %d: %s", rr.Code, want, rr.Body)
+		}
+	}
+	if got := balance(); got != 100 {
+		t.Fatalf("got credit %v after redeeming, want 100", got)
+	}
+
+	if rr := serve(http.MethodPost, fmt.Sprintf("/enrollments/%d/payments/credit", enrollment.ID), ""); rr.Code != http.StatusCreated {
+		t.Fatalf("paying from credit: got status %d: %s", rr.Code, rr.Body)
+	}
+	if got := balance(); got != 20 {
+		t.Errorf("got credit %v after paying, want 20", got)
+	}
+	db.First(&enrollment, enrollment.ID)
+	if enrollment.Status != models.EnrollmentActive {
+		t.Errorf("got enrollment status %q, want %q", enrollment.Status, models.EnrollmentActive)
+	}
+
+	// Cancelling within the full refund period returns the credit
+	if rr := serve(http.MethodDelete, fmt.Sprintf("/enrollments/%d", enrollment.ID), ""); rr.Code != http.StatusOK {
+		t.Fatalf("cancelling: got status %d: %s", rr.Code, rr.Body)
+	}
+	if got := balance(); got != 100 {
+		t.Errorf("got credit %v after cancelling, want 100", got)
+	}
+}
+
+func TestAdjustCredit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	h := NewWalletHandler(db)
+
+	admin := models.User{Phone: "+989120000510", Role: models.Admin}
+	student := models.User{Phone: "+989120000511", Role: models.Student}
+	db.Create(&admin)
+	db.Create(&student)
+
+	r := gin.New()
+	r.Use(withUser(admin.ID, models.Admin))
+	r.POST("/users/:id/wallet/adjustments", h.AdjustCredit)
+	adjust := func(body string, want int) WalletResponse {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/users/%s/wallet/adjustments", student.ID), strings.NewReader(body)))
+		if rr.Code != want {
+			t.Fatalf("adjusting by %s: got status %d, want %d: %s", body, rr.Code, want, rr.Body)
+		}
+		var wallet WalletResponse
+		json.Unmarshal(rr.Body.Bytes(), &wallet)
+		return wallet
+	}
+
+	adjust(`{"amount": 50, "description": "Sorry for the cancelled class"}`, http.StatusOK)
+	adjust(`{"amount": -80, "description": "Correction"}`, http.StatusConflict)
+	adjust(`{"amount": 0, "description": "Nothing"}`, http.StatusBadRequest)
+	wallet := adjust(`{"amount": -20, "description": "Correction"}`, http.StatusOK)
+	if wallet.Balance != 30 || len(wallet.Transactions) != 2 || wallet.Transactions[0].Kind != models.CreditAdjustment {
+		t.Errorf("got wallet %+v, want 30 credit after two adjustments", wallet)
+	}
+}
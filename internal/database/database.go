@@ -5,10 +5,14 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"math"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 	"yoga-guru/internal/models"
+	"yoga-guru/internal/money"
 	"yoga-guru/internal/utils"
 
 	_ "github.com/joho/godotenv/autoload"
@@ -40,7 +44,21 @@ var (
 	dbInstance *service
 )
 
-func New() Service {
+// moneyColumns are the columns holding amounts of money, by model. They
+// were stored as floating point major units before amounts became whole
+// minor units.
+var moneyColumns = []struct {
+	model   any
+	columns []string
+}{
+	{&models.Course{}, []string{"price"}},
+	{&models.Enrollment{}, []string{"price_paid"}},
+	{&models.Payment{}, []string{"amount"}},
+}
+
+// New connects to the database and migrates it, converting amounts from
+// before currencies were tracked to the studio currency.
+func New(currency money.Currency) Service {
 	// Reuse Connection
 	if dbInstance != nil {
 		return dbInstance
@@ -61,7 +79,7 @@ func New() Service {
 		orm: gormdb,
 	}
 
-	migrate(gormdb)
+	migrate(gormdb, currency)
 	return dbInstance
 }
 
@@ -128,13 +146,66 @@ func (s *service) Getgorm() *gorm.DB {
 	return s.orm
 }
 
-func migrate(db *gorm.DB) *gorm.DB {
+func migrate(db *gorm.DB, currency money.Currency) *gorm.DB {
+	legacy := legacyMoneyColumns(db)
+
 	// Auto-migrate the models
-	err := db.AutoMigrate(&models.User{}, &models.Profile{}, &models.Course{}, &models.Enrollment{})
+	err := db.AutoMigrate(
+		&models.User{},
+		&models.Profile{},
+		&models.Course{},
+		&models.Schedule{},
+		&models.CourseSession{},
+		&models.Enrollment{},
+		&models.Booking{},
+		&models.Attendance{},
+		&models.Payment{},
+		&models.PricePlan{},
+		&models.Coupon{},
+		&models.CouponRedemption{},
+		&models.Wallet{},
+		&models.CreditTransaction{},
+		&models.GiftCard{},
+		&models.EnrollmentFreeze{},
+		&models.Household{},
+		&models.HouseholdMember{},
+		&models.EnrollmentTransfer{},
+		&models.Invoice{},
+		&models.InvoiceSequence{},
+		&models.PayoutRule{},
+		&models.PayoutStatement{},
+		&models.PayoutLine{},
+		&models.OneTimeCode{},
+		&models.DeviceSession{},
+		&models.RefreshToken{},
+		&models.LoginThrottle{},
+	)
 	if err != nil {
 		log.Fatalf("failed to migrate database: %v", err)
 	}
 
+	if err := convertMoney(db, currency, legacy); err != nil {
+		log.Fatalf("failed to convert amounts to minor units: %v", err)
+	}
+
+	// Enrollments and payments from before tax was tracked are untaxed
+	if err := db.Model(&models.Enrollment{}).Unscoped().Where("net_price = 0 AND tax = 0 AND price_paid <> 0").
+		Update("net_price", gorm.Expr("price_paid")).Error; err != nil {
+		log.Fatalf("failed to backfill enrollment net prices: %v", err)
+	}
+	if err := db.Model(&models.Payment{}).Unscoped().Where("net_amount = 0 AND tax = 0 AND amount <> 0").
+		Update("net_amount", gorm.Expr("amount")).Error; err != nil {
+		log.Fatalf("failed to backfill payment net amounts: %v", err)
+	}
+
+	// Amounts from before currencies were tracked are in the studio currency
+	for _, model := range []any{&models.Enrollment{}, &models.Payment{}} {
+		if err := db.Model(model).Unscoped().Where("currency = '' OR currency IS NULL").
+			Update("currency", currency).Error; err != nil {
+			log.Fatalf("failed to backfill currencies: %v", err)
+		}
+	}
+
 	// Hash the password
 	hashedPassword, err := utils.HashPassword("feri1367it")
 	if err != nil {
@@ -158,6 +229,59 @@ func migrate(db *gorm.DB) *gorm.DB {
 		}
 	}
 
+	// Seed the default price plans on a new database
+	var planCount int64
+	if err := db.Model(&models.PricePlan{}).Unscoped().Count(&planCount).Error; err != nil {
+		log.Fatalf("failed to count price plans: %v", err)
+	}
+	if planCount == 0 {
+		plans := models.DefaultPricePlans()
+		if err := db.Create(&plans).Error; err != nil {
+			log.Fatalf("failed to seed price plans: %v", err)
+		}
+	}
+
 	log.Println("Database connection established and models migrated successfully.")
 	return db
 }
+
+// legacyMoneyColumns returns the money columns, by table, still stored as
+// floating point numbers.
+func legacyMoneyColumns(db *gorm.DB) map[string][]string {
+	legacy := make(map[string][]string)
+	for _, m := range moneyColumns {
+		if !db.Migrator().HasTable(m.model) {
+			continue
+		}
+		types, err := db.Migrator().ColumnTypes(m.model)
+		if err != nil {
+			log.Fatalf("failed to inspect columns: %v", err)
+		}
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(m.model); err != nil {
+			log.Fatalf("failed to parse model: %v", err)
+		}
+		for _, t := range types {
+			if slices.Contains(m.columns, t.Name()) && strings.EqualFold(t.DatabaseTypeName(), "real") {
+				legacy[stmt.Schema.Table] = append(legacy[stmt.Schema.Table], t.Name())
+			}
+		}
+	}
+	return legacy
+}
+
+// convertMoney scales the legacy money columns from major to minor units of
+// the studio currency.
+func convertMoney(db *gorm.DB, currency money.Currency, legacy map[string][]string) error {
+	scale := math.Pow10(currency.Digits())
+	return db.Transaction(func(tx *gorm.DB) error {
+		for table, columns := range legacy {
+			for _, column := range columns {
+				if err := tx.Exec(fmt.Sprintf("UPDATE %q SET %q = CAST(ROUND(%[2]q * ?) AS INTEGER) WHERE %[2]q IS NOT NULL", table, column), scale).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,128 @@
+// Package invoice renders invoices and receipts as PDF documents.
+package invoice
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/money"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Line is a billed item of a document.
+type Line struct {
+	Description string
+	Quantity    int
+	UnitPrice   money.Amount
+	Amount      money.Amount
+}
+
+// Document is the content of an invoice or receipt.
+type Document struct {
+	Title    string // e.g., "Invoice" or "Receipt"
+	Number   string
+	IssuedAt time.Time
+	Studio   config.StudioDetails
+	BillTo   []string // Lines of the customer's name and contact details
+	Lines    []Line
+	Subtotal money.Amount
+	Discount money.Amount
+	Tax      money.Amount
+	TaxRate  float64 // Printed next to the tax, when set
+	Total    money.Amount
+	Notes    []string // Printed below the totals, e.g. how it was paid
+	// FormatAmount writes the amounts of the document, e.g. converted to
+	// the studio's display currency.
+	FormatAmount func(money.Amount) string
+}
+
+// Render writes the document as a single A4 page PDF. The core fonts used
+// only cover Western European characters.
+func Render(w io.Writer, doc *Document) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(doc.Title+" "+doc.Number, true)
+	pdf.SetCreator(doc.Studio.Name, true)
+	pdf.SetMargins(20, 20, 20)
+	pdf.AddPage()
+	tr := pdf.UnicodeTranslatorFromDescriptor("") // UTF-8 to cp1252
+
+	// Studio details on the left, document number on the right
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(100, 8, tr(doc.Studio.Name), "", 0, "L", false, 0, "")
+	pdf.CellFormat(70, 8, tr(doc.Title), "", 1, "R", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	header := []string{doc.Studio.Address, doc.Studio.Phone}
+	if doc.Studio.TaxID != "" {
+		header = append(header, "Tax ID: "+doc.Studio.TaxID)
+	}
+	right := []string{"No. " + doc.Number, "Date: " + doc.IssuedAt.Format("2006-01-02")}
+	for i := 0; i < max(len(header), len(right)); i++ {
+		pdf.CellFormat(100, 5, tr(at(header, i)), "", 0, "L", false, 0, "")
+		pdf.CellFormat(70, 5, tr(at(right, i)), "", 1, "R", false, 0, "")
+	}
+
+	pdf.Ln(8)
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(0, 5, "Bill to", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	for _, line := range doc.BillTo {
+		pdf.CellFormat(0, 5, tr(line), "", 1, "L", false, 0, "")
+	}
+
+	pdf.Ln(8)
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(235, 235, 235)
+	pdf.CellFormat(90, 7, "Description", "B", 0, "L", true, 0, "")
+	pdf.CellFormat(20, 7, "Qty", "B", 0, "R", true, 0, "")
+	pdf.CellFormat(30, 7, "Unit price", "B", 0, "R", true, 0, "")
+	pdf.CellFormat(30, 7, "Amount", "B", 1, "R", true, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	for _, line := range doc.Lines {
+		pdf.CellFormat(90, 7, tr(line.Description), "B", 0, "L", false, 0, "")
+		pdf.CellFormat(20, 7, fmt.Sprint(line.Quantity), "B", 0, "R", false, 0, "")
+		pdf.CellFormat(30, 7, tr(doc.FormatAmount(line.UnitPrice)), "B", 0, "R", false, 0, "")
+		pdf.CellFormat(30, 7, tr(doc.FormatAmount(line.Amount)), "B", 1, "R", false, 0, "")
+	}
+
+	pdf.Ln(2)
+	taxLabel := "Tax"
+	if doc.TaxRate > 0 {
+		taxLabel = fmt.Sprintf("Tax (%s%%)", strconv.FormatFloat(doc.TaxRate*100, 'f', -1, 64))
+	}
+	totals := []struct {
+		label string
+		value money.Amount
+	}{
+		{"Subtotal", doc.Subtotal},
+		{"Discount", -doc.Discount},
+		{taxLabel, doc.Tax},
+	}
+	for _, total := range totals {
+		pdf.CellFormat(140, 6, total.label, "", 0, "R", false, 0, "")
+		pdf.CellFormat(30, 6, tr(doc.FormatAmount(total.value)), "", 1, "R", false, 0, "")
+	}
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(140, 8, "Total", "T", 0, "R", false, 0, "")
+	pdf.CellFormat(30, 8, tr(doc.FormatAmount(doc.Total)), "T", 1, "R", false, 0, "")
+
+	if len(doc.Notes) > 0 {
+		pdf.Ln(8)
+		pdf.SetFont("Helvetica", "", 9)
+		for _, note := range doc.Notes {
+			pdf.MultiCell(0, 5, tr(note), "", "L", false)
+		}
+	}
+
+	return pdf.Output(w)
+}
+
+// at returns the i-th line, or an empty one past the end.
+func at(lines []string, i int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+	return ""
+}
@@ -16,44 +16,105 @@ import (
 
 // Claims defines the JWT claims structure
 type Claims struct {
-	Role models.UserRole
+	Role      models.UserRole
+	SessionID string `json:"sid,omitempty"` // Device session of access tokens
 	jwt.RegisteredClaims
 }
 
-// GenerateJWT generates a new JWT token for a user.
-func GenerateJWT(userID string, role models.UserRole, cfg *config.Config) (string, string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour) // Token valid for 24 hours
-	accessTokenClaims := &Claims{
-		Role: role,
+// Access tokens authenticate API requests, refresh tokens are only exchanged
+// for new tokens. Each carries its own audience so neither is accepted as the
+// other.
+const (
+	AccessAudience  = "access"
+	RefreshAudience = "refresh"
+)
+
+// CheckInAudience is the audience of session check-in tokens. Such tokens
+// only prove presence at the studio and are never accepted for authentication.
+const CheckInAudience = "checkin"
+
+// CheckInClaims defines the claims of a session check-in token.
+type CheckInClaims struct {
+	SessionID uint
+	jwt.RegisteredClaims
+}
+
+// GenerateCheckInToken generates a signed check-in token for a course session.
+func GenerateCheckInToken(sessionID uint, expiresAt time.Time, cfg *config.Config) (string, error) {
+	claims := &CheckInClaims{
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			Audience:  jwt.ClaimStrings{CheckInAudience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Subject:   userID,
 		},
 	}
 
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessTokenClaims)
-	accessTokenString, err := accessToken.SignedString([]byte(cfg.JWTSecret))
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(cfg.JWTSecret))
 	if err != nil {
-		return "", "", fmt.Errorf("failed to sign token: %w", err)
+		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
+	return tokenString, nil
+}
 
-	expirationTime = time.Now().Add(7 * 24 * time.Hour) // Token valid for 24 hours
-	refreshTokenClaims := &Claims{
+// ValidateCheckInToken validates a check-in token and returns its session ID.
+func ValidateCheckInToken(tokenString string, cfg *config.Config) (uint, error) {
+	claims := &CheckInClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(cfg.JWTSecret), nil
+	}, jwt.WithAudience(CheckInAudience))
+	if err != nil {
+		return 0, fmt.Errorf("token validation failed: %w", err)
+	}
+	return claims.SessionID, nil
+}
+
+// GenerateAccessToken generates a signed access token for a user's device
+// session.
+func GenerateAccessToken(userID string, role models.UserRole, sessionID string, cfg *config.Config) (string, error) {
+	claims := &Claims{
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			Audience:  jwt.ClaimStrings{AccessAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Subject:   userID,
 		},
 	}
 
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshTokenClaims)
-	refreshTokenString, err := refreshToken.SignedString([]byte(cfg.JWTSecret))
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(cfg.JWTSecret))
 	if err != nil {
-		return "", "", fmt.Errorf("failed to sign token: %w", err)
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return tokenString, nil
+}
+
+// GenerateRefreshToken generates a signed refresh token for a user. Its ID
+// identifies the token stored server side, which decides whether it can
+// still be used.
+func GenerateRefreshToken(userID, tokenID string, expiresAt time.Time, cfg *config.Config) (string, error) {
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{RefreshAudience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Subject:   userID,
+			ID:        tokenID,
+		},
 	}
 
-	return accessTokenString, refreshTokenString, nil
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(cfg.JWTSecret))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return tokenString, nil
 }
 
 // AuthMiddleware validates the JWT token from the request header.
@@ -81,7 +142,7 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 			}
 			return []byte(cfg.JWTSecret), nil
-		})
+		}, jwt.WithAudience(AccessAudience))
 		if err != nil {
 			if err == jwt.ErrSignatureInvalid {
 				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token signature"})
@@ -101,6 +162,7 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 
 		c.Set("userID", claims.Subject)
 		c.Set("userRole", claims.Role)
+		c.Set("sessionID", claims.SessionID)
 		c.Next()
 	}
 }
@@ -134,19 +196,20 @@ func AuthorizeRole(allowedRoles ...models.UserRole) gin.HandlerFunc {
 	}
 }
 
-// ValidateToken validates a JWT string and returns the claims.
-func ValidateToken(tokenString string, cfg *config.Config) (*Claims, error) {
+// ValidateRefreshToken validates a refresh token and returns its claims.
+// Access and check-in tokens are rejected.
+func ValidateRefreshToken(tokenString string, cfg *config.Config) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return []byte(cfg.JWTSecret), nil
-	})
+	}, jwt.WithAudience(RefreshAudience))
 	if err != nil {
 		return nil, fmt.Errorf("token validation failed: %w", err)
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid && claims.ID != "" {
 		return claims, nil
 	}
 	return nil, fmt.Errorf("invalid token")
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BookingStatus defines the status of a session booking.
+type BookingStatus string
+
+const (
+	BookingBooked     BookingStatus = "booked"
+	BookingWaitlisted BookingStatus = "waitlisted"
+	BookingCancelled  BookingStatus = "cancelled"
+	BookingAttended   BookingStatus = "attended"
+	BookingNoShow     BookingStatus = "no_show"
+)
+
+// Booking reserves a place for a student in a specific course session.
+// Active bookings hold a session of the enrollment they were made with until
+// attendance is recorded, while waitlisted bookings wait in Position order
+// for a place to free up.
+type Booking struct {
+	gorm.Model
+	CourseSessionID uint `gorm:"index"`
+	CourseSession   CourseSession
+	EnrollmentID    uint `gorm:"index"`
+	Enrollment      Enrollment
+	UserID          uuid.UUID `gorm:"index"`
+	User            User
+	Status          BookingStatus
+	Position        int // Place in the waitlist, zero once booked
+	CancelledAt     *time.Time
+}
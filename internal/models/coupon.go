@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+	"yoga-guru/internal/money"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CouponKind defines how a coupon discounts a price.
+type CouponKind string
+
+const (
+	CouponPercentage CouponKind = "percentage" // Value is a fraction of the price (e.g., 0.20 for 20%)
+	CouponFixed      CouponKind = "fixed"      // Amount is taken off the price
+)
+
+// Coupon is a promotional code students can redeem when enrolling.
+type Coupon struct {
+	gorm.Model
+	Code           string `gorm:"uniqueIndex"` // Stored upper case
+	Description    string
+	Kind           CouponKind
+	Value          float64      // Only for percentage coupons
+	Amount         money.Amount // Only for fixed coupons
+	ValidFrom      *time.Time   // Open ended when not set
+	ValidUntil     *time.Time   // Open ended when not set
+	MaxRedemptions int          // Zero means unlimited
+	PerUserLimit   int          // Zero means unlimited
+	Redemptions    int          // Counter of redemptions so far
+	CourseID       *uint        // Restricts the coupon to a course when set
+	PricePlanID    *uint        // Restricts the coupon to a price plan when set
+	Active         bool
+}
+
+// Discount returns the amount the coupon takes off price.
+func (c *Coupon) Discount(price money.Amount) money.Amount {
+	switch c.Kind {
+	case CouponPercentage:
+		return price.Mul(c.Value)
+	case CouponFixed:
+		return min(c.Amount, price)
+	}
+	return 0
+}
+
+// CouponRedemption records a coupon redeemed for an enrollment.
+type CouponRedemption struct {
+	gorm.Model
+	CouponID     uint `gorm:"index"`
+	Coupon       Coupon
+	UserID       uuid.UUID `gorm:"index"`
+	EnrollmentID uint
+	Amount       money.Amount // The amount taken off the enrollment price
+}
@@ -2,7 +2,9 @@ package models
 
 import (
 	"time"
+	"yoga-guru/internal/money"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -20,6 +22,17 @@ const (
 	Friday                              // 64 (1000000)
 )
 
+// DayOfWeekMaskFor returns the mask bit for the given weekday.
+func DayOfWeekMaskFor(d time.Weekday) DayOfWeekMask {
+	// The mask starts the week on Saturday, time.Weekday starts it on Sunday.
+	return 1 << ((int(d) + 1) % 7)
+}
+
+// Has reports whether the mask includes the given weekday.
+func (m DayOfWeekMask) Has(d time.Weekday) bool {
+	return m&DayOfWeekMaskFor(d) != 0
+}
+
 // CourseLevel defines the difficulty levels for courses.
 type CourseLevel string
 
@@ -43,11 +56,26 @@ type Course struct {
 	Title        string
 	CourseType   string // e.g., Hatha, Vinyasa, Ashtanga
 	Level        CourseLevel
-	Price        float64    // Price per single session
-	Capacity     int        // Max number of students
-	InstructorID uint       // ID of the instructor creating the course
-	Instructor   User       // GORM association
-	Schedules    []Schedule `gorm:"foreignKey:CourseID"`
+	Price        money.Amount // Price per single session, in minor units of the studio currency
+	Capacity     int          // Max number of students
+	InstructorID uuid.UUID    // ID of the instructor creating the course
+	Instructor   User         // GORM association
+	Schedules    []Schedule   `gorm:"foreignKey:CourseID"`
+}
+
+// CourseFilter selects courses by type, level and instructor. Fields left
+// empty match any course.
+type CourseFilter struct {
+	CourseType   string
+	Level        CourseLevel
+	InstructorID *uuid.UUID
+}
+
+// Matches reports whether the course passes the filter.
+func (f CourseFilter) Matches(course *Course) bool {
+	return (f.CourseType == "" || f.CourseType == course.CourseType) &&
+		(f.Level == "" || f.Level == course.Level) &&
+		(f.InstructorID == nil || *f.InstructorID == course.InstructorID)
 }
 
 // Schedule defines a specific time, days, and recurrence for a course session.
@@ -55,9 +83,11 @@ type Schedule struct {
 	gorm.Model
 	// Use a single integer field to represent multiple days of the week.
 	// Example: A course on Saturday and Sunday would have DaysMask = 3 (1+2).
-	DaysMask   DayOfWeekMask
-	StartTime  time.Time          `gorm:"type:time"`
-	EndTime    time.Time          `gorm:"type:time"`
+	DaysMask DayOfWeekMask
+	// Only the clock part of StartTime and EndTime is meaningful. They are kept
+	// as datetime columns since the SQLite driver cannot scan a "time" column.
+	StartTime  time.Time
+	EndTime    time.Time
 	Recurrence ScheduleRecurrence // e.g., "weekly", "bi-weekly", "monthly"
 	CourseID   uint               // Foreign key for the Course
 }
@@ -66,10 +96,12 @@ type Schedule struct {
 // e.g., "Hatha Yoga" on "Monday, October 26, 2025 at 10:00 AM".
 type CourseSession struct {
 	gorm.Model
-	CourseID    uint
+	CourseID    uint `gorm:"uniqueIndex:idx_course_session_slot"`
 	Course      Course
-	ScheduledAt time.Time
+	ScheduleID  uint      // The schedule this session was generated from
+	ScheduledAt time.Time `gorm:"uniqueIndex:idx_course_session_slot"`
+	EndsAt      time.Time
 	// Optional: You could add fields like `Room`, `InstructorID` here if they vary.
-	// You might also add a boolean for `IsCancelled`.
-	IsCanceled bool
+	IsCanceled  bool
+	BookedCount int // Number of active bookings, never exceeds the course capacity
 }
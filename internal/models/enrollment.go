@@ -2,7 +2,9 @@ package models
 
 import (
 	"time"
+	"yoga-guru/internal/money"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -14,22 +16,61 @@ const (
 	Monthly    EnrollmentType = "monthly"
 	SixMonth   EnrollmentType = "six_month"
 	Yearly     EnrollmentType = "yearly"
+	Pack       EnrollmentType = "pack" // Sessions usable in any course matching the pack's filter
+)
+
+// EnrollmentStatus defines the lifecycle state of an enrollment.
+type EnrollmentStatus string
+
+const (
+	EnrollmentPending     EnrollmentStatus = "pending" // Waiting for an online payment
+	EnrollmentActive      EnrollmentStatus = "active"
+	EnrollmentCancelled   EnrollmentStatus = "cancelled"
+	EnrollmentExpired     EnrollmentStatus = "expired"
+	EnrollmentTransferred EnrollmentStatus = "transferred" // Its remaining value moved to another enrollment
 )
 
 // Enrollment represents a student's enrollment in a course or package.
 type Enrollment struct {
 	gorm.Model
-	UserID          uint
-	User            User
-	CourseID        uint // This is the main course this enrollment is for
-	Course          Course
-	EnrollmentType  EnrollmentType
-	StartDate       time.Time
-	ExpirationDate  time.Time
-	PricePaid       float64
-	DiscountApplied float64
-	TotalSessions   int // Only for fixed session packages
-	SessionsUsed    int // Counter for fixed session packages
+	UserID         uuid.UUID
+	User           User
+	CourseID       uint // This is the main course this enrollment is for
+	Course         Course
+	EnrollmentType EnrollmentType
+	PricePlanID    *uint // The plan the enrollment was bought with
+	PricePlan      *PricePlan
+	Status         EnrollmentStatus `gorm:"default:active"`
+	StartDate      time.Time
+	ExpirationDate time.Time
+	// Currency is what the amounts of the enrollment and its payments are
+	// in, the studio currency when it was bought.
+	Currency        money.Currency
+	PricePaid       money.Amount // The gross price, NetPrice plus Tax
+	NetPrice        money.Amount
+	Tax             money.Amount
+	TaxRate         float64 // Fraction of NetPrice charged as Tax (e.g., 0.09 for 9% VAT)
+	DiscountApplied float64 // Fraction taken off by the plan and any coupon (e.g., 0.10 for 10%)
+	TotalSessions   int     // Only for fixed session packages, zero means unlimited
+	SessionsUsed    int     // Counter for fixed session packages
+	// PackFilter selects the courses a pack enrollment can be booked in,
+	// besides the course it was bought for.
+	PackFilter CourseFilter `gorm:"embedded;embeddedPrefix:pack_"`
+	// AutoRenew renews the enrollment with the same plan when it expires.
+	AutoRenew             bool
+	RenewalReminderSentAt *time.Time
+	RenewedFromID         *uint // The enrollment this one renewed
+	// CancelledAt and CancellationReason are set once the enrollment is
+	// cancelled.
+	CancelledAt        *time.Time
+	CancellationReason string
+	// Balance is the amount still owed, PricePaid minus succeeded payments,
+	// or zero once cancelled or transferred. It is computed from the payments
+	// ledger and not stored.
+	Balance money.Amount `gorm:"-"`
+	// SessionsLeft is how many sessions of a fixed session package can
+	// still be booked, nil when unlimited. It is not stored.
+	SessionsLeft *int `gorm:"-"`
 	// A user can have many attendance records under this enrollment.
 	Attendances []Attendance `gorm:"foreignKey:EnrollmentID"`
 	Payments    []Payment    `gorm:"foreignKey:EnrollmentID"`
@@ -38,10 +79,11 @@ type Enrollment struct {
 // Attendance tracks whether a user attended a specific course session.
 type Attendance struct {
 	gorm.Model
-	UserID          uint
+	UserID          uuid.UUID `gorm:"uniqueIndex:idx_attendance_session_user"`
 	User            User
-	CourseSessionID uint
+	CourseSessionID uint `gorm:"uniqueIndex:idx_attendance_session_user"`
 	CourseSession   CourseSession
+	BookingID       uint // The booking the attendance was recorded for
 	EnrollmentID    uint
 	Enrollment      Enrollment
 	Attended        bool // `true` if the user attended, `false` otherwise
@@ -57,6 +99,10 @@ const (
 	PaymentSucceeded PaymentStatus = "succeeded"
 	PaymentFailed    PaymentStatus = "failed"
 	PaymentRefunded  PaymentStatus = "refunded"
+	// Refunds through the payment gateway are pending until it confirms them,
+	// and failed if it turns them down.
+	PaymentRefundPending PaymentStatus = "refund_pending"
+	PaymentRefundFailed  PaymentStatus = "refund_failed"
 )
 
 // PaymentMethod defines the method of payment.
@@ -67,6 +113,8 @@ const (
 	Cash          PaymentMethod = "cash"
 	BankTransfer  PaymentMethod = "bank_transfer"
 	OnlinePayment PaymentMethod = "online_payment"
+	Credit        PaymentMethod = "credit"   // Paid from the user's wallet
+	Transfer      PaymentMethod = "transfer" // Value moved from a transferred enrollment
 )
 
 // Payment represents a single financial transaction.
@@ -74,9 +122,39 @@ type Payment struct {
 	gorm.Model
 	EnrollmentID  uint // Foreign key to the enrollment this payment is for
 	Enrollment    Enrollment
-	Amount        float64       // The amount of this specific payment
-	Status        PaymentStatus // e.g., 'succeeded', 'failed', 'pending'
-	Method        PaymentMethod // e.g., 'card', 'cash'
-	TransactionID string        `gorm:"uniqueIndex"` // External ID from payment gateway (e.g., Stripe)
+	Currency      money.Currency // The currency of the enrollment paid for
+	Amount        money.Amount   // The amount of this specific payment, tax included
+	NetAmount     money.Amount   // Amount less Tax
+	Tax           money.Amount   // The part of Amount that is tax, at the enrollment's tax rate
+	Status        PaymentStatus  // e.g., 'succeeded', 'failed', 'pending'
+	Method        PaymentMethod  // e.g., 'card', 'cash'
+	TransactionID string         `gorm:"uniqueIndex"` // External ID from payment gateway (e.g., Stripe)
 	PaymentDate   time.Time
+	// Refunds are payments of their own, kept apart from the succeeded
+	// payment they pay back. Refunded is how much of a succeeded payment was
+	// refunded so far, and RefundOfID links a refund to the payment.
+	Refunded   money.Amount
+	RefundOfID *uint `gorm:"index"`
+}
+
+// BeforeCreate records the payment in the currency of the enrollment it is
+// for, and splits its amount into net amount and tax at the enrollment's tax
+// rate.
+func (p *Payment) BeforeCreate(tx *gorm.DB) error {
+	var enrollment Enrollment
+	err := tx.Session(&gorm.Session{NewDB: true}).Select("currency", "tax_rate").
+		Where("id = ?", p.EnrollmentID).Limit(1).Find(&enrollment).Error
+	if err != nil {
+		return err
+	}
+	p.Currency = enrollment.Currency
+	p.NetAmount, p.Tax = SplitTax(p.Amount, enrollment.TaxRate)
+	return nil
+}
+
+// SplitTax splits a gross amount into its net amount and the tax charged on
+// it at rate.
+func SplitTax(gross money.Amount, rate float64) (net, tax money.Amount) {
+	net = gross.Mul(1 / (1 + rate))
+	return net, gross - net
 }
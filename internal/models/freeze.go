@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FreezeStatus defines the state of an enrollment freeze.
+type FreezeStatus string
+
+const (
+	FreezePending   FreezeStatus = "pending" // Waiting for an admin to approve it
+	FreezeApproved  FreezeStatus = "approved"
+	FreezeRejected  FreezeStatus = "rejected"
+	FreezeCancelled FreezeStatus = "cancelled"
+)
+
+// EnrollmentFreeze pauses a long-term enrollment, for travel or an injury.
+// Once approved the enrollment cannot be booked during the freeze and its
+// expiration date moves out by the frozen days.
+type EnrollmentFreeze struct {
+	gorm.Model
+	EnrollmentID uint `gorm:"index"`
+	Enrollment   Enrollment
+	UserID       uuid.UUID
+	StartDate    time.Time // First frozen day
+	EndDate      time.Time // Day the enrollment can be used again
+	Days         int
+	Reason       string
+	Status       FreezeStatus
+	DecidedByID  *uuid.UUID // The admin who approved or rejected the freeze
+	DecidedAt    *time.Time
+}
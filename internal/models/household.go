@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Household is a family or group sharing the enrollments of its owner, who
+// pays for them. Members book sessions on the owner's enrollments.
+type Household struct {
+	gorm.Model
+	Name    string
+	OwnerID uuid.UUID `gorm:"uniqueIndex"` // A user owns at most one household
+	Owner   User
+	Members []HouseholdMember `gorm:"foreignKey:HouseholdID"`
+}
+
+// HouseholdMember links a user to the household whose enrollments they book
+// sessions on. Users are invited by the owner and only become members once
+// they accept.
+type HouseholdMember struct {
+	gorm.Model
+	HouseholdID uint      `gorm:"index"`
+	UserID      uuid.UUID `gorm:"uniqueIndex"` // A user belongs to at most one household
+	User        User
+	// SessionLimit caps the sessions the member can book on each of the
+	// owner's enrollments, zero means unlimited.
+	SessionLimit int
+	JoinedAt     *time.Time // Nil while the invitation is pending
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+	"yoga-guru/internal/money"
+
+	"gorm.io/gorm"
+)
+
+// InvoiceKind defines the kind of a numbered document.
+type InvoiceKind string
+
+const (
+	InvoiceKindInvoice InvoiceKind = "invoice" // Bills an enrollment
+	InvoiceKindReceipt InvoiceKind = "receipt" // Acknowledges a succeeded payment
+)
+
+// Invoice is a numbered invoice of an enrollment or receipt of a payment.
+// It is issued the first time it is downloaded and keeps its number and
+// amounts from then on.
+type Invoice struct {
+	gorm.Model
+	Kind         InvoiceKind `gorm:"uniqueIndex:idx_invoice_number"`
+	Series       string      `gorm:"uniqueIndex:idx_invoice_number"` // The studio's invoice prefix
+	Number       int         `gorm:"uniqueIndex:idx_invoice_number"`
+	EnrollmentID uint        `gorm:"index"`
+	PaymentID    *uint       `gorm:"uniqueIndex"` // Only for receipts
+	IssuedAt     time.Time
+	Currency     money.Currency
+	Subtotal     money.Amount // Before discount and tax
+	Discount     money.Amount
+	Tax          money.Amount
+	TaxRate      float64
+	Total        money.Amount
+}
+
+// InvoiceSequence holds the next number of a series of invoices or receipts.
+// It is advanced in the transaction issuing the number, so that numbers are
+// sequential without gaps.
+type InvoiceSequence struct {
+	Kind   InvoiceKind `gorm:"primaryKey"`
+	Series string      `gorm:"primaryKey"`
+	Next   int
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OTPPurpose is what a one-time code can be used for.
+type OTPPurpose string
+
+const (
+	OTPLogin         OTPPurpose = "login"
+	OTPPasswordReset OTPPurpose = "password_reset"
+)
+
+// OneTimeCode is a code texted to a phone number to log in or reset the
+// password with. Only its hash is stored, and it can be tried a limited
+// number of times before it expires.
+type OneTimeCode struct {
+	gorm.Model
+	Phone     string     `gorm:"index"`
+	Purpose   OTPPurpose `gorm:"default:login"`
+	CodeHash  string     `json:"-"`
+	ExpiresAt time.Time
+	Attempts  int        // Failed verifications so far
+	UsedAt    *time.Time // Set once the code logged a user in
+}
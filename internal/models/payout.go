@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+	"yoga-guru/internal/money"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PayoutRuleKind defines how a payout rule pays an instructor.
+type PayoutRuleKind string
+
+const (
+	PayoutPerSession   PayoutRuleKind = "per_session"   // Amount for every session taught
+	PayoutPerAttendee  PayoutRuleKind = "per_attendee"  // Amount for every student attending a session
+	PayoutRevenueShare PayoutRuleKind = "revenue_share" // Share of the net revenue of the instructor's courses
+)
+
+// PayoutRule is part of how an instructor is paid. An instructor is paid the
+// sum of their rules, e.g. a flat amount per session plus a share of revenue.
+type PayoutRule struct {
+	gorm.Model
+	InstructorID uuid.UUID `gorm:"index"`
+	CourseID     *uint     // Limits the rule to a single course when set
+	Course       *Course
+	Kind         PayoutRuleKind
+	Amount       money.Amount // Per session or attendee, in minor units of the studio currency
+	Share        float64      // Fraction of revenue for revenue shares (e.g., 0.40 for 40%)
+}
+
+// PayoutStatus defines the state of a payout statement.
+type PayoutStatus string
+
+const (
+	PayoutOpen PayoutStatus = "open" // Recomputed until it is paid
+	PayoutPaid PayoutStatus = "paid"
+)
+
+// PayoutStatement is what an instructor earned in a calendar month. Open
+// statements are recomputed from the sessions, attendance and payments of
+// the month each time they are fetched, and are kept as they are once paid.
+type PayoutStatement struct {
+	gorm.Model
+	InstructorID uuid.UUID `gorm:"uniqueIndex:idx_payout_month"`
+	Month        time.Time `gorm:"uniqueIndex:idx_payout_month"` // First day of the month
+	Status       PayoutStatus
+	Currency     money.Currency
+	Sessions     int          // Sessions taught
+	Attendees    int          // Students who attended them
+	Revenue      money.Amount // Net revenue of the instructor's courses
+	Amount       money.Amount // What the instructor is paid, the sum of Lines
+	Lines        []PayoutLine `gorm:"foreignKey:StatementID"`
+	PaidAt       *time.Time
+	PaidByID     *uuid.UUID // The admin who marked the statement paid
+	Reference    string     // e.g. the bank transfer the instructor was paid with
+}
+
+// PayoutLine is the part of a statement earned under one payout rule.
+type PayoutLine struct {
+	gorm.Model
+	StatementID uint `gorm:"index"`
+	RuleID      uint
+	Description string
+	Quantity    int          // Sessions or attendees, one for revenue shares
+	Basis       money.Amount // Amount per session or attendee, or the revenue shared
+	Amount      money.Amount
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"yoga-guru/internal/money"
+
+	"gorm.io/gorm"
+)
+
+// PricePlan is an enrollment package a student can buy. A plan applies to a
+// single course, to all courses of a course type, or to every course.
+type PricePlan struct {
+	gorm.Model
+	Name           string
+	EnrollmentType EnrollmentType // Kind of enrollment the plan sells
+	CourseID       *uint          // Set for a plan of a single course
+	CourseType     string         // Set for a plan of a course type, empty for all courses
+	Sessions       int            // Sessions priced into the plan, at the course's per session price
+	SessionLimit   int            // Sessions the enrollment allows, zero means unlimited
+	DurationMonths int            // How long the enrollment is valid
+	Discount       float64        // Stored as a fraction (e.g., 0.10 for 10%)
+	FixedPrice     *money.Amount  // Overrides the price computed from sessions and discount
+	Active         bool           // Inactive plans can no longer be bought
+	// PackFilter selects the courses a pack can be booked in. It is only
+	// used by plans of the pack enrollment type.
+	PackFilter CourseFilter `gorm:"embedded;embeddedPrefix:pack_"`
+}
+
+// Quote returns the price of the plan for the course and the discount
+// applied to it.
+func (p *PricePlan) Quote(course *Course) (price money.Amount, discount float64) {
+	if p.FixedPrice != nil {
+		return *p.FixedPrice, 0
+	}
+	return course.Price.Times(p.Sessions).Mul(1 - p.Discount), p.Discount
+}
+
+// Applies reports whether the plan can be bought for the course. A pack can
+// only be bought for a course it can be booked in.
+func (p *PricePlan) Applies(course *Course) bool {
+	if p.EnrollmentType == Pack && !p.PackFilter.Matches(course) {
+		return false
+	}
+	if p.CourseID != nil {
+		return *p.CourseID == course.ID
+	}
+	return p.CourseType == "" || p.CourseType == course.CourseType
+}
+
+// DefaultPricePlans are the plans a new studio starts with, matching the
+// enrollment types.
+func DefaultPricePlans() []PricePlan {
+	return []PricePlan{
+		// A single session to be booked within a month
+		{Name: "Single session", EnrollmentType: PreSession, Sessions: 1, SessionLimit: 1, DurationMonths: 1, Active: true},
+		// Assume 4 sessions in a month
+		{Name: "Monthly", EnrollmentType: Monthly, Sessions: 4, DurationMonths: 1, Discount: 0.10, Active: true},
+		// Assume 24 sessions in 6 months
+		{Name: "Six months", EnrollmentType: SixMonth, Sessions: 24, DurationMonths: 6, Discount: 0.20, Active: true},
+		// Assume 48 sessions in a year
+		{Name: "Yearly", EnrollmentType: Yearly, Sessions: 48, DurationMonths: 12, Discount: 0.30, Active: true},
+	}
+}
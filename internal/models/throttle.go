@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// LoginThrottleKind is what failed logins are counted by.
+type LoginThrottleKind string
+
+const (
+	ThrottleAccount LoginThrottleKind = "account" // By phone number
+	ThrottleIP      LoginThrottleKind = "ip"
+)
+
+// LoginThrottle counts the recent failed logins of a phone number or from an
+// IP address, and blocks further logins until BlockedUntil.
+type LoginThrottle struct {
+	ID            uint              `gorm:"primarykey"`
+	Kind          LoginThrottleKind `gorm:"uniqueIndex:idx_login_throttle"`
+	Key           string            `gorm:"uniqueIndex:idx_login_throttle"` // The phone number or IP address
+	Failures      int
+	LastFailureAt time.Time
+	BlockedUntil  time.Time
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceSession is a login on one device. It lasts across the refresh tokens
+// rotated from the login until it expires or is revoked.
+type DeviceSession struct {
+	ID         uuid.UUID `gorm:"type:uuid;primarykey"`
+	CreatedAt  time.Time
+	UserID     uuid.UUID `gorm:"index"`
+	UserAgent  string
+	IP         string     // Address the session was last used from
+	LastUsedAt time.Time  // When it logged in or last refreshed its tokens
+	ExpiresAt  time.Time  // When its current refresh token expires
+	RevokedAt  *time.Time // Set on logout or when token reuse is detected
+}
+
+// RefreshToken is a refresh token handed out to a device session, stored so
+// it can only be used once. Each refresh uses it up and issues a new token
+// to the session, so a token presented twice has leaked and the session is
+// revoked.
+type RefreshToken struct {
+	ID        uuid.UUID `gorm:"type:uuid;primarykey"` // The ID claim of the signed token
+	CreatedAt time.Time
+	UserID    uuid.UUID `gorm:"index"`
+	SessionID uuid.UUID `gorm:"index"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time // Set once exchanged for a new token
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"yoga-guru/internal/money"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EnrollmentTransfer records the remaining value of an enrollment moved to a
+// new enrollment in another course or for another student.
+type EnrollmentTransfer struct {
+	gorm.Model
+	FromEnrollmentID uint `gorm:"index"`
+	ToEnrollmentID   uint `gorm:"index"`
+	FromUserID       uuid.UUID
+	ToUserID         uuid.UUID
+	FromCourseID     uint
+	ToCourseID       uint
+	Value            money.Amount // Remaining value of the original enrollment
+	// PriceDifference is what the new enrollment costs over Value, owed on
+	// it when positive and credited to the wallet of FromUserID when negative.
+	PriceDifference money.Amount
+	TransferredByID uuid.UUID // The student or admin who made the transfer
+	Reason          string
+}
@@ -14,6 +14,7 @@ const (
 	Admin      UserRole = "admin"
 	Instructor UserRole = "instructor"
 	Student    UserRole = "student"
+	Staff      UserRole = "staff" // Front desk staff
 )
 
 type UserGender string
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+	"yoga-guru/internal/money"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Wallet holds the store credit of a user.
+type Wallet struct {
+	gorm.Model
+	UserID  uuid.UUID `gorm:"uniqueIndex"`
+	Balance money.Amount
+}
+
+// CreditTransactionKind defines why the credit of a wallet changed.
+type CreditTransactionKind string
+
+const (
+	CreditGiftCard   CreditTransactionKind = "gift_card"  // Credit loaded from a gift card
+	CreditPayment    CreditTransactionKind = "payment"    // Credit spent on an enrollment
+	CreditRefund     CreditTransactionKind = "refund"     // Credit issued for a refund
+	CreditAdjustment CreditTransactionKind = "adjustment" // Credit changed by an admin
+)
+
+// CreditTransaction is an entry in the history of a wallet.
+type CreditTransaction struct {
+	gorm.Model
+	UserID      uuid.UUID `gorm:"index"`
+	Kind        CreditTransactionKind
+	Amount      money.Amount // Positive when credit is added, negative when spent
+	Description string
+	PaymentID   *uint // The payment the credit was spent on or refunded from
+	GiftCardID  *uint // The gift card the credit was loaded from
+}
+
+// GiftCard is a code that loads credit into the wallet of whoever redeems it.
+type GiftCard struct {
+	gorm.Model
+	Code         string `gorm:"uniqueIndex"`
+	Amount       money.Amount
+	IssuedByID   uuid.UUID  // The staff member or admin who sold the gift card
+	ExpiresAt    *time.Time // Never expires when not set
+	RedeemedByID *uuid.UUID
+	RedeemedAt   *time.Time
+}
@@ -0,0 +1,118 @@
+// Package money represents amounts of money exactly, as whole minor units of
+// a currency, and formats them for display.
+package money
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Amount is a sum of money in minor units of a currency, e.g. rials or
+// cents. Amounts add and subtract exactly and are only rounded when
+// multiplied by a fraction.
+type Amount int64
+
+// Mul returns the amount multiplied by f, rounded half away from zero to a
+// whole minor unit. It is used to apply discounts, tax rates and pro-rating.
+func (a Amount) Mul(f float64) Amount {
+	return Amount(math.Round(float64(a) * f))
+}
+
+// Times returns the amount multiplied by n.
+func (a Amount) Times(n int) Amount {
+	return a * Amount(n)
+}
+
+// Currency is the code of a currency, an ISO 4217 code or IRT for the toman.
+type Currency string
+
+const (
+	IRR Currency = "IRR" // Iranian rial
+	IRT Currency = "IRT" // Toman, ten rials, how prices are commonly quoted in Iran
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+)
+
+// currencyInfo describes how a currency is counted and written.
+type currencyInfo struct {
+	digits int    // Decimal digits of the minor unit
+	name   string // Written after the amount
+	rials  int64  // Fixed rate in rials, zero when floating
+}
+
+var currencies = map[Currency]currencyInfo{
+	IRR: {digits: 0, name: "Rial", rials: 1},
+	IRT: {digits: 0, name: "Toman", rials: 10},
+	USD: {digits: 2, name: "USD"},
+	EUR: {digits: 2, name: "EUR"},
+}
+
+// Valid reports whether the currency is supported.
+func (c Currency) Valid() bool {
+	_, ok := currencies[c]
+	return ok
+}
+
+// Digits returns the number of decimal digits of the currency's minor unit.
+func (c Currency) Digits() int {
+	return currencies[c].digits
+}
+
+// FromMajor converts an amount in major units, e.g. dollars, to an Amount
+// in minor units of the currency.
+func FromMajor(v float64, c Currency) Amount {
+	return Amount(math.Round(v * math.Pow10(c.Digits())))
+}
+
+// Convert converts an amount between currencies at a fixed rate, the rial
+// and the toman. Other currencies have no fixed rate and only convert to
+// themselves.
+func Convert(a Amount, from, to Currency) (Amount, error) {
+	if from == to {
+		return a, nil
+	}
+	f, t := currencies[from], currencies[to]
+	if f.rials == 0 || t.rials == 0 {
+		return 0, fmt.Errorf("no fixed rate from %s to %s", from, to)
+	}
+	if f.rials > t.rials {
+		return a * Amount(f.rials/t.rials), nil
+	}
+	return a.Mul(float64(f.rials) / float64(t.rials)), nil
+}
+
+// Format writes the amount with thousands separators and the currency's
+// decimal digits, e.g. "1,250,000 Rial" or "12.50 USD".
+func Format(a Amount, c Currency) string {
+	info := currencies[c]
+	sign := ""
+	if a < 0 {
+		sign = "-"
+		a = -a
+	}
+
+	digits := strconv.FormatInt(int64(a), 10)
+	if len(digits) <= info.digits {
+		digits = strings.Repeat("0", info.digits-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-info.digits], digits[len(digits)-info.digits:]
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	if fraction != "" {
+		b.WriteString("." + fraction)
+	}
+	name := info.name
+	if name == "" {
+		name = string(c)
+	}
+	return b.String() + " " + name
+}
@@ -0,0 +1,42 @@
+package money
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		amount   Amount
+		currency Currency
+		want     string
+	}{
+		{0, IRR, "0 Rial"},
+		{1250000, IRR, "1,250,000 Rial"},
+		{-125000, IRT, "-125,000 Toman"},
+		{1250, USD, "12.50 USD"},
+		{5, EUR, "0.05 EUR"},
+		{123456789, USD, "1,234,567.89 USD"},
+	}
+	for _, tt := range tests {
+		if got := Format(tt.amount, tt.currency); got != tt.want {
+			t.Errorf("Format(%d, %s) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	if got, err := Convert(1250005, IRR, IRT); err != nil || got != 125001 {
+		t.Errorf("Convert(1250005 IRR to IRT) = %d, %v, want 125001", got, err)
+	}
+	if got, err := Convert(125000, IRT, IRR); err != nil || got != 1250000 {
+		t.Errorf("Convert(125000 IRT to IRR) = %d, %v, want 1250000", got, err)
+	}
+	if _, err := Convert(100, USD, IRR); err == nil {
+		t.Error("Convert(USD to IRR) succeeded, want an error")
+	}
+}
+
+func TestMul(t *testing.T) {
+	// 48 sessions at 2,500,000 rials with 30% off, exact in minor units
+	if got := Amount(2500000).Times(48).Mul(0.7); got != 84000000 {
+		t.Errorf("got %d, want 84000000", got)
+	}
+}
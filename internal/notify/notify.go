@@ -0,0 +1,22 @@
+package notify
+
+import (
+	"log"
+	"yoga-guru/internal/models"
+)
+
+// Notifier delivers short messages to users.
+type Notifier interface {
+	// Notify sends message to user.
+	Notify(user models.User, message string) error
+}
+
+// LogNotifier writes notifications to the application log. It is used when
+// no delivery channel is configured.
+type LogNotifier struct{}
+
+// Notify logs the message.
+func (LogNotifier) Notify(user models.User, message string) error {
+	log.Printf("notify %s (%s): %s", user.ID, user.Phone, message)
+	return nil
+}
@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// SMSSender delivers text messages to phone numbers, e.g. one-time login
+// codes.
+type SMSSender interface {
+	// SendSMS sends message to the E.164 phone number.
+	SendSMS(ctx context.Context, phone, message string) error
+}
+
+// ConsoleSMSSender writes text messages to the application log instead of
+// sending them. It is meant for development.
+type ConsoleSMSSender struct{}
+
+// SendSMS logs the message.
+func (ConsoleSMSSender) SendSMS(ctx context.Context, phone, message string) error {
+	log.Printf("sms %s: %s", phone, message)
+	return nil
+}
+
+// FileSMSSender appends text messages to a file instead of sending them, one
+// line each, for development and tests to read codes from.
+type FileSMSSender struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// SendSMS appends the message to the file.
+func (s *FileSMSSender) SendSMS(ctx context.Context, phone, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open SMS file: %w", err)
+	}
+	defer f.Close()
+	if _, err := fmt.Fprintf(f, "%s\t%s\t%s\n", time.Now().Format(time.RFC3339), phone, message); err != nil {
+		return fmt.Errorf("failed to write SMS file: %w", err)
+	}
+	return nil
+}
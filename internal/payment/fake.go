@@ -0,0 +1,118 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sync"
+	"yoga-guru/internal/money"
+
+	"github.com/google/uuid"
+)
+
+// FakeGateway is a local Gateway for development and tests. Payments are
+// approved instantly: the redirect URL leads straight back to the callback
+// URL with a signed successful result.
+type FakeGateway struct {
+	secret []byte
+
+	mu       sync.Mutex
+	amounts  map[string]money.Amount
+	refunded map[string]money.Amount
+}
+
+// NewFakeGateway creates a FakeGateway with a random signing secret.
+func NewFakeGateway() *FakeGateway {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(fmt.Sprintf("failed to generate fake gateway secret: %v", err))
+	}
+	return &FakeGateway{
+		secret:   secret,
+		amounts:  make(map[string]money.Amount),
+		refunded: make(map[string]money.Amount),
+	}
+}
+
+// CreatePayment implements Gateway.
+func (g *FakeGateway) CreatePayment(ctx context.Context, req Request) (*Checkout, error) {
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("invalid amount: %v", req.Amount)
+	}
+
+	transactionID := "fake-" + uuid.NewString()
+	g.mu.Lock()
+	g.amounts[transactionID] = req.Amount
+	g.mu.Unlock()
+
+	redirect, err := url.Parse(req.CallbackURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid callback URL: %w", err)
+	}
+	redirect.RawQuery = g.Callback(transactionID, true).Encode()
+
+	return &Checkout{TransactionID: transactionID, RedirectURL: redirect.String()}, nil
+}
+
+// Callback returns the signed callback parameters for a payment, as the
+// gateway would send them after the payer succeeded or gave up.
+func (g *FakeGateway) Callback(transactionID string, succeeded bool) url.Values {
+	status := "NOK"
+	if succeeded {
+		status = "OK"
+	}
+	return url.Values{
+		"transactionId": {transactionID},
+		"status":        {status},
+		"signature":     {g.sign(transactionID, status)},
+	}
+}
+
+// VerifyCallback implements Gateway.
+func (g *FakeGateway) VerifyCallback(ctx context.Context, params url.Values) (*Result, error) {
+	transactionID := params.Get("transactionId")
+	status := params.Get("status")
+	signature, err := hex.DecodeString(params.Get("signature"))
+	if err != nil || !hmac.Equal(signature, g.mac(transactionID, status)) {
+		return nil, ErrInvalidCallback
+	}
+
+	g.mu.Lock()
+	_, ok := g.amounts[transactionID]
+	g.mu.Unlock()
+	if !ok {
+		return nil, ErrInvalidCallback
+	}
+
+	return &Result{TransactionID: transactionID, Succeeded: status == "OK"}, nil
+}
+
+// Refund implements Gateway.
+func (g *FakeGateway) Refund(ctx context.Context, transactionID string, amount money.Amount) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	paid, ok := g.amounts[transactionID]
+	if !ok {
+		return fmt.Errorf("unknown transaction %s", transactionID)
+	}
+	if amount <= 0 || g.refunded[transactionID]+amount > paid {
+		return fmt.Errorf("invalid refund amount %v for transaction %s", amount, transactionID)
+	}
+	g.refunded[transactionID] += amount
+	return nil
+}
+
+func (g *FakeGateway) sign(transactionID, status string) string {
+	return hex.EncodeToString(g.mac(transactionID, status))
+}
+
+func (g *FakeGateway) mac(transactionID, status string) []byte {
+	m := hmac.New(sha256.New, g.secret)
+	m.Write([]byte(transactionID + ":" + status))
+	return m.Sum(nil)
+}
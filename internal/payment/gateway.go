@@ -0,0 +1,47 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"yoga-guru/internal/money"
+)
+
+// ErrInvalidCallback is returned when a gateway callback cannot be verified.
+var ErrInvalidCallback = errors.New("invalid payment callback")
+
+// Request describes a payment to start with a gateway.
+type Request struct {
+	Amount      money.Amount
+	Currency    money.Currency
+	Description string
+	// CallbackURL is where the gateway sends the payer back to once done.
+	CallbackURL string
+}
+
+// Checkout is a payment started with a gateway.
+type Checkout struct {
+	TransactionID string
+	// RedirectURL is where the payer completes the payment.
+	RedirectURL string
+}
+
+// Result is the verified outcome of a gateway callback.
+type Result struct {
+	TransactionID string
+	Succeeded     bool
+}
+
+// Gateway is an online payment provider.
+type Gateway interface {
+	// CreatePayment starts a payment the payer completes at the returned
+	// checkout's redirect URL.
+	CreatePayment(ctx context.Context, req Request) (*Checkout, error)
+
+	// VerifyCallback checks the parameters the gateway sent to the callback
+	// URL and returns the verified outcome of the payment.
+	VerifyCallback(ctx context.Context, params url.Values) (*Result, error)
+
+	// Refund returns amount of a succeeded payment to the payer.
+	Refund(ctx context.Context, transactionID string, amount money.Amount) error
+}
@@ -0,0 +1,48 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"yoga-guru/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// StartOnline starts a gateway payment for the outstanding balance of the
+// enrollment and records it as pending, failing the online payments still
+// open for the enrollment. The gateway sends the payer back to callbackURL
+// once done.
+func StartOnline(ctx context.Context, db *gorm.DB, gateway Gateway, callbackURL string, enrollment *models.Enrollment) (*models.Payment, *Checkout, error) {
+	checkout, err := gateway.CreatePayment(ctx, Request{
+		Amount:      enrollment.Balance,
+		Currency:    enrollment.Currency,
+		Description: fmt.Sprintf("Enrollment %d", enrollment.ID),
+		CallbackURL: callbackURL,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create gateway payment: %w", err)
+	}
+
+	p := models.Payment{
+		EnrollmentID:  enrollment.ID,
+		Amount:        enrollment.Balance,
+		Status:        models.PaymentPending,
+		Method:        models.OnlinePayment,
+		TransactionID: checkout.TransactionID,
+		PaymentDate:   time.Now(),
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Only the newest checkout is meant to be completed
+		if err := tx.Model(&models.Payment{}).
+			Where("enrollment_id = ? AND status = ? AND method = ?", enrollment.ID, models.PaymentPending, models.OnlinePayment).
+			Update("status", models.PaymentFailed).Error; err != nil {
+			return err
+		}
+		return tx.Create(&p).Error
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to record payment: %w", err)
+	}
+	return &p, checkout, nil
+}
@@ -0,0 +1,195 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"yoga-guru/internal/config"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/money"
+	"yoga-guru/internal/notify"
+	"yoga-guru/internal/payment"
+
+	"gorm.io/gorm"
+)
+
+// Renewals handles enrollments reaching their expiration date: it reminds
+// students ahead of time, renews the enrollments set to auto-renew and marks
+// the others expired.
+type Renewals struct {
+	DB       *gorm.DB
+	Notifier notify.Notifier
+	Gateway  payment.Gateway
+	// CallbackURL is where the gateway sends students paying a renewal.
+	CallbackURL string
+	// ReminderDays is how many days before expiry students are reminded.
+	ReminderDays int
+	// Tax is charged on the renewals.
+	Tax config.TaxPolicy
+	// Currency is the studio currency renewals are charged in.
+	Currency money.Currency
+}
+
+// Run periodically processes expiring enrollments until ctx is done.
+func (r *Renewals) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Process(ctx, time.Now()); err != nil {
+			log.Printf("enrollment renewal failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Process sends the reminders due at now, renews and expires the
+// enrollments that expired by now. Enrollments failing to renew are logged
+// and retried on the next run.
+func (r *Renewals) Process(ctx context.Context, now time.Time) error {
+	if err := r.SendReminders(now); err != nil {
+		return fmt.Errorf("failed to send renewal reminders: %w", err)
+	}
+
+	var expiring []models.Enrollment
+	if err := r.DB.Where("status = ? AND auto_renew = ? AND expiration_date <= ?", models.EnrollmentActive, true, now).
+		Find(&expiring).Error; err != nil {
+		return fmt.Errorf("failed to fetch enrollments to renew: %w", err)
+	}
+	var failed []uint
+	for i := range expiring {
+		if err := r.Renew(ctx, &expiring[i]); err != nil {
+			log.Printf("failed to renew enrollment %d: %v", expiring[i].ID, err)
+			failed = append(failed, expiring[i].ID)
+		}
+	}
+
+	expire := r.DB.Model(&models.Enrollment{}).
+		Where("status IN ? AND expiration_date <= ?", []models.EnrollmentStatus{models.EnrollmentActive, models.EnrollmentPending}, now)
+	if len(failed) > 0 {
+		expire = expire.Where("id NOT IN ?", failed) // Kept active to retry the renewal
+	}
+	if err := expire.Update("status", models.EnrollmentExpired).Error; err != nil {
+		return fmt.Errorf("failed to expire enrollments: %w", err)
+	}
+	return nil
+}
+
+// SendReminders tells the students whose enrollments expire within the
+// reminder period, once per enrollment.
+func (r *Renewals) SendReminders(now time.Time) error {
+	var enrollments []models.Enrollment
+	if err := r.DB.Preload("User").Preload("Course").
+		Where("status = ? AND renewal_reminder_sent_at IS NULL AND expiration_date > ? AND expiration_date <= ?",
+			models.EnrollmentActive, now, now.AddDate(0, 0, r.ReminderDays)).
+		Find(&enrollments).Error; err != nil {
+		return err
+	}
+
+	for _, enrollment := range enrollments {
+		message := fmt.Sprintf("Your enrollment in %s expires on %s. Renew it to keep booking.",
+			enrollment.Course.Title, enrollment.ExpirationDate.Format("Monday, January 2"))
+		if enrollment.AutoRenew {
+			message = fmt.Sprintf("Your enrollment in %s renews automatically on %s.",
+				enrollment.Course.Title, enrollment.ExpirationDate.Format("Monday, January 2"))
+		}
+		if err := r.Notifier.Notify(enrollment.User, message); err != nil {
+			log.Printf("failed to remind user %s of enrollment %d: %v", enrollment.UserID, enrollment.ID, err)
+			continue
+		}
+		if err := r.DB.Model(&enrollment).Update("renewal_reminder_sent_at", now).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Renew creates the enrollment following the given one with the same plan,
+// at the plan's current price, pending until it is paid. The student is sent
+// the link to pay online. A renewal whose payment could not be started is
+// picked up again, so the retry of a failed renewal starts its payment.
+func (r *Renewals) Renew(ctx context.Context, enrollment *models.Enrollment) error {
+	var renewal models.Enrollment
+	if err := r.DB.Where("renewed_from_id = ?", enrollment.ID).Limit(1).Find(&renewal).Error; err != nil {
+		return err
+	}
+	if renewal.ID != 0 {
+		if renewal.Status != models.EnrollmentPending {
+			return nil
+		}
+		var started int64
+		if err := r.DB.Model(&models.Payment{}).Where("enrollment_id = ? AND status IN ?", renewal.ID,
+			[]models.PaymentStatus{models.PaymentPending, models.PaymentSucceeded}).Count(&started).Error; err != nil {
+			return err
+		}
+		if started > 0 {
+			return nil
+		}
+	}
+
+	var user models.User
+	if err := r.DB.First(&user, "id = ?", enrollment.UserID).Error; err != nil {
+		return err
+	}
+	var course models.Course
+	if err := r.DB.First(&course, enrollment.CourseID).Error; err != nil {
+		return err
+	}
+
+	if renewal.ID == 0 {
+		var plan models.PricePlan
+		if enrollment.PricePlanID == nil || r.DB.First(&plan, *enrollment.PricePlanID).Error != nil || !plan.Active {
+			// The plan is gone, the student has to pick a new one
+			if err := r.DB.Model(enrollment).Update("auto_renew", false).Error; err != nil {
+				return err
+			}
+			return r.Notifier.Notify(user, fmt.Sprintf("Your enrollment in %s could not be renewed as its plan is no longer offered.", course.Title))
+		}
+
+		quote, discount := plan.Quote(&course)
+		net, tax, price := r.Tax.Apply(quote)
+		status := models.EnrollmentPending
+		if price <= 0 {
+			status = models.EnrollmentActive
+		}
+		renewal = models.Enrollment{
+			UserID:          enrollment.UserID,
+			CourseID:        enrollment.CourseID,
+			EnrollmentType:  plan.EnrollmentType,
+			PricePlanID:     &plan.ID,
+			Status:          status,
+			Currency:        r.Currency,
+			StartDate:       enrollment.ExpirationDate,
+			ExpirationDate:  enrollment.ExpirationDate.AddDate(0, plan.DurationMonths, 0),
+			PricePaid:       price,
+			NetPrice:        net,
+			Tax:             tax,
+			TaxRate:         r.Tax.Rate,
+			DiscountApplied: discount,
+			TotalSessions:   plan.SessionLimit,
+			PackFilter:      plan.PackFilter,
+			AutoRenew:       true,
+			RenewedFromID:   &enrollment.ID,
+		}
+		if err := r.DB.Create(&renewal).Error; err != nil {
+			return err
+		}
+	}
+
+	message := fmt.Sprintf("Your enrollment in %s was renewed until %s.", course.Title, renewal.ExpirationDate.Format("Monday, January 2"))
+	if renewal.Status == models.EnrollmentPending {
+		renewal.Balance = renewal.PricePaid
+		_, checkout, err := payment.StartOnline(ctx, r.DB, r.Gateway, r.CallbackURL, &renewal)
+		if err != nil {
+			return err
+		}
+		message += " Pay for it at " + checkout.RedirectURL
+	}
+	return r.Notifier.Notify(user, message)
+}
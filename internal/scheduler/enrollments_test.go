@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/payment"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// countingNotifier counts the notifications sent to each user.
+type countingNotifier map[uuid.UUID]int
+
+func (n countingNotifier) Notify(user models.User, message string) error {
+	n[user.ID]++
+	return nil
+}
+
+func TestRenewalsProcess(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Course{}, &models.Enrollment{}, &models.Payment{}, &models.PricePlan{}); err != nil {
+		t.Fatal(err)
+	}
+
+	course := models.Course{Title: "Vinyasa", Price: 100}
+	db.Create(&course)
+	plan := models.PricePlan{Name: "Monthly", EnrollmentType: models.Monthly, Sessions: 1, DurationMonths: 1, Active: true}
+	db.Create(&plan)
+
+	now := time.Now()
+	create := func(phone string, autoRenew bool, expires time.Time) (models.User, models.Enrollment) {
+		user := models.User{Phone: phone, Role: models.Student}
+		db.Create(&user)
+		enrollment := models.Enrollment{
+			UserID:         user.ID,
+			CourseID:       course.ID,
+			EnrollmentType: models.Monthly,
+			PricePlanID:    &plan.ID,
+			Status:         models.EnrollmentActive,
+			StartDate:      expires.AddDate(0, -1, 0),
+			ExpirationDate: expires,
+			PricePaid:      100,
+			AutoRenew:      autoRenew,
+		}
+		db.Create(&enrollment)
+		return user, enrollment
+	}
+	renewing, renewed := create("+989120000001", true, now.Add(-time.Hour))
+	lapsing, lapsed := create("+989120000002", false, now.Add(-time.Hour))
+	expiring, _ := create("+989120000003", false, now.AddDate(0, 0, 3))
+
+	notifier := countingNotifier{}
+	renewals := &Renewals{
+		DB:           db,
+		Notifier:     notifier,
+		Gateway:      payment.NewFakeGateway(),
+		CallbackURL:  "http://localhost/payments/callback",
+		ReminderDays: 7,
+	}
+	for range 2 {
+		if err := renewals.Process(context.Background(), now); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, enrollment := range []models.Enrollment{renewed, lapsed} {
+		db.First(&enrollment, enrollment.ID)
+		if enrollment.Status != models.EnrollmentExpired {
+			t.Errorf("enrollment %d is %s, want expired", enrollment.ID, enrollment.Status)
+		}
+	}
+
+	var next []models.Enrollment
+	db.Where("renewed_from_id = ?", renewed.ID).Find(&next)
+	if len(next) != 1 {
+		t.Fatalf("got %d renewals, want 1", len(next))
+	}
+	renewal := next[0]
+	if renewal.Status != models.EnrollmentPending || !renewal.AutoRenew ||
+		!renewal.StartDate.Equal(renewed.ExpirationDate) || renewal.PricePaid != 100 {
+		t.Errorf("unexpected renewal %+v", renewal)
+	}
+	var pending int64
+	db.Model(&models.Payment{}).Where("enrollment_id = ? AND status = ?", renewal.ID, models.PaymentPending).Count(&pending)
+	if pending != 1 {
+		t.Errorf("got %d pending payments for the renewal, want 1", pending)
+	}
+
+	if notifier[renewing.ID] != 1 || notifier[lapsing.ID] != 0 || notifier[expiring.ID] != 1 {
+		t.Errorf("unexpected notifications %v", notifier)
+	}
+}
+
+// failingGateway turns down starting payments while down is set.
+type failingGateway struct {
+	payment.Gateway
+	down bool
+}
+
+func (g *failingGateway) CreatePayment(ctx context.Context, req payment.Request) (*payment.Checkout, error) {
+	if g.down {
+		return nil, errors.New("gateway unavailable")
+	}
+	return g.Gateway.CreatePayment(ctx, req)
+}
+
+func TestRenewalsRetryPaymentAfterGatewayFailure(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Course{}, &models.Enrollment{}, &models.Payment{}, &models.PricePlan{}); err != nil {
+		t.Fatal(err)
+	}
+
+	course := models.Course{Title: "Vinyasa", Price: 100}
+	db.Create(&course)
+	plan := models.PricePlan{Name: "Monthly", EnrollmentType: models.Monthly, Sessions: 1, DurationMonths: 1, Active: true}
+	db.Create(&plan)
+	user := models.User{Phone: "+989120000001", Role: models.Student}
+	db.Create(&user)
+	now := time.Now()
+	enrollment := models.Enrollment{UserID: user.ID, CourseID: course.ID, EnrollmentType: models.Monthly, PricePlanID: &plan.ID,
+		Status: models.EnrollmentActive, StartDate: now.AddDate(0, -1, 0), ExpirationDate: now.Add(-time.Hour), PricePaid: 100, AutoRenew: true}
+	db.Create(&enrollment)
+
+	gateway := &failingGateway{Gateway: payment.NewFakeGateway(), down: true}
+	notifier := countingNotifier{}
+	renewals := &Renewals{DB: db, Notifier: notifier, Gateway: gateway, CallbackURL: "http://localhost/payments/callback"}
+
+	// The renewal is created but its payment can't be started
+	if err := renewals.Process(context.Background(), now); err != nil {
+		t.Fatal(err)
+	}
+	db.First(&enrollment, enrollment.ID)
+	if enrollment.Status != models.EnrollmentActive || notifier[user.ID] != 0 {
+		t.Fatalf("got enrollment %s with %d notifications, want it kept active to retry without notifying", enrollment.Status, notifier[user.ID])
+	}
+
+	// The next run starts the payment of the same renewal
+	gateway.down = false
+	for range 2 {
+		if err := renewals.Process(context.Background(), now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var next []models.Enrollment
+	db.Where("renewed_from_id = ?", enrollment.ID).Find(&next)
+	if len(next) != 1 {
+		t.Fatalf("got %d renewals, want 1", len(next))
+	}
+	var pending int64
+	db.Model(&models.Payment{}).Where("enrollment_id = ? AND status = ?", next[0].ID, models.PaymentPending).Count(&pending)
+	db.First(&enrollment, enrollment.ID)
+	if pending != 1 || enrollment.Status != models.EnrollmentExpired || notifier[user.ID] != 1 {
+		t.Errorf("got %d pending payments, enrollment %s and %d notifications, want 1, expired and 1",
+			pending, enrollment.Status, notifier[user.ID])
+	}
+}
@@ -0,0 +1,29 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+	"yoga-guru/internal/notify"
+
+	"gorm.io/gorm"
+)
+
+// Run periodically materializes upcoming course sessions until ctx is done.
+// Students are notified through notifier of bookings canceled with sessions.
+func Run(ctx context.Context, db *gorm.DB, notifier notify.Notifier, interval, horizon time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := GenerateUpcomingSessions(db, notifier, horizon); err != nil {
+			log.Printf("session generation failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
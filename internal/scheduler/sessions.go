@@ -0,0 +1,212 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"time"
+	"yoga-guru/internal/models"
+	"yoga-guru/internal/notify"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Occurrences expands a schedule into the concrete session start times that
+// fall within [from, to). Times are interpreted in the given location.
+//
+// Weekly schedules repeat on every selected day, bi-weekly schedules on every
+// other week counted from the week the schedule was created, and monthly
+// schedules on the first selected weekday of each month.
+func Occurrences(s models.Schedule, from, to time.Time, loc *time.Location) []time.Time {
+	var result []time.Time
+
+	from = from.In(loc)
+	to = to.In(loc)
+	anchor := startOfWeek(s.CreatedAt.In(loc))
+	hour, minute, second := s.StartTime.Clock()
+
+	for day := startOfDay(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !s.DaysMask.Has(day.Weekday()) {
+			continue
+		}
+
+		switch s.Recurrence {
+		case models.BiWeekly:
+			weeks := daysBetween(anchor, startOfWeek(day)) / 7
+			if weeks%2 != 0 {
+				continue
+			}
+		case models.MonthlyR:
+			// Only the first matching weekday of the month.
+			if day.Day() > 7 {
+				continue
+			}
+		}
+
+		at := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, second, 0, loc)
+		if at.Before(from) || !at.Before(to) {
+			continue
+		}
+		result = append(result, at)
+	}
+
+	return result
+}
+
+// sessionEnd returns the end time of a session starting at start.
+func sessionEnd(s models.Schedule, start time.Time) time.Time {
+	hour, minute, second := s.EndTime.Clock()
+	end := time.Date(start.Year(), start.Month(), start.Day(), hour, minute, second, 0, start.Location())
+	if !end.After(start) {
+		// Sessions running past midnight end on the next day.
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// GenerateSessions materializes the sessions of a course between from and to.
+// It is idempotent: sessions that already exist are left untouched and future
+// sessions that no longer match any schedule are removed. Booked sessions are
+// canceled instead, and their students notified that their bookings were.
+func GenerateSessions(db *gorm.DB, notifier notify.Notifier, courseID uint, from, to time.Time) error {
+	var schedules []models.Schedule
+	if err := db.Where("course_id = ?", courseID).Find(&schedules).Error; err != nil {
+		return fmt.Errorf("failed to fetch schedules: %w", err)
+	}
+
+	var bookings []models.Booking
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var slots []time.Time
+		for _, schedule := range schedules {
+			for _, at := range Occurrences(schedule, from, to, time.Local) {
+				session := models.CourseSession{
+					CourseID:    courseID,
+					ScheduleID:  schedule.ID,
+					ScheduledAt: at,
+					EndsAt:      sessionEnd(schedule, at),
+				}
+				err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&session).Error
+				if err != nil {
+					return fmt.Errorf("failed to create session: %w", err)
+				}
+				slots = append(slots, at)
+			}
+		}
+
+		// Sessions that already started are history and never removed.
+		if now := time.Now(); from.Before(now) {
+			from = now
+		}
+		var existing []models.CourseSession
+		err := tx.Where("course_id = ? AND scheduled_at >= ? AND scheduled_at < ?", courseID, from, to).
+			Find(&existing).Error
+		if err != nil {
+			return fmt.Errorf("failed to fetch sessions: %w", err)
+		}
+
+		var stale, canceled []uint
+		for _, session := range existing {
+			if session.IsCanceled || slices.ContainsFunc(slots, session.ScheduledAt.Equal) {
+				continue
+			}
+			// Booked sessions are kept, canceled, so students can see what happened
+			if session.BookedCount > 0 {
+				canceled = append(canceled, session.ID)
+			} else {
+				stale = append(stale, session.ID)
+			}
+		}
+		if len(stale) > 0 {
+			if err := tx.Unscoped().Delete(&models.CourseSession{}, stale).Error; err != nil {
+				return fmt.Errorf("failed to remove stale sessions: %w", err)
+			}
+		}
+		bookings, err = CancelSessions(tx, canceled)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	NotifyCancelledBookings(notifier, bookings)
+	return nil
+}
+
+// CancelSessions cancels the sessions with the given IDs along with their
+// bookings and waitlist places, which no longer hold a session of their
+// enrollment. It returns the cancelled bookings, with their session, course
+// and user loaded to notify the students.
+func CancelSessions(tx *gorm.DB, ids []uint) ([]models.Booking, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if err := tx.Model(&models.CourseSession{}).Where("id IN ?", ids).
+		Updates(map[string]any{"is_canceled": true, "booked_count": 0}).Error; err != nil {
+		return nil, fmt.Errorf("failed to cancel sessions: %w", err)
+	}
+
+	var bookings []models.Booking
+	if err := tx.Preload("CourseSession.Course").Preload("User").
+		Where("course_session_id IN ? AND status IN ?", ids,
+			[]models.BookingStatus{models.BookingBooked, models.BookingWaitlisted}).
+		Find(&bookings).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch bookings of canceled sessions: %w", err)
+	}
+	now := time.Now()
+	for i := range bookings {
+		bookings[i].Status, bookings[i].CancelledAt = models.BookingCancelled, &now
+		if err := tx.Model(&bookings[i]).Select("Status", "CancelledAt").Updates(&bookings[i]).Error; err != nil {
+			return nil, fmt.Errorf("failed to cancel booking: %w", err)
+		}
+	}
+	return bookings, nil
+}
+
+// NotifyCancelledBookings tells the students of bookings returned by
+// CancelSessions that their session was canceled.
+func NotifyCancelledBookings(notifier notify.Notifier, bookings []models.Booking) {
+	for _, booking := range bookings {
+		message := fmt.Sprintf("%s on %s was canceled, and your booking with it.",
+			booking.CourseSession.Course.Title, booking.CourseSession.ScheduledAt.Format("Mon Jan 2 15:04"))
+		if err := notifier.Notify(booking.User, message); err != nil {
+			log.Printf("failed to notify user %s of canceled booking %d: %v", booking.UserID, booking.ID, err)
+		}
+	}
+}
+
+// GenerateUpcomingSessions materializes sessions for every course over the
+// given horizon, starting today.
+func GenerateUpcomingSessions(db *gorm.DB, notifier notify.Notifier, horizon time.Duration) error {
+	var courseIDs []uint
+	if err := db.Model(&models.Course{}).Pluck("id", &courseIDs).Error; err != nil {
+		return fmt.Errorf("failed to fetch courses: %w", err)
+	}
+
+	from := startOfDay(time.Now())
+	to := from.Add(horizon)
+	for _, id := range courseIDs {
+		if err := GenerateSessions(db, notifier, id, from, to); err != nil {
+			return fmt.Errorf("course %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// daysBetween counts the calendar days from a to b, whatever daylight saving
+// time changes lie between them.
+func daysBetween(a, b time.Time) int {
+	from := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// startOfWeek returns the Saturday starting the week of t.
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 1) % 7
+	return startOfDay(t).AddDate(0, 0, -offset)
+}
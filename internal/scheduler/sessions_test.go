@@ -0,0 +1,202 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+	"yoga-guru/internal/models"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func clock(hour, minute int) time.Time {
+	return time.Date(0, 1, 1, hour, minute, 0, 0, time.UTC)
+}
+
+func TestOccurrences(t *testing.T) {
+	// Saturday, 4 October 2025
+	created := time.Date(2025, 10, 4, 8, 0, 0, 0, time.UTC)
+	from := time.Date(2025, 10, 4, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 28)
+
+	tests := []struct {
+		name     string
+		schedule models.Schedule
+		want     []string
+	}{
+		{
+			name: "weekly on monday and wednesday",
+			schedule: models.Schedule{
+				DaysMask:   models.Monday | models.Wednesday,
+				StartTime:  clock(10, 0),
+				Recurrence: models.Weekly,
+			},
+			want: []string{
+				"2025-10-06 10:00", "2025-10-08 10:00",
+				"2025-10-13 10:00", "2025-10-15 10:00",
+				"2025-10-20 10:00", "2025-10-22 10:00",
+				"2025-10-27 10:00", "2025-10-29 10:00",
+			},
+		},
+		{
+			name: "bi-weekly on saturday",
+			schedule: models.Schedule{
+				DaysMask:   models.Saturday,
+				StartTime:  clock(18, 30),
+				Recurrence: models.BiWeekly,
+			},
+			want: []string{"2025-10-04 18:30", "2025-10-18 18:30"},
+		},
+		{
+			name: "monthly on sunday and friday",
+			schedule: models.Schedule{
+				DaysMask:   models.Sunday | models.Friday,
+				StartTime:  clock(7, 0),
+				Recurrence: models.MonthlyR,
+			},
+			want: []string{"2025-10-05 07:00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.schedule.CreatedAt = created
+			got := Occurrences(tt.schedule, from, to, time.UTC)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d occurrences %v, want %d", len(got), got, len(tt.want))
+			}
+			for i, at := range got {
+				if s := at.Format("2006-01-02 15:04"); s != tt.want[i] {
+					t.Errorf("occurrence %d: got %s, want %s", i, s, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateSessionsIsIdempotent(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.Course{}, &models.Schedule{}, &models.CourseSession{}); err != nil {
+		t.Fatal(err)
+	}
+
+	course := models.Course{
+		Title: "Hatha",
+		Schedules: []models.Schedule{{
+			DaysMask:   models.Saturday | models.Sunday | models.Monday | models.Tuesday | models.Wednesday | models.Thursday | models.Friday,
+			StartTime:  clock(23, 59),
+			EndTime:    clock(23, 59),
+			Recurrence: models.Weekly,
+		}},
+	}
+	if err := db.Create(&course).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Now()
+	to := from.AddDate(0, 0, 7)
+	for range 2 {
+		if err := GenerateSessions(db, countingNotifier{}, course.ID, from, to); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var count int64
+	db.Model(&models.CourseSession{}).Where("course_id = ?", course.ID).Count(&count)
+	if count != 7 {
+		t.Errorf("got %d sessions, want 7", count)
+	}
+
+	// Dropping the schedule removes the upcoming sessions
+	db.Where("course_id = ?", course.ID).Delete(&models.Schedule{})
+	if err := GenerateSessions(db, countingNotifier{}, course.ID, from, to); err != nil {
+		t.Fatal(err)
+	}
+	db.Model(&models.CourseSession{}).Where("course_id = ?", course.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("got %d sessions after removing the schedule, want 0", count)
+	}
+}
+
+func TestGenerateSessionsCancelsBookings(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Course{}, &models.Schedule{}, &models.CourseSession{}, &models.Booking{}); err != nil {
+		t.Fatal(err)
+	}
+
+	course := models.Course{
+		Title: "Hatha",
+		Schedules: []models.Schedule{{
+			DaysMask:   models.Saturday | models.Sunday | models.Monday | models.Tuesday | models.Wednesday | models.Thursday | models.Friday,
+			StartTime:  clock(23, 59),
+			EndTime:    clock(23, 59),
+			Recurrence: models.Weekly,
+		}},
+	}
+	db.Create(&course)
+	student := models.User{Phone: "+989120000001", Role: models.Student}
+	db.Create(&student)
+
+	from := time.Now()
+	to := from.AddDate(0, 0, 7)
+	notifier := countingNotifier{}
+	if err := GenerateSessions(db, notifier, course.ID, from, to); err != nil {
+		t.Fatal(err)
+	}
+	var session models.CourseSession
+	db.Where("course_id = ?", course.ID).Order("scheduled_at").First(&session)
+	db.Model(&session).Update("booked_count", 1)
+	booking := models.Booking{CourseSessionID: session.ID, UserID: student.ID, Status: models.BookingBooked}
+	db.Create(&booking)
+
+	// Dropping the schedule cancels the booked session and its booking, once
+	db.Where("course_id = ?", course.ID).Delete(&models.Schedule{})
+	for range 2 {
+		if err := GenerateSessions(db, notifier, course.ID, from, to); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.First(&session, session.ID)
+	db.First(&booking, booking.ID)
+	if !session.IsCanceled || session.BookedCount != 0 {
+		t.Errorf("got session canceled %v with %d booked, want canceled with none booked", session.IsCanceled, session.BookedCount)
+	}
+	if booking.Status != models.BookingCancelled || booking.CancelledAt == nil {
+		t.Errorf("got booking %q, want it cancelled", booking.Status)
+	}
+	if notifier[student.ID] != 1 {
+		t.Errorf("got %d notifications, want 1", notifier[student.ID])
+	}
+}
+
+func TestOccurrencesBiWeeklyAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	// Clocks go forward on Sunday, 29 March 2026, making that week an hour short
+	schedule := models.Schedule{
+		DaysMask:   models.Monday,
+		StartTime:  clock(18, 0),
+		Recurrence: models.BiWeekly,
+	}
+	schedule.CreatedAt = time.Date(2026, 3, 21, 8, 0, 0, 0, loc)
+	from := time.Date(2026, 3, 21, 0, 0, 0, 0, loc)
+
+	got := Occurrences(schedule, from, from.AddDate(0, 0, 35), loc)
+	want := []string{"2026-03-23 18:00", "2026-04-06 18:00", "2026-04-20 18:00"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d occurrences %v, want %v", len(got), got, want)
+	}
+	for i, at := range got {
+		if s := at.Format("2006-01-02 15:04"); s != want[i] {
+			t.Errorf("occurrence %d: got %s, want %s", i, s, want[i])
+		}
+	}
+}
@@ -19,6 +19,11 @@ import (
 
 func (s *Server) RegisterRoutes() http.Handler {
 	r := gin.Default()
+	// Client IPs key login throttling and device sessions, so forwarded
+	// addresses are only believed from our own proxies
+	if err := r.SetTrustedProxies(s.cfg.TrustedProxies); err != nil {
+		log.Fatalf("invalid trusted proxies: %v", err)
+	}
 
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:5173"}, // Add your frontend URL
@@ -33,17 +38,34 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 	r.GET("/websocket", s.websocketHandler)
 	// Initialize handlers
-	authHandler := controllers.NewAuthHandler(s.db.Getgorm(), s.cfg)
+	authHandler := controllers.NewAuthHandler(s.db.Getgorm(), s.cfg, s.sms)
 	userHandler := controllers.NewUserHandler(s.db.Getgorm())
-	courseHandler := controllers.NewCourseHandler(s.db.Getgorm())
-	enrollmentHandler := controllers.NewEnrollmentHandler(s.db.Getgorm())
+	courseHandler := controllers.NewCourseHandler(s.db.Getgorm(), s.cfg, s.notifier)
+	enrollmentHandler := controllers.NewEnrollmentHandler(s.db.Getgorm(), s.cfg, s.notifier, s.gateway)
+	sessionHandler := controllers.NewSessionHandler(s.db.Getgorm(), s.cfg, s.notifier)
+	paymentHandler := controllers.NewPaymentHandler(s.db.Getgorm(), s.cfg, s.gateway)
+	planHandler := controllers.NewPricePlanHandler(s.db.Getgorm(), s.cfg)
+	couponHandler := controllers.NewCouponHandler(s.db.Getgorm())
+	walletHandler := controllers.NewWalletHandler(s.db.Getgorm())
+	freezeHandler := controllers.NewFreezeHandler(s.db.Getgorm(), s.cfg, s.notifier)
+	householdHandler := controllers.NewHouseholdHandler(s.db.Getgorm(), s.notifier)
+	payoutHandler := controllers.NewPayoutHandler(s.db.Getgorm(), s.cfg, s.notifier)
 
 	// Public routes
 	r.POST("/register", authHandler.Register)
 	r.POST("/login", authHandler.Login)
 	r.POST("/refresh", authHandler.RefreshToken)
+	r.POST("/logout", authHandler.Logout)
+	r.POST("/password/forgot", authHandler.ForgotPassword)
+	r.POST("/password/reset", authHandler.ResetPassword)
+	r.POST("/otp/request", authHandler.RequestOTP) // Log in or sign up with a code texted to the phone
+	r.POST("/otp/verify", authHandler.VerifyOTP)
 	r.GET("/courses", courseHandler.GetCourses)        // Anyone can view courses
 	r.GET("/courses/:id", courseHandler.GetCourseByID) // Anyone can view a specific course
+	r.GET("/courses/:id/sessions", sessionHandler.GetCourseSessions)
+	r.GET("/courses/:id/plans", planHandler.GetCoursePlans)
+	r.GET("/payments/callback", paymentHandler.PaymentCallback) // The payment gateway redirects the payer here
+	r.POST("/payments/callback", paymentHandler.PaymentCallback)
 
 	// Authenticated routes
 	authorized := r.Group("/")
@@ -51,14 +73,55 @@ func (s *Server) RegisterRoutes() http.Handler {
 	{
 		// User routes
 		authorized.GET("/users/me", userHandler.GetCurrentUserProfile)
+		authorized.POST("/logout/all", authHandler.LogoutAll)
+		authorized.PUT("/users/me/password", authHandler.ChangePassword)
+		authorized.GET("/users/me/sessions", authHandler.GetMySessions)
+		authorized.DELETE("/users/me/sessions/:id", authHandler.RevokeMySession)
+
+		// Store credit of the current user
+		authorized.GET("/wallet/me", walletHandler.GetMyWallet)
+		authorized.POST("/wallet/redeem", walletHandler.RedeemGiftCard)
+
+		// Gift cards are sold at the front desk
+		staffAdminGroup := authorized.Group("/")
+		staffAdminGroup.Use(middleware.AuthorizeRole(models.Staff, models.Admin))
+		{
+			staffAdminGroup.GET("/users/:id/wallet", walletHandler.GetUserWallet)
+			staffAdminGroup.GET("/gift-cards", walletHandler.GetGiftCards)
+			staffAdminGroup.POST("/gift-cards", walletHandler.CreateGiftCard)
+		}
 
 		// Admin-only user routes
 		adminGroup := authorized.Group("/")
 		adminGroup.Use(middleware.AuthorizeRole(models.Admin))
 		{
 			adminGroup.PUT("/users/:id/role", userHandler.UpdateUserRole)
+			adminGroup.POST("/users/:id/unlock", authHandler.UnlockUser)
+			adminGroup.POST("/users/:id/wallet/adjustments", walletHandler.AdjustCredit)
+			adminGroup.GET("/users/:id/sessions", authHandler.GetUserSessions)
+			adminGroup.DELETE("/users/:id/sessions/:sessionID", authHandler.RevokeUserSession)
+			adminGroup.GET("/plans", planHandler.GetPlans)
+			adminGroup.POST("/plans", planHandler.CreatePlan)
+			adminGroup.PUT("/plans/:id", planHandler.UpdatePlan)
+			adminGroup.DELETE("/plans/:id", planHandler.DeletePlan)
+			adminGroup.GET("/coupons", couponHandler.GetCoupons)
+			adminGroup.POST("/coupons", couponHandler.CreateCoupon)
+			adminGroup.PUT("/coupons/:id", couponHandler.UpdateCoupon)
+			adminGroup.DELETE("/coupons/:id", couponHandler.DeleteCoupon)
+			adminGroup.GET("/coupons/:id/redemptions", couponHandler.GetCouponRedemptions)
+			adminGroup.GET("/freezes", freezeHandler.GetFreezes)
+			adminGroup.POST("/freezes/:id/approve", freezeHandler.ApproveFreeze)
+			adminGroup.POST("/freezes/:id/reject", freezeHandler.RejectFreeze)
+			adminGroup.GET("/instructors/:id/payout-rules", payoutHandler.GetPayoutRules)
+			adminGroup.PUT("/instructors/:id/payout-rules", payoutHandler.SetPayoutRules)
+			adminGroup.GET("/instructors/:id/payouts", payoutHandler.GetInstructorPayout)
+			adminGroup.GET("/payouts", payoutHandler.GetPayouts)
+			adminGroup.POST("/payouts/:id/pay", payoutHandler.MarkPayoutPaid)
 		}
 
+		// Instructors see what they earned under their payout rules
+		authorized.GET("/instructors/me/payouts", middleware.AuthorizeRole(models.Instructor), payoutHandler.GetMyPayout)
+
 		// Instructor and Admin routes for course management
 		instructorAdminGroup := authorized.Group("/courses")
 		instructorAdminGroup.Use(middleware.AuthorizeRole(models.Instructor, models.Admin))
@@ -76,7 +139,66 @@ func (s *Server) RegisterRoutes() http.Handler {
 			studentAdminGroup.GET("/me", enrollmentHandler.GetStudentEnrollments)
 			studentAdminGroup.GET("/:id", enrollmentHandler.GetEnrollmentByID)
 			studentAdminGroup.DELETE("/:id", enrollmentHandler.CancelEnrollment)
+			studentAdminGroup.PUT("/:id/auto-renew", enrollmentHandler.SetAutoRenew)
+			studentAdminGroup.POST("/:id/transfer", enrollmentHandler.TransferEnrollment)
+			studentAdminGroup.GET("/:id/transfers", enrollmentHandler.GetEnrollmentTransfers)
+			studentAdminGroup.GET("/:id/freezes", freezeHandler.GetEnrollmentFreezes)
+			studentAdminGroup.POST("/:id/freezes", freezeHandler.RequestFreeze)
+			studentAdminGroup.DELETE("/:id/freezes/:freezeID", freezeHandler.CancelFreeze)
+		}
+
+		// Households share the enrollments of their owner with its members
+		householdGroup := authorized.Group("/households")
+		householdGroup.Use(middleware.AuthorizeRole(models.Student, models.Admin))
+		{
+			householdGroup.POST("", householdHandler.CreateHousehold)
+			householdGroup.GET("/me", householdHandler.GetMyHousehold)
+			householdGroup.GET("/me/bookings", householdHandler.GetHouseholdBookings)
+			householdGroup.POST("/me/members", householdHandler.AddHouseholdMember)
+			householdGroup.POST("/me/accept", householdHandler.AcceptHouseholdInvitation)
+			householdGroup.PUT("/me/members/:userId", householdHandler.UpdateHouseholdMember)
+			householdGroup.DELETE("/me/members/:userId", householdHandler.RemoveHouseholdMember)
+		}
+
+		// Payments ledger, students can only view their own enrollment's payments
+		paymentGroup := authorized.Group("/enrollments/:id/payments")
+		paymentGroup.Use(middleware.AuthorizeRole(models.Student, models.Staff, models.Admin))
+		{
+			paymentGroup.GET("", paymentHandler.GetPayments)
+			paymentGroup.GET("/:paymentID", paymentHandler.GetPaymentByID)
+			paymentGroup.GET("/:paymentID/receipt.pdf", paymentHandler.GetReceiptPDF)
+			paymentGroup.POST("/checkout", paymentHandler.Checkout)
+			paymentGroup.POST("/credit", paymentHandler.PayWithCredit)
+
+			staffAdmin := middleware.AuthorizeRole(models.Staff, models.Admin)
+			paymentGroup.POST("", staffAdmin, paymentHandler.RecordPayment)
+			paymentGroup.POST("/:paymentID/refund", staffAdmin, paymentHandler.RefundPayment)
+			paymentGroup.DELETE("/:paymentID", middleware.AuthorizeRole(models.Admin), paymentHandler.DeletePayment)
+		}
+
+		// Invoices for the accountant, students can only download their own
+		authorized.GET("/enrollments/:id/invoice.pdf", middleware.AuthorizeRole(models.Student, models.Staff, models.Admin), paymentHandler.GetInvoicePDF)
+
+		// Student and Admin routes for session bookings
+		bookingGroup := authorized.Group("/sessions")
+		bookingGroup.Use(middleware.AuthorizeRole(models.Student, models.Admin))
+		{
+			bookingGroup.POST("/:id/bookings", sessionHandler.BookSession)
+			bookingGroup.POST("/:id/waitlist", sessionHandler.JoinWaitlist)
+			bookingGroup.DELETE("/:id/bookings/:bookingID", sessionHandler.CancelBooking)
 		}
+
+		// Instructor and Admin routes for session attendance
+		attendanceGroup := authorized.Group("/sessions")
+		attendanceGroup.Use(middleware.AuthorizeRole(models.Instructor, models.Admin))
+		{
+			attendanceGroup.GET("/:id/roster", sessionHandler.GetRoster)
+			attendanceGroup.POST("/:id/attendance", sessionHandler.RecordAttendance)
+			attendanceGroup.GET("/:id/checkin.png", sessionHandler.GetCheckInQRCode)
+		}
+
+		// Students check themselves in with the QR code shown at the front desk
+		authorized.POST("/checkin", middleware.AuthorizeRole(models.Student), sessionHandler.CheckIn)
 	}
 
 	// Swagger documentation route
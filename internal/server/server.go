@@ -1,12 +1,17 @@
 package server
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"net/http"
 	"time"
 	"yoga-guru/docs"
 	"yoga-guru/internal/config"
 	"yoga-guru/internal/database"
+	"yoga-guru/internal/notify"
+	"yoga-guru/internal/payment"
+	"yoga-guru/internal/scheduler"
 
 	_ "github.com/joho/godotenv/autoload"
 )
@@ -14,19 +19,54 @@ import (
 type Server struct {
 	cfg *config.Config
 
-	db database.Service
+	db       database.Service
+	notifier notify.Notifier
+	gateway  payment.Gateway
+	sms      notify.SMSSender
 }
 
 func NewServer() *http.Server {
+	cfg := config.LoadConfig()
 	NewServer := &Server{
-		cfg: config.LoadConfig(),
-		db:  database.New(),
+		cfg:      cfg,
+		db:       database.New(cfg.Currency),
+		notifier: notify.LogNotifier{},
+	}
+
+	switch NewServer.cfg.PaymentGateway {
+	case "fake":
+		log.Println("Using the fake payment gateway: online payments are approved without charging anyone")
+		NewServer.gateway = payment.NewFakeGateway()
+	default:
+		log.Fatalf("unknown payment gateway %q", NewServer.cfg.PaymentGateway)
+	}
+
+	switch NewServer.cfg.SMSSender {
+	case "console":
+		NewServer.sms = notify.ConsoleSMSSender{}
+	case "file":
+		NewServer.sms = &notify.FileSMSSender{Path: NewServer.cfg.SMSFile}
+	default:
+		log.Fatalf("unknown SMS sender %q", NewServer.cfg.SMSSender)
 	}
 
 	// Set up Swagger UI programmatically if not generated
 	docs.SwaggerInfo.BasePath = "/"
 	docs.SwaggerInfo.Host = "localhost:" + NewServer.cfg.Port
 
+	// Keep upcoming course sessions materialized in the background
+	go scheduler.Run(context.Background(), NewServer.db.Getgorm(), NewServer.notifier, time.Hour, NewServer.cfg.SessionHorizon)
+	renewals := &scheduler.Renewals{
+		DB:           NewServer.db.Getgorm(),
+		Notifier:     NewServer.notifier,
+		Gateway:      NewServer.gateway,
+		CallbackURL:  NewServer.cfg.PaymentCallbackURL(),
+		ReminderDays: NewServer.cfg.RenewalReminderDays,
+		Tax:          NewServer.cfg.Tax,
+		Currency:     NewServer.cfg.Currency,
+	}
+	go renewals.Run(context.Background(), time.Hour)
+
 	// Declare Server config
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%s", NewServer.cfg.Port),